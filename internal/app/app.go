@@ -1,14 +1,18 @@
 package app
 
 import (
+	"context"
+	"fmt"
 	"github.com/joho/godotenv"
 	"github.com/rs/zerolog"
 	"os"
 	"time"
 	"ui-platform-backend-service/internal/config"
 	"ui-platform-backend-service/internal/handlers"
+	"ui-platform-backend-service/internal/jobs"
 	"ui-platform-backend-service/internal/services"
 	"ui-platform-backend-service/internal/storages"
+	"ui-platform-backend-service/pkg/blobstore"
 	"ui-platform-backend-service/pkg/database"
 	"ui-platform-backend-service/pkg/jwt"
 	"ui-platform-backend-service/pkg/rabbit_mq"
@@ -49,18 +53,31 @@ func Run() {
 		logger.Error().Msgf("Error connecting to Redis: %v", err)
 	}
 	logger.Info().Msg("Redis: OK")
+	// assets
+	blobs, err := newBlobStore(cfg.Assets)
+	if err != nil {
+		logger.Fatal().Msgf("Error initializing asset storage: %v", err)
+	}
+	logger.Info().Msg("Assets: OK")
 	// storage
 	storage := storages.NewStorage(storages.StorageDeps{
 		PostgresDB: pg,
 		Redis:      redis,
+		Blobs:      blobs,
 		Log:        logger,
 	})
 	// services
 	service := services.NewService(services.ServiceDeps{
-		Log:      logger,
-		Producer: producer,
-		Storage:  storage,
+		Log:                    logger,
+		Producer:               producer,
+		Storage:                storage,
+		TrashRetention:         cfg.Projects.TrashRetention,
+		RuntimeCacheTTL:        cfg.Runtime.CacheTTL,
+		CollabSnapshotInterval: cfg.Collab.SnapshotInterval,
 	})
+	// background jobs
+	go jobs.NewTrashCleanup(logger, service.Project, time.Hour).Run(context.Background())
+	go jobs.NewAssetCleanup(logger, service.Asset, time.Hour).Run(context.Background())
 	// jwt service
 	jwtService := jwt.New(jwt.Config{
 		SecretKey:       cfg.AppSecretKey,
@@ -72,3 +89,20 @@ func Run() {
 	// run
 	handler.InitRoutes(cfg.AppPort)
 }
+
+func newBlobStore(cfg config.Assets) (blobstore.Store, error) {
+	switch cfg.Driver {
+	case "fs":
+		return blobstore.NewFS(cfg.Dir)
+	case "s3":
+		return blobstore.NewS3(blobstore.S3Config{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			Bucket:    cfg.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			PathStyle: cfg.S3.PathStyle,
+		})
+	}
+	return nil, fmt.Errorf("unknown asset storage driver %q", cfg.Driver)
+}
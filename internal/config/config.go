@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 )
 
 type Config struct {
@@ -12,6 +13,10 @@ type Config struct {
 	RabbitMQ     RabbitMQ
 	Postgres     Postgres
 	Redis        Redis
+	Projects     Projects
+	Runtime      Runtime
+	Collab       Collab
+	Assets       Assets
 }
 
 type RabbitMQ struct {
@@ -37,6 +42,34 @@ type Redis struct {
 	DB       int
 }
 
+type Projects struct {
+	TrashRetention time.Duration
+}
+
+type Runtime struct {
+	CacheTTL time.Duration
+}
+
+type Collab struct {
+	SnapshotInterval time.Duration
+}
+
+type Assets struct {
+	// Driver - хранилище файлов ассетов: fs или s3
+	Driver string
+	Dir    string
+	S3     S3
+}
+
+type S3 struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool
+}
+
 func GetConfig() Config {
 	// APP
 	appPort := os.Getenv("APP_PORT")
@@ -144,6 +177,70 @@ func GetConfig() Config {
 		redisDBInt = 0
 	}
 
+	// projects
+	trashRetentionDays := os.Getenv("PROJECT_TRASH_RETENTION_DAYS")
+	if trashRetentionDays == "" {
+		trashRetentionDays = "30"
+		fmt.Println("PROJECT_TRASH_RETENTION_DAYS environment variable is not set. Using default value: 30")
+	}
+
+	trashRetentionDaysInt, err := strconv.Atoi(trashRetentionDays)
+	if err != nil || trashRetentionDaysInt <= 0 {
+		fmt.Println("PROJECT_TRASH_RETENTION_DAYS environment variable is not a positive number. Using default value: 30")
+		trashRetentionDaysInt = 30
+	}
+
+	// runtime
+	runtimeCacheTTL := os.Getenv("RUNTIME_CACHE_TTL_SECONDS")
+	if runtimeCacheTTL == "" {
+		runtimeCacheTTL = "300"
+		fmt.Println("RUNTIME_CACHE_TTL_SECONDS environment variable is not set. Using default value: 300")
+	}
+
+	runtimeCacheTTLInt, err := strconv.Atoi(runtimeCacheTTL)
+	if err != nil || runtimeCacheTTLInt <= 0 {
+		fmt.Println("RUNTIME_CACHE_TTL_SECONDS environment variable is not a positive number. Using default value: 300")
+		runtimeCacheTTLInt = 300
+	}
+
+	// collab
+	collabSnapshotInterval := os.Getenv("COLLAB_SNAPSHOT_INTERVAL_SECONDS")
+	if collabSnapshotInterval == "" {
+		collabSnapshotInterval = "10"
+		fmt.Println("COLLAB_SNAPSHOT_INTERVAL_SECONDS environment variable is not set. Using default value: 10")
+	}
+
+	collabSnapshotIntervalInt, err := strconv.Atoi(collabSnapshotInterval)
+	if err != nil || collabSnapshotIntervalInt <= 0 {
+		fmt.Println("COLLAB_SNAPSHOT_INTERVAL_SECONDS environment variable is not a positive number. Using default value: 10")
+		collabSnapshotIntervalInt = 10
+	}
+
+	// assets
+	assetDriver := os.Getenv("ASSET_STORAGE")
+	if assetDriver == "" {
+		assetDriver = "fs"
+		fmt.Println("ASSET_STORAGE environment variable is not set. Using default value: fs")
+	}
+
+	assetDir := os.Getenv("ASSET_DIR")
+	if assetDir == "" {
+		assetDir = "./data/assets"
+		fmt.Println("ASSET_DIR environment variable is not set. Using default value: ./data/assets")
+	}
+
+	s3Region := os.Getenv("ASSET_S3_REGION")
+	if s3Region == "" {
+		s3Region = "us-east-1"
+		fmt.Println("ASSET_S3_REGION environment variable is not set. Using default value: us-east-1")
+	}
+
+	// Локальные S3-совместимые серверы (MinIO) адресуют бакет путем
+	s3PathStyle, err := strconv.ParseBool(os.Getenv("ASSET_S3_PATH_STYLE"))
+	if err != nil {
+		s3PathStyle = true
+	}
+
 	return Config{
 		AppPort:      appPort,
 		AppSecretKey: appSecretKey,
@@ -167,5 +264,26 @@ func GetConfig() Config {
 			Password: redisPassword,
 			DB:       redisDBInt,
 		},
+		Projects: Projects{
+			TrashRetention: time.Duration(trashRetentionDaysInt) * 24 * time.Hour,
+		},
+		Runtime: Runtime{
+			CacheTTL: time.Duration(runtimeCacheTTLInt) * time.Second,
+		},
+		Collab: Collab{
+			SnapshotInterval: time.Duration(collabSnapshotIntervalInt) * time.Second,
+		},
+		Assets: Assets{
+			Driver: assetDriver,
+			Dir:    assetDir,
+			S3: S3{
+				Endpoint:  os.Getenv("ASSET_S3_ENDPOINT"),
+				Region:    s3Region,
+				Bucket:    os.Getenv("ASSET_S3_BUCKET"),
+				AccessKey: os.Getenv("ASSET_S3_ACCESS_KEY"),
+				SecretKey: os.Getenv("ASSET_S3_SECRET_KEY"),
+				PathStyle: s3PathStyle,
+			},
+		},
 	}
 }
@@ -0,0 +1,127 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Виды ассетов проекта.
+const (
+	AssetKindImage = "image"
+	AssetKindSVG   = "svg"
+	AssetKindFont  = "font"
+)
+
+// AssetMimeTypes - поддерживаемые типы файлов и их вид. Тип определяется по содержимому файла.
+var AssetMimeTypes = map[string]string{
+	"image/png":     AssetKindImage,
+	"image/jpeg":    AssetKindImage,
+	"image/gif":     AssetKindImage,
+	"image/webp":    AssetKindImage,
+	"image/svg+xml": AssetKindSVG,
+	"font/ttf":      AssetKindFont,
+	"font/otf":      AssetKindFont,
+	"font/woff":     AssetKindFont,
+	"font/woff2":    AssetKindFont,
+}
+
+// AssetMaxSizes - ограничения размера файла по виду ассета.
+var AssetMaxSizes = map[string]int64{
+	AssetKindImage: 10 << 20,
+	AssetKindSVG:   1 << 20,
+	AssetKindFont:  5 << 20,
+}
+
+// AssetMaxSize - максимальный размер загружаемого файла любого вида.
+const AssetMaxSize = 10 << 20
+
+// AssetMaxPixels - ограничение площади растрового изображения, защищает от "бомб" при декодировании.
+const AssetMaxPixels = 40_000_000
+
+// AssetThumbnailSize - сторона квадрата, в который вписывается превью.
+const AssetThumbnailSize = 256
+
+// AssetPublicPath - путь публичной выдачи ассетов. Виджеты ссылаются на ассет
+// его URL, поэтому использование ассета ищется по хешу в документах экранов.
+const AssetPublicPath = "/api/v1/public/assets/"
+
+// Asset - файл проекта. Содержимое адресуется SHA-256 хешем и хранится один раз
+// для всех проектов (AssetBlob); повторная загрузка того же файла в проект возвращает
+// существующий ассет.
+type Asset struct {
+	Id           string    `json:"id" db:"id"`
+	ProjectId    string    `json:"project_id" db:"project_id"`
+	Name         string    `json:"name" db:"name"`
+	Hash         string    `json:"hash" db:"hash"`
+	Kind         string    `json:"kind" db:"kind"`
+	MimeType     string    `json:"mime_type" db:"mime_type"`
+	Size         int64     `json:"size" db:"size"`
+	Width        int       `json:"width,omitempty" db:"width"`
+	Height       int       `json:"height,omitempty" db:"height"`
+	HasThumbnail bool      `json:"-" db:"has_thumbnail"`
+	URL          string    `json:"url" db:"-"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty" db:"-"`
+	Usages       int       `json:"usages" db:"usages"`
+	CreatedBy    string    `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+func (a *Asset) EntityName() string {
+	return "projects_assets"
+}
+
+// SetURLs заполняет публичные адреса ассета и превью.
+func (a *Asset) SetURLs() {
+	a.URL = AssetPublicPath + a.Hash
+	a.ThumbnailURL = ""
+	if a.HasThumbnail {
+		a.ThumbnailURL = a.URL + "/thumbnail"
+	}
+}
+
+// AssetBlob - содержимое ассета, общее для всех проектов с таким же файлом.
+type AssetBlob struct {
+	Hash         string `db:"hash"`
+	Kind         string `db:"kind"`
+	MimeType     string `db:"mime_type"`
+	Size         int64  `db:"size"`
+	Width        int    `db:"width"`
+	Height       int    `db:"height"`
+	HasThumbnail bool   `db:"has_thumbnail"`
+}
+
+// AssetUpload - загружаемый файл. Name по умолчанию - имя файла.
+type AssetUpload struct {
+	Name string
+	Data []byte
+}
+
+func (u *AssetUpload) Validate() error {
+	u.Name = strings.TrimSpace(u.Name)
+	if u.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(u.Name) > 255 {
+		return errors.New("name must be less than 255 characters")
+	}
+	if len(u.Data) == 0 {
+		return errors.New("file is empty")
+	}
+	if len(u.Data) > AssetMaxSize {
+		return errors.New("file is too large")
+	}
+	return nil
+}
+
+// AssetUsage - место, где документ ссылается на ассет: виджет или настройки (пустой WidgetId)
+// головной версии ветки экрана, живого релиза или последней версии компонента проекта.
+type AssetUsage struct {
+	ScreenId      string `json:"screen_id,omitempty" db:"screen_id"`
+	ScreenName    string `json:"screen_name,omitempty" db:"screen_name"`
+	BranchName    string `json:"branch_name,omitempty" db:"branch_name"`
+	ReleaseNumber int    `json:"release_number,omitempty" db:"release_number"`
+	ComponentId   string `json:"component_id,omitempty" db:"component_id"`
+	ComponentName string `json:"component_name,omitempty" db:"component_name"`
+	WidgetId      string `json:"widget_id,omitempty" db:"widget_id"`
+}
@@ -0,0 +1,226 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Привязки данных. Строка в свойствах виджета или настройках экрана может содержать
+// выражения "{{orders.items[0].title}}": первый сегмент - имя источника данных проекта,
+// дальше путь в его ответе (поля через точку, индексы массивов, ключи ["x-total"]).
+// Строка, состоящая из одного выражения, заменяется значением как есть (число, массив, объект),
+// выражения внутри текста ("Всего: {{orders.total}}") подставляются строкой.
+// Выражения не вычисляются как код: это только пути в данных.
+
+// BindingMaxLength - максимальная длина одного выражения.
+const BindingMaxLength = 300
+
+// BindingMaxDepth - максимальное число сегментов пути после имени источника.
+const BindingMaxDepth = 32
+
+// Binding - разобранное выражение привязки. Path содержит ключи (string) и индексы (int).
+type Binding struct {
+	Expression string        `json:"expression"`
+	Source     string        `json:"source"`
+	Path       []interface{} `json:"path"`
+}
+
+// ParseBinding разбирает выражение без фигурных скобок: orders.items[0]["x-id"].
+func ParseBinding(expression string) (Binding, error) {
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return Binding{}, errors.New("binding expression is empty")
+	}
+	if len(expression) > BindingMaxLength {
+		return Binding{}, fmt.Errorf("binding expression must be less than %d characters", BindingMaxLength)
+	}
+	binding := Binding{Expression: expression}
+	name, rest := scanIdentifier(expression)
+	if name == "" {
+		return Binding{}, fmt.Errorf("binding %q must start with a data source name", expression)
+	}
+	binding.Source = name
+
+	for rest != "" {
+		if len(binding.Path) == BindingMaxDepth {
+			return Binding{}, fmt.Errorf("binding %q is deeper than %d segments", expression, BindingMaxDepth)
+		}
+		switch {
+		case rest[0] == '.':
+			field, tail := scanIdentifier(rest[1:])
+			if field == "" {
+				return Binding{}, fmt.Errorf("binding %q: expected field name after '.'", expression)
+			}
+			binding.Path, rest = append(binding.Path, field), tail
+		case strings.HasPrefix(rest, `["`):
+			end := strings.Index(rest[2:], `"]`)
+			if end < 0 {
+				return Binding{}, fmt.Errorf(`binding %q: unclosed ["`, expression)
+			}
+			binding.Path, rest = append(binding.Path, rest[2:2+end]), rest[2+end+2:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return Binding{}, fmt.Errorf("binding %q: unclosed [", expression)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return Binding{}, fmt.Errorf("binding %q: array index must be a non-negative integer", expression)
+			}
+			binding.Path, rest = append(binding.Path, index), rest[end+1:]
+		default:
+			return Binding{}, fmt.Errorf("binding %q: unexpected %q", expression, rest[:1])
+		}
+	}
+	return binding, nil
+}
+
+func scanIdentifier(s string) (identifier, rest string) {
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		letter := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		if !letter && (i == 0 || c < '0' || c > '9') {
+			break
+		}
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// ParseBindingTemplate находит выражения в строке. whole - строка целиком состоит
+// из одного выражения. Строка без "{{" возвращает пустой список.
+func ParseBindingTemplate(s string) (bindings []Binding, whole bool, err error) {
+	rest := s
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			return nil, false, errors.New("unclosed {{ in binding")
+		}
+		binding, err := ParseBinding(rest[start+2 : start+end])
+		if err != nil {
+			return nil, false, err
+		}
+		bindings = append(bindings, binding)
+		rest = rest[start+end+2:]
+	}
+	trimmed := strings.TrimSpace(s)
+	whole = len(bindings) == 1 && strings.HasPrefix(trimmed, "{{") && strings.Index(trimmed, "}}") == len(trimmed)-2
+	return bindings, whole, nil
+}
+
+// Lookup находит значение выражения в данных источников: имя источника -> ответ.
+func (b Binding) Lookup(data map[string]interface{}) (value interface{}, ok bool) {
+	value, ok = data[b.Source]
+	for _, segment := range b.Path {
+		if !ok {
+			return nil, false
+		}
+		switch key := segment.(type) {
+		case string:
+			var object map[string]interface{}
+			if object, ok = value.(map[string]interface{}); ok {
+				value, ok = object[key]
+			}
+		case int:
+			var array []interface{}
+			if array, ok = value.([]interface{}); ok && key < len(array) {
+				value = array[key]
+			} else {
+				ok = false
+			}
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+	return value, true
+}
+
+// RenderBindingTemplate подставляет в строку значения выражений. Выражения, которых нет
+// в данных, возвращаются в missing и заменяются null (целая строка) или пустой строкой.
+func RenderBindingTemplate(s string, data map[string]interface{}) (value interface{}, missing []Binding, err error) {
+	bindings, whole, err := ParseBindingTemplate(s)
+	if err != nil || len(bindings) == 0 {
+		return s, nil, err
+	}
+	if whole {
+		value, ok := bindings[0].Lookup(data)
+		if !ok {
+			return nil, bindings, nil
+		}
+		return value, nil, nil
+	}
+
+	var out strings.Builder
+	rest := s
+	for _, binding := range bindings {
+		start := strings.Index(rest, "{{")
+		end := start + strings.Index(rest[start:], "}}")
+		out.WriteString(rest[:start])
+		value, ok := binding.Lookup(data)
+		if !ok {
+			missing = append(missing, binding)
+		}
+		out.WriteString(bindingText(value))
+		rest = rest[end+2:]
+	}
+	out.WriteString(rest)
+	return out.String(), missing, nil
+}
+
+// bindingText - значение выражения внутри текста: строки как есть, остальное в JSON.
+func bindingText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	text, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(text)
+}
+
+// Откуда предпросмотр взял данные источника.
+const (
+	BindingDataMock     = "mock"
+	BindingDataStatic   = "static"
+	BindingDataRecorded = "recorded"
+)
+
+// BindingPreviewRequest - запрос предпросмотра привязок. Без Widgets берется версия ветки
+// (по умолчанию головная версия Main), иначе - переданный несохраненный документ.
+// Mocks задает ответы источников и имеет приоритет над статическими данными и записанными ответами.
+type BindingPreviewRequest struct {
+	BranchId string                 `json:"branch_id"`
+	Version  int                    `json:"version"`
+	Widgets  map[string]interface{} `json:"widgets"`
+	Settings map[string]interface{} `json:"settings"`
+	Mocks    map[string]interface{} `json:"mocks"`
+}
+
+// BindingIssue - выражение, которое не удалось разрешить при предпросмотре.
+type BindingIssue struct {
+	Path       string `json:"path"`
+	Expression string `json:"expression"`
+	Message    string `json:"message"`
+}
+
+// BindingPreview - документ экрана с подставленными данными. Sources - для каждого
+// использованного источника, откуда взяты данные (mock, static, recorded).
+type BindingPreview struct {
+	Widgets  map[string]interface{} `json:"widgets"`
+	Settings map[string]interface{} `json:"settings"`
+	Sources  map[string]string      `json:"sources"`
+	Issues   []BindingIssue         `json:"issues"`
+}
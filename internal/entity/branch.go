@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// ScreenBranch - ветка экрана. Ветка, созданная из другой ветки,
+// хранит родителя и версию, от которой она ответвилась.
+type ScreenBranch struct {
+	Id             string    `json:"id" db:"id"`
+	ScreenId       string    `json:"screen_id" db:"screen_id"`
+	Name           string    `json:"name" db:"name"`
+	ParentBranchId string    `json:"parent_branch_id,omitempty" db:"parent_branch_id"`
+	ParentVersion  int       `json:"parent_version,omitempty" db:"parent_version"`
+	HeadVersion    int       `json:"head_version" db:"head_version"`
+	CreatedBy      string    `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt      time.Time `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+func (b *ScreenBranch) EntityName() string {
+	return "screens_branches"
+}
+
+func (b *ScreenBranch) ValidateName() error {
+	if b.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(b.Name) > 100 {
+		return errors.New("name must be less than 100 characters")
+	}
+	return nil
+}
+
+// ScreenBranchCreate - параметры создания ветки из версии другой ветки.
+// Нулевая версия означает головную версию исходной ветки.
+type ScreenBranchCreate struct {
+	Name           string `json:"name"`
+	SourceBranchId string `json:"source_branch_id"`
+	SourceVersion  int    `json:"source_version,omitempty"`
+}
+
+func (b *ScreenBranchCreate) Validate() error {
+	branch := ScreenBranch{Name: b.Name}
+	if err := branch.ValidateName(); err != nil {
+		return err
+	}
+	if b.SourceBranchId == "" {
+		return errors.New("source_branch_id is required")
+	}
+	if b.SourceVersion < 0 {
+		return errors.New("source_version must be positive")
+	}
+	return nil
+}
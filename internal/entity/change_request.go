@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"ui-platform-backend-service/pkg/jsonschema"
+)
+
+// Статусы запроса на изменение.
+const (
+	ChangeRequestOpen   = "open"
+	ChangeRequestMerged = "merged"
+	ChangeRequestClosed = "closed"
+)
+
+// ChangeRequestRequiredApprovals - сколько одобрений администраторов нужно для слияния.
+const ChangeRequestRequiredApprovals = 1
+
+// Проверки запроса на изменение.
+const (
+	ChangeRequestCheckApprovals  = "approvals"
+	ChangeRequestCheckConflicts  = "conflicts"
+	ChangeRequestCheckValidation = "validation"
+)
+
+// ChangeRequest - запрос на слияние ветки экрана в Main. Сливается автоматически,
+// когда проходят все проверки: одобрения администраторов, отсутствие конфликтов
+// и корректность результата слияния.
+type ChangeRequest struct {
+	Id             string     `json:"id" db:"id"`
+	ScreenId       string     `json:"screen_id" db:"screen_id"`
+	SourceBranchId string     `json:"source_branch_id" db:"source_branch_id"`
+	TargetBranchId string     `json:"target_branch_id" db:"target_branch_id"`
+	Title          string     `json:"title" db:"title"`
+	Description    string     `json:"description,omitempty" db:"description"`
+	Status         string     `json:"status" db:"status"`
+	CreatedBy      string     `json:"created_by" db:"created_by"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	MergedBy       string     `json:"merged_by,omitempty" db:"merged_by"`
+	MergedAt       *time.Time `json:"merged_at,omitempty" db:"merged_at"`
+	MergedVersion  int        `json:"merged_version,omitempty" db:"merged_version"`
+	// Заполняются при просмотре запроса
+	Approvals []ChangeRequestApproval `json:"approvals,omitempty" db:"-"`
+	Checks    []ChangeRequestCheck    `json:"checks,omitempty" db:"-"`
+	Diff      *ScreenDiff             `json:"diff,omitempty" db:"-"`
+}
+
+func (r *ChangeRequest) EntityName() string {
+	return "screens_change_requests"
+}
+
+// ChangeRequestApproval - одобрение конкретной версии ветки-источника. После новых
+// сохранений в источник одобрение устаревает (Stale) и не учитывается.
+type ChangeRequestApproval struct {
+	UserId        string    `json:"user_id" db:"user_id"`
+	SourceVersion int       `json:"source_version" db:"source_version"`
+	Stale         bool      `json:"stale" db:"-"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// ChangeRequestCheck - результат проверки запроса. Errors - нарушения схем виджетов
+// в результате слияния.
+type ChangeRequestCheck struct {
+	Name    string             `json:"name"`
+	Passed  bool               `json:"passed"`
+	Message string             `json:"message,omitempty"`
+	Errors  []jsonschema.Error `json:"errors,omitempty"`
+}
+
+// ChangeRequestCreate - открытие запроса на слияние ветки в Main.
+type ChangeRequestCreate struct {
+	SourceBranchId string `json:"source_branch_id"`
+	Title          string `json:"title"`
+	Description    string `json:"description,omitempty"`
+}
+
+func (r *ChangeRequestCreate) Validate() error {
+	if r.SourceBranchId == "" {
+		return errors.New("source_branch_id is required")
+	}
+	if strings.TrimSpace(r.Title) == "" {
+		return errors.New("title is required")
+	}
+	if len(r.Title) > 200 {
+		return errors.New("title must be less than 200 characters")
+	}
+	if len(r.Description) > 4000 {
+		return errors.New("description must be less than 4000 characters")
+	}
+	return nil
+}
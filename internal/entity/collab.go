@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"time"
+
+	"ui-platform-backend-service/pkg/crdt"
+	"ui-platform-backend-service/pkg/jsonschema"
+)
+
+// Типы сообщений WebSocket совместного редактирования.
+const (
+	CollabMessageWelcome    = "welcome"
+	CollabMessageOps        = "ops"
+	CollabMessagePresence   = "presence"
+	CollabMessageLeave      = "leave"
+	CollabMessageLock       = "lock"
+	CollabMessageUnlock     = "unlock"
+	CollabMessageLockDenied = "lock_denied"
+	CollabMessageSnapshot   = "snapshot"
+	CollabMessageReset      = "reset"
+	CollabMessageError      = "error"
+)
+
+// CollabMaxOps - максимальное число операций в одном сообщении.
+const CollabMaxOps = 100
+
+// CollabCursor - положение курсора участника на холсте.
+type CollabCursor struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// CollabPresence - участник сессии: кто смотрит ветку, какой виджет выбран и где курсор.
+type CollabPresence struct {
+	ClientId       string        `json:"client_id"`
+	UserId         string        `json:"user_id"`
+	SelectedWidget string        `json:"selected_widget,omitempty"`
+	Cursor         *CollabCursor `json:"cursor,omitempty"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+// CollabLock - подсказка о блокировке виджета участником. Блокировка не мешает
+// применять операции, а лишь предупреждает остальных, и истекает без продления.
+type CollabLock struct {
+	WidgetId  string    `json:"widget_id"`
+	ClientId  string    `json:"client_id"`
+	UserId    string    `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CollabMessage - сообщение WebSocket в обе стороны. Набор заполненных полей зависит от Type:
+// ops - Ops (от сервера с Lamport-метками); presence - Presence; lock/unlock - WidgetId;
+// welcome и reset - Document и Version; snapshot - Version; error - Message и Errors,
+// а при отзыве права редактирования еще и ReadOnly.
+type CollabMessage struct {
+	Type     string             `json:"type"`
+	ClientId string             `json:"client_id,omitempty"`
+	UserId   string             `json:"user_id,omitempty"`
+	Ops      []crdt.Op          `json:"ops,omitempty"`
+	Document interface{}        `json:"document,omitempty"`
+	Version  int                `json:"version,omitempty"`
+	ReadOnly bool               `json:"read_only,omitempty"`
+	Presence *CollabPresence    `json:"presence,omitempty"`
+	Peers    []CollabPresence   `json:"peers,omitempty"`
+	Locks    []CollabLock       `json:"locks,omitempty"`
+	WidgetId string             `json:"widget_id,omitempty"`
+	Lock     *CollabLock        `json:"lock,omitempty"`
+	Message  string             `json:"message,omitempty"`
+	Errors   []jsonschema.Error `json:"errors,omitempty"`
+}
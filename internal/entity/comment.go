@@ -0,0 +1,147 @@
+package entity
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Статусы ветки обсуждения.
+const (
+	CommentThreadOpen     = "open"
+	CommentThreadResolved = "resolved"
+)
+
+// Как обсуждение привязано к виджету в просматриваемой версии.
+const (
+	// CommentAnchorExact - виджет на прежнем месте
+	CommentAnchorExact = "exact"
+	// CommentAnchorMoved - виджет тот же, но перемещен в дереве
+	CommentAnchorMoved = "moved"
+	// CommentAnchorReanchored - виджета нет, обсуждение перенесено на похожий виджет
+	CommentAnchorReanchored = "reanchored"
+	// CommentAnchorOrphaned - виджет удален и замены не нашлось
+	CommentAnchorOrphaned = "orphaned"
+)
+
+const CommentMaxLength = 4000
+
+// mentionRegexp находит упоминания вида @user@example.com: пользователи различаются по email.
+var mentionRegexp = regexp.MustCompile(`(?:^|[^\w.@])@([\w.%+-]+@[\w-]+(?:\.[\w-]+)*\.[A-Za-z]{2,})`)
+
+// CommentThread - обсуждение виджета в версии ветки экрана. Вместе с id виджета
+// запоминаются его тип, свойства и положение, чтобы найти виджет в следующих версиях.
+type CommentThread struct {
+	Id          string                 `json:"id" db:"id"`
+	ScreenId    string                 `json:"screen_id" db:"screen_id"`
+	BranchId    string                 `json:"branch_id" db:"branch_id"`
+	Version     int                    `json:"version" db:"version"`
+	WidgetId    string                 `json:"widget_id" db:"widget_id"`
+	WidgetType  string                 `json:"widget_type" db:"widget_type"`
+	WidgetProps map[string]interface{} `json:"-" db:"widget_props"`
+	Position    WidgetPosition         `json:"position" db:"-"`
+	Status      string                 `json:"status" db:"status"`
+	CreatedBy   string                 `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time              `json:"created_at" db:"created_at"`
+	ResolvedBy  string                 `json:"resolved_by,omitempty" db:"resolved_by"`
+	ResolvedAt  *time.Time             `json:"resolved_at,omitempty" db:"resolved_at"`
+	Comments    []Comment              `json:"comments" db:"-"`
+	// Anchor - привязка к запрошенной версии ветки
+	Anchor *CommentAnchor `json:"anchor,omitempty" db:"-"`
+}
+
+func (t *CommentThread) EntityName() string {
+	return "screens_comment_threads"
+}
+
+// Comment - сообщение в обсуждении. Mentions - id упомянутых пользователей.
+type Comment struct {
+	Id        string    `json:"id" db:"id"`
+	ThreadId  string    `json:"thread_id" db:"thread_id"`
+	AuthorId  string    `json:"author_id" db:"author_id"`
+	Body      string    `json:"body" db:"body"`
+	Mentions  []string  `json:"mentions" db:"mentions"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+func (c *Comment) EntityName() string {
+	return "screens_comments"
+}
+
+// CommentAnchor - виджет, к которому относится обсуждение в версии Version.
+type CommentAnchor struct {
+	Version  int            `json:"version"`
+	WidgetId string         `json:"widget_id,omitempty"`
+	Position WidgetPosition `json:"position"`
+	Status   string         `json:"status"`
+}
+
+// CommentThreadCreate - новое обсуждение с первым сообщением. Пустая ветка означает Main,
+// нулевая версия - головную версию ветки.
+type CommentThreadCreate struct {
+	BranchId string `json:"branch_id,omitempty"`
+	Version  int    `json:"version,omitempty"`
+	WidgetId string `json:"widget_id"`
+	Body     string `json:"body"`
+}
+
+func (t *CommentThreadCreate) Validate() error {
+	if t.WidgetId == "" {
+		return errors.New("widget_id is required")
+	}
+	if t.Version < 0 {
+		return errors.New("version must be positive")
+	}
+	return validateCommentBody(t.Body)
+}
+
+// CommentCreate - ответ в обсуждении.
+type CommentCreate struct {
+	Body string `json:"body"`
+}
+
+func (c *CommentCreate) Validate() error {
+	return validateCommentBody(c.Body)
+}
+
+// CommentThreadFilter - отбор обсуждений экрана. Нулевая версия - головная версия ветки.
+type CommentThreadFilter struct {
+	BranchId string
+	Version  int
+	Status   string
+}
+
+func (f *CommentThreadFilter) Validate() error {
+	if f.Status != "" && f.Status != CommentThreadOpen && f.Status != CommentThreadResolved {
+		return errors.New("status must be open or resolved")
+	}
+	if f.Version < 0 {
+		return errors.New("version must be positive")
+	}
+	return nil
+}
+
+// ParseMentions возвращает email упомянутых в тексте пользователей без повторов.
+func ParseMentions(body string) []string {
+	var emails []string
+	seen := map[string]bool{}
+	for _, match := range mentionRegexp.FindAllStringSubmatch(body, -1) {
+		email := strings.ToLower(match[1])
+		if !seen[email] {
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+func validateCommentBody(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return errors.New("body is required")
+	}
+	if len(body) > CommentMaxLength {
+		return errors.New("body must be less than 4000 characters")
+	}
+	return nil
+}
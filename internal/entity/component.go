@@ -0,0 +1,174 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Компонент - именованное версионируемое поддерево виджетов, общее для экранов.
+// Компонент принадлежит проекту или воркспейсу; компоненты воркспейса доступны
+// всем его проектам. Экран ссылается на компонент виджетом-экземпляром:
+//
+//	{"type": "component", "props": {"component_id": "...", "version": 3}}
+//
+// Без version используется последняя версия компонента. При чтении с разворачиванием
+// и при публикации экземпляр заменяется корнем компонента, а остальные виджеты
+// компонента добавляются с id вида "<id экземпляра>.<id виджета компонента>".
+const WidgetTypeComponent = "component"
+
+// ComponentMaxDepth - максимальная вложенность компонентов друг в друга.
+const ComponentMaxDepth = 8
+
+type Component struct {
+	Id          string                 `json:"id" db:"id"`
+	ProjectId   string                 `json:"project_id,omitempty" db:"project_id"`
+	WorkspaceId string                 `json:"workspace_id,omitempty" db:"workspace_id"`
+	Name        string                 `json:"name" db:"name"`
+	Description string                 `json:"description,omitempty" db:"description"`
+	Version     int                    `json:"version" db:"version"`
+	Root        string                 `json:"root,omitempty" db:"-"`
+	Widgets     map[string]interface{} `json:"widgets,omitempty" db:"-"`
+	CreatedBy   string                 `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt   time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at" db:"updated_at"`
+}
+
+func (c *Component) EntityName() string {
+	return "projects_components"
+}
+
+// ComponentVersion - неизменяемая версия поддерева компонента. Root - id корневого виджета.
+type ComponentVersion struct {
+	ComponentId string                 `json:"component_id" db:"component_id"`
+	Version     int                    `json:"version" db:"version"`
+	Root        string                 `json:"root" db:"root"`
+	Widgets     map[string]interface{} `json:"widgets,omitempty" db:"-"`
+	Message     string                 `json:"message,omitempty" db:"message"`
+	CreatedBy   string                 `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt   time.Time              `json:"created_at" db:"created_at"`
+}
+
+// ComponentScope - владелец компонентов: проект или воркспейс.
+type ComponentScope struct {
+	ProjectId   string
+	WorkspaceId string
+}
+
+// ComponentCreate - создание компонента вместе с первой версией.
+type ComponentCreate struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Root        string                 `json:"root"`
+	Widgets     map[string]interface{} `json:"widgets"`
+	Message     string                 `json:"message,omitempty"`
+}
+
+func (c *ComponentCreate) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return errors.New("name is required")
+	}
+	if len(c.Name) > 100 {
+		return errors.New("name must be less than 100 characters")
+	}
+	if len(c.Description) > 4000 {
+		return errors.New("description must be less than 4000 characters")
+	}
+	return validateComponentTree(c.Root, c.Widgets, c.Message)
+}
+
+// ComponentVersionCreate - новая версия компонента. BaseVersion - версия, от которой
+// начиналось редактирование; если компонент успел измениться, версия отклоняется.
+type ComponentVersionCreate struct {
+	Root        string                 `json:"root"`
+	Widgets     map[string]interface{} `json:"widgets"`
+	Message     string                 `json:"message,omitempty"`
+	BaseVersion int                    `json:"base_version,omitempty"`
+}
+
+func (c *ComponentVersionCreate) Validate() error {
+	if c.BaseVersion < 0 {
+		return errors.New("base_version must be positive")
+	}
+	return validateComponentTree(c.Root, c.Widgets, c.Message)
+}
+
+// validateComponentTree проверяет форму поддерева: корень есть среди виджетов
+// и все виджеты достижимы из него. Схемы props проверяются по типам виджетов при сохранении.
+func validateComponentTree(root string, widgets map[string]interface{}, message string) error {
+	if len(message) > 500 {
+		return errors.New("message must be less than 500 characters")
+	}
+	if root == "" {
+		return errors.New("root is required")
+	}
+	if _, ok := widgets[root]; !ok {
+		return errors.New("root must be one of widgets")
+	}
+	parsed := ParseWidgets(widgets)
+	reached := map[string]bool{root: true}
+	queue := []string{root}
+	for len(queue) > 0 {
+		widget := parsed[queue[0]]
+		queue = queue[1:]
+		for _, child := range widget.Children {
+			if _, ok := parsed[child]; ok && !reached[child] {
+				reached[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+	for id := range widgets {
+		if strings.Contains(id, ".") {
+			return errors.New("widget ids must not contain '.'")
+		}
+		if !reached[id] {
+			return errors.New("widget " + id + " is not reachable from root")
+		}
+	}
+	return nil
+}
+
+// ComponentRef - ссылка экземпляра на компонент. Нулевая Version - последняя версия.
+type ComponentRef struct {
+	ComponentId string
+	Version     int
+}
+
+// ParseComponentRef разбирает виджет-экземпляр компонента.
+func ParseComponentRef(widget Widget) (ref ComponentRef, ok bool) {
+	if widget.Type != WidgetTypeComponent {
+		return ComponentRef{}, false
+	}
+	ref.ComponentId, _ = widget.Props["component_id"].(string)
+	if version, isNumber := widget.Props["version"].(float64); isNumber {
+		ref.Version = int(version)
+	}
+	return ref, ref.ComponentId != ""
+}
+
+// ComponentUsage - экземпляр компонента в головной версии ветки экрана
+// или в последней версии другого компонента.
+type ComponentUsage struct {
+	ProjectId     string `json:"project_id,omitempty" db:"project_id"`
+	ScreenId      string `json:"screen_id,omitempty" db:"screen_id"`
+	ScreenName    string `json:"screen_name,omitempty" db:"screen_name"`
+	BranchName    string `json:"branch_name,omitempty" db:"branch_name"`
+	ComponentId   string `json:"component_id,omitempty" db:"component_id"`
+	ComponentName string `json:"component_name,omitempty" db:"component_name"`
+	WidgetId      string `json:"widget_id" db:"widget_id"`
+	Version       int    `json:"version" db:"version"`
+	// Pinned - экземпляр закреплен на версии и не получит изменения автоматически
+	Pinned bool `json:"pinned" db:"-"`
+}
+
+// ComponentImpact - отчет о том, где используется компонент, до его изменения или удаления.
+type ComponentImpact struct {
+	Component  Component        `json:"component"`
+	Usages     []ComponentUsage `json:"usages"`
+	Screens    int              `json:"screens"`
+	Projects   int              `json:"projects"`
+	Components int              `json:"components"`
+	// Affected - экземпляры, которые получат следующую версию (не закрепленные)
+	Affected int `json:"affected"`
+}
@@ -0,0 +1,242 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Виды источников данных: REST-запрос и статический JSON, заданный в самом источнике.
+const (
+	DataSourceKindREST   = "rest"
+	DataSourceKindStatic = "static"
+)
+
+// Способы авторизации REST-источника.
+const (
+	DataSourceAuthNone   = "none"
+	DataSourceAuthBearer = "bearer"
+	DataSourceAuthBasic  = "basic"
+	DataSourceAuthAPIKey = "api_key"
+)
+
+// Куда передается API-ключ.
+const (
+	DataSourceKeyInHeader = "header"
+	DataSourceKeyInQuery  = "query"
+)
+
+// DataSourceSecretMask заменяет секреты авторизации в ответах API. Если при обновлении
+// источника секрет равен маске, сохраняется прежнее значение (см. KeepSecrets).
+const DataSourceSecretMask = "********"
+
+// DataSourceDataMaxSize - максимальный размер статических данных и записанного ответа в JSON.
+const DataSourceDataMaxSize = 1 << 20
+
+// DataSource - источник данных проекта. Виджеты ссылаются на него по имени в выражениях
+// привязки ("{{orders.items}}"), поэтому имя - идентификатор и не меняется.
+// Sample - ответ REST-источника, записанный для предпросмотра.
+type DataSource struct {
+	ProjectId   string          `json:"project_id" db:"project_id"`
+	Name        string          `json:"name" db:"name"`
+	Description string          `json:"description,omitempty" db:"description"`
+	Kind        string          `json:"kind" db:"kind"`
+	REST        *DataSourceREST `json:"rest,omitempty" db:"-"`
+	Data        interface{}     `json:"data,omitempty" db:"-"`
+	Sample      interface{}     `json:"sample,omitempty" db:"-"`
+	RecordedAt  *time.Time      `json:"recorded_at,omitempty" db:"recorded_at"`
+	CreatedBy   string          `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+func (d *DataSource) EntityName() string {
+	return "projects_data_sources"
+}
+
+// DataSourceREST - запрос REST-источника. Ответ должен быть JSON.
+type DataSourceREST struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Query   map[string]string `json:"query,omitempty"`
+	Body    interface{}       `json:"body,omitempty"`
+	Auth    DataSourceAuth    `json:"auth"`
+}
+
+// DataSourceAuth - авторизация REST-источника. Token, Password и Value - секреты.
+type DataSourceAuth struct {
+	Type     string `json:"type"`
+	Token    string `json:"token,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Name     string `json:"name,omitempty"`
+	In       string `json:"in,omitempty"`
+	Value    string `json:"value,omitempty"`
+}
+
+var (
+	dataSourceNamePattern   = regexp.MustCompile(`^[a-z][A-Za-z0-9_]{0,63}$`)
+	dataSourceHeaderPattern = regexp.MustCompile(`^[A-Za-z0-9-]{1,100}$`)
+)
+
+// dataSourceReservedHeaders задаются HTTP-клиентом или авторизацией источника.
+var dataSourceReservedHeaders = map[string]bool{
+	"Host": true, "Content-Length": true, "Connection": true, "Transfer-Encoding": true, "Authorization": true,
+}
+
+func (d *DataSource) Validate() error {
+	if !dataSourceNamePattern.MatchString(d.Name) {
+		return errors.New("name must start with a lowercase letter and contain only letters, digits and '_' (up to 64 characters)")
+	}
+	if len(d.Description) > 500 {
+		return errors.New("description must be less than 500 characters")
+	}
+	switch d.Kind {
+	case DataSourceKindREST:
+		if d.REST == nil {
+			return errors.New("rest is required for rest data source")
+		}
+		d.Data = nil
+		return d.REST.Validate()
+	case DataSourceKindStatic:
+		if d.Data == nil {
+			return errors.New("data is required for static data source")
+		}
+		data, err := json.Marshal(d.Data)
+		if err != nil || len(data) > DataSourceDataMaxSize {
+			return fmt.Errorf("data must be at most %d bytes of JSON", DataSourceDataMaxSize)
+		}
+		d.REST = nil
+		return nil
+	}
+	return fmt.Errorf("kind must be %s or %s", DataSourceKindREST, DataSourceKindStatic)
+}
+
+func (r *DataSourceREST) Validate() error {
+	r.Method = strings.ToUpper(r.Method)
+	if r.Method == "" {
+		r.Method = http.MethodGet
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		return errors.New("method must be GET or POST")
+	}
+	if r.Body != nil && r.Method != http.MethodPost {
+		return errors.New("body is allowed only for POST")
+	}
+	if len(r.URL) > 2000 {
+		return errors.New("url must be less than 2000 characters")
+	}
+	u, err := url.Parse(r.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	if u.User != nil {
+		return errors.New("url must not contain credentials, use auth")
+	}
+	if len(r.Headers) > 50 || len(r.Query) > 50 {
+		return errors.New("headers and query must have at most 50 entries each")
+	}
+	for name := range r.Headers {
+		if !dataSourceHeaderPattern.MatchString(name) {
+			return fmt.Errorf("header %q is invalid", name)
+		}
+		if dataSourceReservedHeaders[http.CanonicalHeaderKey(name)] {
+			return fmt.Errorf("header %s cannot be set, use auth for credentials", name)
+		}
+	}
+	return r.Auth.Validate()
+}
+
+func (a *DataSourceAuth) Validate() error {
+	switch a.Type {
+	case "", DataSourceAuthNone:
+		*a = DataSourceAuth{Type: DataSourceAuthNone}
+	case DataSourceAuthBearer:
+		if a.Token == "" {
+			return errors.New("auth token is required for bearer auth")
+		}
+		*a = DataSourceAuth{Type: a.Type, Token: a.Token}
+	case DataSourceAuthBasic:
+		if a.Username == "" || a.Password == "" {
+			return errors.New("auth username and password are required for basic auth")
+		}
+		*a = DataSourceAuth{Type: a.Type, Username: a.Username, Password: a.Password}
+	case DataSourceAuthAPIKey:
+		if a.In == "" {
+			a.In = DataSourceKeyInHeader
+		}
+		if a.In != DataSourceKeyInHeader && a.In != DataSourceKeyInQuery {
+			return errors.New("auth in must be header or query")
+		}
+		if !dataSourceHeaderPattern.MatchString(a.Name) || a.Value == "" {
+			return errors.New("auth name and value are required for api_key auth")
+		}
+		*a = DataSourceAuth{Type: a.Type, Name: a.Name, In: a.In, Value: a.Value}
+	default:
+		return errors.New("auth type must be none, bearer, basic or api_key")
+	}
+	return nil
+}
+
+// Redact заменяет секреты авторизации маской.
+func (d *DataSource) Redact() {
+	if d.REST == nil {
+		return
+	}
+	rest := *d.REST
+	for _, secret := range []*string{&rest.Auth.Token, &rest.Auth.Password, &rest.Auth.Value} {
+		if *secret != "" {
+			*secret = DataSourceSecretMask
+		}
+	}
+	d.REST = &rest
+}
+
+// KeepSecrets подставляет сохраненные секреты вместо маски, пришедшей от клиента.
+// Секреты сохраняются, только если они уходят туда же, что и раньше: схема и хост URL,
+// способ авторизации, имя и место API-ключа не изменились. Иначе маска остается
+// и секрет нужно ввести заново.
+func (d *DataSource) KeepSecrets(stored DataSource) {
+	if d.REST == nil || stored.REST == nil || !d.REST.sameDestination(*stored.REST) {
+		return
+	}
+	auth, old := &d.REST.Auth, stored.REST.Auth
+	for _, pair := range [][2]*string{{&auth.Token, &old.Token}, {&auth.Password, &old.Password}, {&auth.Value, &old.Value}} {
+		if *pair[0] == DataSourceSecretMask {
+			*pair[0] = *pair[1]
+		}
+	}
+}
+
+func (r DataSourceREST) sameDestination(other DataSourceREST) bool {
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return false
+	}
+	o, err := url.Parse(other.URL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Scheme, o.Scheme) && strings.EqualFold(u.Host, o.Host) &&
+		r.Auth.Type == other.Auth.Type && r.Auth.Name == other.Auth.Name && r.Auth.In == other.Auth.In
+}
+
+// HasSecrets проверяет, что заданы секреты, которых требует способ авторизации.
+// У копии проекта секреты источников очищены и должны быть введены заново.
+func (a DataSourceAuth) HasSecrets() bool {
+	switch a.Type {
+	case DataSourceAuthBearer:
+		return a.Token != ""
+	case DataSourceAuthBasic:
+		return a.Password != ""
+	case DataSourceAuthAPIKey:
+		return a.Value != ""
+	}
+	return true
+}
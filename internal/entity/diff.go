@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"ui-platform-backend-service/pkg/jsonpatch"
+)
+
+// WidgetRef - краткое описание виджета в сводке изменений.
+type WidgetRef struct {
+	Id   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// WidgetMove - виджет сменил родителя или позицию среди детей.
+type WidgetMove struct {
+	Id   string         `json:"id"`
+	From WidgetPosition `json:"from"`
+	To   WidgetPosition `json:"to"`
+}
+
+// PropertyChange - изменение свойства виджета. Отсутствие значения
+// на одной из сторон означает, что свойство добавлено или удалено.
+type PropertyChange struct {
+	Id       string      `json:"id"`
+	Property string      `json:"property"`
+	From     interface{} `json:"from,omitempty"`
+	To       interface{} `json:"to,omitempty"`
+}
+
+// DiffSummary - сводка изменений дерева виджетов.
+type DiffSummary struct {
+	Added           []WidgetRef      `json:"added"`
+	Removed         []WidgetRef      `json:"removed"`
+	Moved           []WidgetMove     `json:"moved"`
+	Changed         []PropertyChange `json:"changed"`
+	SettingsChanged []string         `json:"settings_changed"`
+}
+
+// ScreenDiff - разница между двумя версиями экрана: JSON Patch (RFC 6902)
+// над документом {"widgets", "settings"} и сводка по виджетам.
+type ScreenDiff struct {
+	From    VersionRef            `json:"from"`
+	To      VersionRef            `json:"to"`
+	Patch   []jsonpatch.Operation `json:"patch"`
+	Summary DiffSummary           `json:"summary"`
+}
@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Типы событий ленты изменений.
+const (
+	EventProjectUpdated   = "project.updated"
+	EventProjectDeleted   = "project.deleted"
+	EventScreenCreated    = "screen.created"
+	EventBranchMerged     = "branch.merged"
+	EventMemberAdded      = "member.added"
+	EventMemberRemoved    = "member.removed"
+	EventReleasePublished = "release.published"
+	// EventStreamReset - часть ленты после Last-Event-ID уже вытеснена: клиенту нужно перечитать данные
+	EventStreamReset = "stream.reset"
+)
+
+// Event - событие ленты изменений. Id - идентификатор в потоке Redis, по которому
+// клиент продолжает чтение через Last-Event-ID; в каждом потоке он свой.
+type Event struct {
+	Id          string      `json:"id"`
+	Type        string      `json:"type"`
+	ProjectId   string      `json:"project_id,omitempty"`
+	WorkspaceId string      `json:"workspace_id,omitempty"`
+	ActorId     string      `json:"actor_id,omitempty"`
+	Data        interface{} `json:"data,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	// Stream - поток, из которого прочитано событие
+	Stream string `json:"-"`
+}
+
+// EventProjectStream и EventUserStream - имена потоков событий проекта и пользователя.
+func EventProjectStream(projectId string) string {
+	return "events:project:" + projectId
+}
+
+func EventUserStream(userId string) string {
+	return "events:user:" + userId
+}
+
+// ParseEventId разбирает id события потока Redis ("ms-seq").
+func ParseEventId(id string) (ms, seq uint64, ok bool) {
+	parts := strings.SplitN(id, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	ms, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	seq, err = strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return ms, seq, true
+}
+
+// EventIdLess сообщает, что событие a записано в поток раньше b. Id должны быть корректными.
+func EventIdLess(a, b string) bool {
+	aMs, aSeq, _ := ParseEventId(a)
+	bMs, bSeq, _ := ParseEventId(b)
+	if aMs != bMs {
+		return aMs < bMs
+	}
+	return aSeq < bSeq
+}
@@ -0,0 +1,237 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Локализация. Текст в свойствах виджетов и настройках экрана ссылается на ключ
+// перевода строкой "{t:checkout.title}". Ссылки разрешаются в runtime для запрошенной
+// локали: точная локаль (de-AT), затем язык (de), затем локаль проекта по умолчанию;
+// если перевода нет нигде, подставляется сам ключ.
+
+// Locale - локаль проекта. Локаль по умолчанию - исходный язык текстов, она одна на проект.
+type Locale struct {
+	ProjectId string    `json:"project_id" db:"project_id"`
+	Code      string    `json:"code" db:"code"`
+	IsDefault bool      `json:"is_default" db:"is_default"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+func (l *Locale) EntityName() string {
+	return "projects_locales"
+}
+
+// localePattern - подмножество BCP 47: язык, необязательные письменность и регион (en, en-US, zh-Hant-TW).
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z][a-z]{3})?(-([A-Z]{2}|[0-9]{3}))?$`)
+
+// NormalizeLocale приводит код локали к каноническому регистру: "en-us" -> "en-US".
+func NormalizeLocale(code string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(code), "_", "-"), "-")
+	for i, part := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(part)
+		case len(part) == 4:
+			parts[i] = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+		default:
+			parts[i] = strings.ToUpper(part)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+func (l *Locale) Validate() error {
+	l.Code = NormalizeLocale(l.Code)
+	if !localePattern.MatchString(l.Code) {
+		return errors.New("code must be a locale like en, en-US or zh-Hant-TW")
+	}
+	return nil
+}
+
+// LocaleFallbacks возвращает цепочку локалей для запроса: de-AT -> de-AT, de, <default>.
+// В цепочку попадают только локали проекта.
+func LocaleFallbacks(requested string, locales []string, defaultLocale string) []string {
+	known := make(map[string]bool, len(locales))
+	for _, locale := range locales {
+		known[locale] = true
+	}
+	var chain []string
+	add := func(locale string) {
+		if !known[locale] {
+			return
+		}
+		for _, existing := range chain {
+			if existing == locale {
+				return
+			}
+		}
+		chain = append(chain, locale)
+	}
+	requested = NormalizeLocale(requested)
+	for parts := strings.Split(requested, "-"); len(parts) > 0; parts = parts[:len(parts)-1] {
+		add(strings.Join(parts, "-"))
+	}
+	add(defaultLocale)
+	return chain
+}
+
+// MatchLocale выбирает локаль проекта по списку запрошенных (например, из Accept-Language):
+// первая запрошенная, у которой есть точное совпадение или совпадение по языку.
+// Если совпадений нет, возвращается локаль по умолчанию.
+func MatchLocale(requested []string, locales []string, defaultLocale string) string {
+	for _, locale := range requested {
+		if chain := LocaleFallbacks(locale, locales, ""); len(chain) > 0 {
+			return chain[0]
+		}
+	}
+	return defaultLocale
+}
+
+// TranslationKeyMax - максимальная длина ключа перевода.
+const TranslationKeyMax = 200
+
+var translationKeyPattern = regexp.MustCompile(`^[a-z0-9_-]+(\.[a-z0-9_-]+)*$`)
+
+// ValidateTranslationKey проверяет ключ перевода: сегменты через точку, как checkout.title.
+func ValidateTranslationKey(key string) error {
+	if len(key) > TranslationKeyMax || !translationKeyPattern.MatchString(key) {
+		return fmt.Errorf("key %q must be dot-separated segments of a-z, 0-9, '_' and '-'", key)
+	}
+	return nil
+}
+
+var translationRefPattern = regexp.MustCompile(`^\{t:([a-z0-9_.-]+)\}$`)
+
+// ParseTranslationRef разбирает ссылку на ключ перевода - строку вида "{t:checkout.title}".
+func ParseTranslationRef(value interface{}) (key string, ok bool) {
+	s, ok := value.(string)
+	if !ok {
+		return "", false
+	}
+	match := translationRefPattern.FindStringSubmatch(s)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+// Translations - переводы проекта: ключ -> локаль -> текст.
+type Translations map[string]map[string]string
+
+// Lookup возвращает перевод ключа по цепочке локалей.
+func (t Translations) Lookup(key string, chain []string) (string, bool) {
+	for _, locale := range chain {
+		if value, ok := t[key][locale]; ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// Resolve возвращает копию документа, в которой ссылки на ключи заменены переводами
+// по цепочке локалей; ключ без перевода подставляется как есть.
+func (t Translations) Resolve(value interface{}, chain []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		resolved := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			resolved[key] = t.Resolve(item, chain)
+		}
+		return resolved
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved[i] = t.Resolve(item, chain)
+		}
+		return resolved
+	}
+	key, ok := ParseTranslationRef(value)
+	if !ok {
+		return value
+	}
+	if text, ok := t.Lookup(key, chain); ok {
+		return text
+	}
+	return key
+}
+
+// Translation - перевод ключа на одну локаль.
+type Translation struct {
+	Key       string    `json:"key" db:"key"`
+	Locale    string    `json:"locale" db:"locale"`
+	Value     string    `json:"value" db:"value"`
+	UpdatedBy string    `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TranslationMaxLength - максимальная длина текста перевода.
+const TranslationMaxLength = 10000
+
+// TranslationsUpdate - изменение переводов: ключ -> локаль -> текст; null удаляет перевод.
+type TranslationsUpdate struct {
+	Translations map[string]map[string]*string `json:"translations"`
+}
+
+func (u *TranslationsUpdate) Validate() error {
+	if len(u.Translations) == 0 {
+		return errors.New("translations are required")
+	}
+	for key, values := range u.Translations {
+		if err := ValidateTranslationKey(key); err != nil {
+			return err
+		}
+		for locale, value := range values {
+			if !localePattern.MatchString(locale) {
+				return fmt.Errorf("key %q: unknown locale format %q", key, locale)
+			}
+			if value != nil && len(*value) > TranslationMaxLength {
+				return fmt.Errorf("key %q, locale %s: translation must be less than %d characters", key, locale, TranslationMaxLength)
+			}
+		}
+	}
+	return nil
+}
+
+// Форматы импорта и экспорта переводов: плоский JSON "ключ": "текст" одной локали и XLIFF 1.2.
+const (
+	TranslationsFormatJSON  = "json"
+	TranslationsFormatXLIFF = "xliff"
+)
+
+// TranslationUsage - виджет головной версии Main экрана или последней версии компонента, который ссылается на ключ.
+type TranslationUsage struct {
+	ScreenId      string `json:"screen_id,omitempty"`
+	ScreenName    string `json:"screen_name,omitempty"`
+	ComponentId   string `json:"component_id,omitempty"`
+	ComponentName string `json:"component_name,omitempty"`
+	WidgetId      string `json:"widget_id"`
+}
+
+// MissingTranslation - используемый ключ без перевода на локаль.
+type MissingTranslation struct {
+	Key    string             `json:"key"`
+	Usages []TranslationUsage `json:"usages"`
+}
+
+// LocaleCoverage - покрытие локали переводами используемых ключей.
+type LocaleCoverage struct {
+	Locale     string               `json:"locale"`
+	IsDefault  bool                 `json:"is_default"`
+	Total      int                  `json:"total"`
+	Translated int                  `json:"translated"`
+	Missing    []MissingTranslation `json:"missing"`
+}
+
+// TranslationReport - отчет о недостающих переводах. Unused - ключи с переводами,
+// на которые не ссылается ни один экран или компонент.
+type TranslationReport struct {
+	Locales []LocaleCoverage `json:"locales"`
+	Unused  []string         `json:"unused"`
+}
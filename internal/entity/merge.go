@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"errors"
+
+	"ui-platform-backend-service/pkg/jsonmerge"
+)
+
+const (
+	MergeTakeTarget = "target"
+	MergeTakeSource = "source"
+	MergeTakeValue  = "value"
+	MergeTakeDelete = "delete"
+)
+
+// MergeResolution - решение клиента по конфликту слияния на пути Path.
+// Take выбирает сторону (target/source), явное значение Value или удаление.
+type MergeResolution struct {
+	Path  string      `json:"path"`
+	Take  string      `json:"take"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// MergeRequest - слияние ветки-источника в целевую ветку.
+// TargetVersion - ожидаемая головная версия целевой ветки: если голова ушла вперед,
+// конфликты нужно пересчитать. SourceVersion - сливаемая версия источника (0 - головная).
+type MergeRequest struct {
+	SourceBranchId string            `json:"source_branch_id"`
+	SourceVersion  int               `json:"source_version,omitempty"`
+	TargetVersion  int               `json:"target_version,omitempty"`
+	Message        string            `json:"message,omitempty"`
+	Resolutions    []MergeResolution `json:"resolutions,omitempty"`
+	DryRun         bool              `json:"dry_run,omitempty"`
+}
+
+func (r *MergeRequest) Validate() error {
+	if r.SourceBranchId == "" {
+		return errors.New("source_branch_id is required")
+	}
+	if r.SourceVersion < 0 || r.TargetVersion < 0 {
+		return errors.New("versions must be positive")
+	}
+	if len(r.Message) > 500 {
+		return errors.New("message must be less than 500 characters")
+	}
+	for _, resolution := range r.Resolutions {
+		switch resolution.Take {
+		case MergeTakeTarget, MergeTakeSource, MergeTakeValue, MergeTakeDelete:
+		default:
+			return errors.New("resolution take must be one of target, source, value, delete")
+		}
+	}
+	return nil
+}
+
+// VersionRef - ссылка на версию ветки.
+type VersionRef struct {
+	BranchId string `json:"branch_id,omitempty"`
+	Version  int    `json:"version"`
+}
+
+// MergeResult - результат слияния. В конфликтах ours - целевая ветка, theirs - источник.
+// Если слияние выполнено, Version содержит созданную версию целевой ветки.
+type MergeResult struct {
+	Merged    bool                   `json:"merged"`
+	Base      VersionRef             `json:"base"`
+	Source    VersionRef             `json:"source"`
+	Target    VersionRef             `json:"target"`
+	Conflicts []jsonmerge.Conflict   `json:"conflicts"`
+	Widgets   map[string]interface{} `json:"widgets,omitempty"`
+	Settings  map[string]interface{} `json:"settings,omitempty"`
+	Version   *ScreenVersion         `json:"version,omitempty"`
+}
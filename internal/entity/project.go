@@ -11,14 +11,86 @@ const (
 	ProjectStatusArchived    = "Archived"
 )
 
+const (
+	ProjectRoleOwner  = "owner"
+	ProjectRoleAdmin  = "admin"
+	ProjectRoleEditor = "editor"
+	ProjectRoleViewer = "viewer"
+)
+
+// projectRoleRank задает иерархию ролей: старшая роль включает права младших.
+var projectRoleRank = map[string]int{
+	ProjectRoleViewer: 1,
+	ProjectRoleEditor: 2,
+	ProjectRoleAdmin:  3,
+	ProjectRoleOwner:  4,
+}
+
+// RoleAtLeast сообщает, не ниже ли роль role требуемой роли required.
+func RoleAtLeast(role, required string) bool {
+	return projectRoleRank[role] > 0 && projectRoleRank[role] >= projectRoleRank[required]
+}
+
+const (
+	ProjectActionPublish   = "publish"
+	ProjectActionUnpublish = "unpublish"
+	ProjectActionArchive   = "archive"
+)
+
+// ProjectTransition описывает допустимый переход статуса проекта
+// и минимальную роль, необходимую для его выполнения.
+type ProjectTransition struct {
+	Action string
+	From   string
+	To     string
+	Role   string
+}
+
+// projectTransitions - конечный автомат жизненного цикла проекта.
+// Переходы, отсутствующие в списке, запрещены.
+var projectTransitions = []ProjectTransition{
+	{Action: ProjectActionPublish, From: ProjectStatusUnPublished, To: ProjectStatusPublished, Role: ProjectRoleAdmin},
+	{Action: ProjectActionUnpublish, From: ProjectStatusPublished, To: ProjectStatusUnPublished, Role: ProjectRoleAdmin},
+	{Action: ProjectActionUnpublish, From: ProjectStatusArchived, To: ProjectStatusUnPublished, Role: ProjectRoleOwner},
+	{Action: ProjectActionArchive, From: ProjectStatusUnPublished, To: ProjectStatusArchived, Role: ProjectRoleOwner},
+	{Action: ProjectActionArchive, From: ProjectStatusPublished, To: ProjectStatusArchived, Role: ProjectRoleOwner},
+}
+
+// FindProjectTransition возвращает переход для действия action из статуса from.
+func FindProjectTransition(action, from string) (ProjectTransition, bool) {
+	for _, transition := range projectTransitions {
+		if transition.Action == action && transition.From == from {
+			return transition, true
+		}
+	}
+	return ProjectTransition{}, false
+}
+
+const (
+	ProjectSortCreatedAt = "created_at"
+	ProjectSortUpdatedAt = "updated_at"
+	ProjectSortName      = "name"
+
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+
+	ProjectListDefaultLimit = 20
+	ProjectListMaxLimit     = 100
+)
+
 type Project struct {
-	ID          string    `json:"id,omitempty" db:"id"`
-	Name        string    `json:"name,omitempty" db:"name"`
-	Description string    `json:"description,omitempty" db:"description"`
-	Status      string    `json:"status,omitempty" db:"status"`
-	CreatedAt   time.Time `json:"created_at,omitempty" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at,omitempty" db:"updated_at"`
-	DeletedAt   time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	ID          string     `json:"id,omitempty" db:"id"`
+	Name        string     `json:"name,omitempty" db:"name"`
+	Description string     `json:"description,omitempty" db:"description"`
+	Status      string     `json:"status,omitempty" db:"status"`
+	Role        string     `json:"role,omitempty" db:"role"`
+	IsTemplate  bool       `json:"is_template,omitempty" db:"is_template"`
+	SourceId    string     `json:"source_project_id,omitempty" db:"source_project_id"`
+	WorkspaceId string     `json:"workspace_id,omitempty" db:"workspace_id"`
+	CreatedAt   time.Time  `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at,omitempty" db:"updated_at"`
+	DeletedAt   time.Time  `json:"deleted_at,omitempty" db:"deleted_at"`
+	PurgeAt     *time.Time `json:"purge_at,omitempty" db:"-"`
 }
 
 func (p *Project) EntityName() string {
@@ -42,3 +114,78 @@ func (p *Project) Validate() error {
 
 	return nil
 }
+
+// ProjectCopy - параметры копирования проекта или создания проекта из шаблона.
+type ProjectCopy struct {
+	Name string `json:"name,omitempty"`
+}
+
+func (p *ProjectCopy) Validate() error {
+	if len(p.Name) > 100 {
+		return errors.New("name must be less than 100 characters")
+	}
+	return nil
+}
+
+// ProjectFilter описывает параметры выборки списка проектов пользователя.
+type ProjectFilter struct {
+	Search      string `query:"search"`
+	Status      string `query:"status"`
+	Role        string `query:"role"`
+	WorkspaceId string `query:"workspace_id"`
+	SortBy      string `query:"sort_by"`
+	SortOrder   string `query:"sort_order"`
+	Cursor      string `query:"cursor"`
+	Limit       int    `query:"limit"`
+}
+
+// Validate проверяет параметры фильтра и проставляет значения по умолчанию.
+func (f *ProjectFilter) Validate() error {
+	switch f.Status {
+	case "", ProjectStatusPublished, ProjectStatusUnPublished, ProjectStatusArchived:
+	default:
+		return errors.New("invalid status")
+	}
+
+	switch f.Role {
+	case "", ProjectRoleOwner, ProjectRoleAdmin, ProjectRoleEditor, ProjectRoleViewer:
+	default:
+		return errors.New("invalid role")
+	}
+
+	switch f.SortBy {
+	case "":
+		f.SortBy = ProjectSortCreatedAt
+	case ProjectSortCreatedAt, ProjectSortUpdatedAt, ProjectSortName:
+	default:
+		return errors.New("sort_by must be one of created_at, updated_at, name")
+	}
+
+	switch f.SortOrder {
+	case "":
+		f.SortOrder = SortOrderDesc
+	case SortOrderAsc, SortOrderDesc:
+	default:
+		return errors.New("sort_order must be asc or desc")
+	}
+
+	if f.Limit < 0 || f.Limit > ProjectListMaxLimit {
+		return errors.New("limit must be between 0 and 100 (0 = default)")
+	}
+	if f.Limit == 0 {
+		f.Limit = ProjectListDefaultLimit
+	}
+
+	if len(f.Search) > 200 {
+		return errors.New("search must be less than 200 characters")
+	}
+
+	return nil
+}
+
+// ProjectList - страница списка проектов.
+type ProjectList struct {
+	Projects   []Project `json:"projects"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	Total      int       `json:"total"`
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// ScreenRelease - неизменяемый опубликованный снимок версии ветки экрана.
+// Живой релиз (IsLive) у экрана один - его отдает runtime. Откат делает
+// живым один из прежних релизов, содержимое релизов не меняется.
+// Компоненты и дизайн-токены в релизе уже развернуты.
+type ScreenRelease struct {
+	Id            string                 `json:"id" db:"id"`
+	ScreenId      string                 `json:"screen_id" db:"screen_id"`
+	ReleaseNumber int                    `json:"release_number" db:"release_number"`
+	BranchId      string                 `json:"branch_id" db:"branch_id"`
+	Version       int                    `json:"version" db:"version"`
+	Widgets       map[string]interface{} `json:"widgets,omitempty" db:"widgets"`
+	Settings      map[string]interface{} `json:"settings,omitempty" db:"settings"`
+	// Themes - значения токенов для тем, кроме темы по умолчанию: тема -> JSON Pointer документа -> значение
+	Themes    map[string]map[string]interface{} `json:"themes,omitempty" db:"themes"`
+	Notes     string                            `json:"notes,omitempty" db:"notes"`
+	IsLive    bool                              `json:"is_live" db:"is_live"`
+	CreatedBy string                            `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt time.Time                         `json:"created_at,omitempty" db:"created_at"`
+}
+
+func (r *ScreenRelease) EntityName() string {
+	return "screens_releases"
+}
+
+// ScreenReleaseCreate - публикация версии ветки. Пустая ветка означает Main,
+// нулевая версия - головную версию ветки.
+type ScreenReleaseCreate struct {
+	BranchId string `json:"branch_id,omitempty"`
+	Version  int    `json:"version,omitempty"`
+	Notes    string `json:"notes,omitempty"`
+}
+
+func (r *ScreenReleaseCreate) Validate() error {
+	if r.Version < 0 {
+		return errors.New("version must be positive")
+	}
+	if len(r.Notes) > 1000 {
+		return errors.New("notes must be less than 1000 characters")
+	}
+	return nil
+}
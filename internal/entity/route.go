@@ -0,0 +1,191 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Route - путь URL проекта, открывающий экран. Сегменты пути бывают статическими
+// (/orders) и параметрами (/orders/:id); значения параметров передаются экрану
+// при переходе по deep link. Путь "/" - точка входа приложения.
+type Route struct {
+	Id        string    `json:"id" db:"id"`
+	ProjectId string    `json:"project_id" db:"project_id"`
+	Path      string    `json:"path" db:"path"`
+	ScreenId  string    `json:"screen_id" db:"screen_id"`
+	CreatedBy string    `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (r *Route) EntityName() string {
+	return "projects_routes"
+}
+
+// RouteMaxLength - максимальная длина пути маршрута.
+const RouteMaxLength = 300
+
+var (
+	routeStaticSegment = regexp.MustCompile(`^[A-Za-z0-9._~-]+$`)
+	routeParamSegment  = regexp.MustCompile(`^:[a-z_][a-z0-9_]{0,63}$`)
+)
+
+// Validate нормализует путь (убирает завершающий "/") и проверяет сегменты.
+func (r *Route) Validate() error {
+	if r.ScreenId == "" {
+		return errors.New("screen_id is required")
+	}
+	if !strings.HasPrefix(r.Path, "/") {
+		return errors.New("path must start with '/'")
+	}
+	if len(r.Path) > RouteMaxLength {
+		return fmt.Errorf("path must be less than %d characters", RouteMaxLength)
+	}
+	r.Path = "/" + strings.Trim(r.Path, "/")
+
+	params := map[string]bool{}
+	for _, segment := range routeSegments(r.Path) {
+		switch {
+		case routeParamSegment.MatchString(segment):
+			if params[segment] {
+				return fmt.Errorf("path parameter %s is repeated", segment)
+			}
+			params[segment] = true
+		case !routeStaticSegment.MatchString(segment):
+			return fmt.Errorf("path segment %q is invalid: use letters, digits, '.', '_', '~', '-' or :param", segment)
+		}
+	}
+	return nil
+}
+
+// Shape - путь без имен параметров. Маршруты одной формы (/orders/:id и /orders/:order_id)
+// совпадают с одними и теми же URL, поэтому в проекте допускается только один из них.
+func (r *Route) Shape() string {
+	segments := routeSegments(r.Path)
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = ":"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// Match сопоставляет путь URL с маршрутом и возвращает значения параметров.
+func (r *Route) Match(path string) (params map[string]string, ok bool) {
+	pattern, segments := routeSegments(r.Path), routeSegments(path)
+	if len(pattern) != len(segments) {
+		return nil, false
+	}
+	params = map[string]string{}
+	for i, segment := range pattern {
+		if strings.HasPrefix(segment, ":") {
+			params[segment[1:]] = segments[i]
+			continue
+		}
+		if segment != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// ResolveRoute находит маршрут для deep link. Строка запроса и фрагмент отбрасываются.
+// Если подходят несколько маршрутов, выбирается более конкретный: статический
+// сегмент важнее параметра в той же позиции (/orders/new важнее /orders/:id).
+func ResolveRoute(routes []Route, link string) (route Route, params map[string]string, ok bool) {
+	if i := strings.IndexAny(link, "?#"); i >= 0 {
+		link = link[:i]
+	}
+	for _, candidate := range routes {
+		candidateParams, matched := candidate.Match(link)
+		if !matched {
+			continue
+		}
+		if !ok || moreSpecific(candidate.Path, route.Path) {
+			route, params, ok = candidate, candidateParams, true
+		}
+	}
+	return route, params, ok
+}
+
+func moreSpecific(a, b string) bool {
+	aSegments, bSegments := routeSegments(a), routeSegments(b)
+	for i := range aSegments {
+		aParam, bParam := strings.HasPrefix(aSegments[i], ":"), strings.HasPrefix(bSegments[i], ":")
+		if aParam != bParam {
+			return bParam
+		}
+	}
+	return false
+}
+
+func routeSegments(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return []string{}
+	}
+	return strings.Split(path, "/")
+}
+
+// Переходы между экранами задаются действием виджета:
+//
+//	{"type": "button", "props": {"label": "Open", "action": {"type": "navigate", "target": "/orders/:id"}}}
+//
+// target - путь маршрута проекта (начинается с "/") или идентификатор экрана.
+// При копировании проекта идентификаторы экранов в target заменяются на копии.
+const WidgetActionNavigate = "navigate"
+
+// NavigationTarget возвращает цель перехода виджета, если его действие - navigate.
+func NavigationTarget(widget Widget) (target string, ok bool) {
+	action, ok := widget.Props["action"].(map[string]interface{})
+	if !ok || action["type"] != WidgetActionNavigate {
+		return "", false
+	}
+	target, ok = action["target"].(string)
+	return target, ok && target != ""
+}
+
+// Причины, по которым переход или маршрут никуда не ведет.
+const (
+	NavigationDanglingScreen      = "screen_not_found"
+	NavigationDanglingRoute       = "route_not_found"
+	NavigationDanglingRouteScreen = "route_screen_deleted"
+)
+
+// NavigationGraph - граф переходов проекта по головным версиям Main. Висячие переходы
+// входят в Edges с Dangling, маршруты на удаленные экраны - в DanglingRoutes.
+type NavigationGraph struct {
+	Nodes          []NavigationNode `json:"nodes"`
+	Edges          []NavigationEdge `json:"edges"`
+	DanglingRoutes []Route          `json:"dangling_routes"`
+}
+
+// NavigationNode - экран проекта и его маршруты. Entry - экран открывается по пути "/".
+type NavigationNode struct {
+	ScreenId string   `json:"screen_id"`
+	Name     string   `json:"name"`
+	Status   string   `json:"status"`
+	Routes   []string `json:"routes"`
+	Entry    bool     `json:"entry"`
+}
+
+// NavigationEdge - переход, объявленный действием виджета экрана From.
+type NavigationEdge struct {
+	From     string `json:"from"`
+	WidgetId string `json:"widget_id"`
+	Target   string `json:"target"`
+	To       string `json:"to,omitempty"`
+	Route    string `json:"route,omitempty"`
+	Dangling bool   `json:"dangling"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// RouteResolution - результат разрешения deep link.
+type RouteResolution struct {
+	Route    Route             `json:"route"`
+	ScreenId string            `json:"screen_id"`
+	Params   map[string]string `json:"params"`
+}
@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"time"
+)
+
+// RuntimeScreen - живой релиз экрана в том виде, в котором его получают приложения.
+type RuntimeScreen struct {
+	Id            string                            `json:"id"`
+	ReleaseNumber int                               `json:"release_number"`
+	Widgets       map[string]interface{}            `json:"widgets"`
+	Settings      map[string]interface{}            `json:"settings"`
+	Themes        map[string]map[string]interface{} `json:"themes,omitempty"`
+	PublishedAt   time.Time                         `json:"published_at"`
+}
+
+// RuntimeProject - снимок опубликованного проекта для публичного runtime API.
+// UpdatedAt - время последнего изменения данных снимка (релизов, маршрутов, переводов),
+// оно служит Last-Modified. В тело ответа оно не попадает, чтобы ETag зависел
+// только от содержимого.
+//
+// Снимок хранит тексты со ссылками на ключи и все переводы проекта (Translations);
+// Localize подставляет переводы выбранной локали перед ответом.
+type RuntimeProject struct {
+	Id            string          `json:"id"`
+	Screens       []RuntimeScreen `json:"screens"`
+	Routes        []RuntimeRoute  `json:"routes"`
+	Locale        string          `json:"locale,omitempty"`
+	DefaultLocale string          `json:"default_locale,omitempty"`
+	Locales       []string        `json:"locales,omitempty"`
+	Translations  Translations    `json:"translations,omitempty"`
+	UpdatedAt     time.Time       `json:"-"`
+}
+
+// RuntimeRoute - маршрут проекта на экран с живым релизом.
+type RuntimeRoute struct {
+	Path     string `json:"path" db:"path"`
+	ScreenId string `json:"screen_id" db:"screen_id"`
+}
+
+// FindScreen ищет экран снимка по идентификатору.
+func (p *RuntimeProject) FindScreen(screenId string) (RuntimeScreen, bool) {
+	for _, screen := range p.Screens {
+		if screen.Id == screenId {
+			return screen, true
+		}
+	}
+	return RuntimeScreen{}, false
+}
+
+// ResolveLink находит экран для deep link по маршрутам снимка.
+func (p *RuntimeProject) ResolveLink(link string) (resolution RouteResolution, ok bool) {
+	routes := make([]Route, len(p.Routes))
+	for i, route := range p.Routes {
+		routes[i] = Route{Path: route.Path, ScreenId: route.ScreenId}
+	}
+	route, params, ok := ResolveRoute(routes, link)
+	if !ok {
+		return RouteResolution{}, false
+	}
+	return RouteResolution{Route: route, ScreenId: route.ScreenId, Params: params}, true
+}
+
+// Localize возвращает копию снимка, в которой ссылки на ключи переводов заменены
+// текстами локали из requested (см. MatchLocale). Переводы в копию не попадают.
+func (p RuntimeProject) Localize(requested []string) RuntimeProject {
+	localized := p
+	localized.Translations = nil
+	localized.Locale = MatchLocale(requested, p.Locales, p.DefaultLocale)
+	chain := LocaleFallbacks(localized.Locale, p.Locales, p.DefaultLocale)
+	localized.Screens = make([]RuntimeScreen, len(p.Screens))
+	for i, screen := range p.Screens {
+		screen.Widgets, _ = p.Translations.Resolve(screen.Widgets, chain).(map[string]interface{})
+		screen.Settings, _ = p.Translations.Resolve(screen.Settings, chain).(map[string]interface{})
+		localized.Screens[i] = screen
+	}
+	return localized
+}
@@ -1,6 +1,9 @@
 package entity
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 const (
 	ScreenStatusPublished   = "Published"
@@ -8,6 +11,12 @@ const (
 	ScreenStatusArchived    = "Archived"
 )
 
+// ScreenMainBranch - имя ветки, создаваемой вместе с экраном.
+const ScreenMainBranch = "Main"
+
+// Screen хранит метаданные экрана и копию головной версии ветки Main.
+// Version - номер этой головной версии, BaseVersion - версия, от которой
+// клиент начинал редактирование виджетов при обновлении экрана.
 type Screen struct {
 	Id          string                 `json:"id" db:"id"`
 	ProjectId   string                 `json:"project_id" db:"project_id"`
@@ -16,7 +25,29 @@ type Screen struct {
 	Status      string                 `json:"status,omitempty" db:"status"`
 	Widgets     map[string]interface{} `json:"widgets,omitempty" db:"widgets"`
 	Settings    map[string]interface{} `json:"settings,omitempty" db:"settings"`
+	Version     int                    `json:"version,omitempty" db:"-"`
+	BaseVersion int                    `json:"base_version,omitempty" db:"-"`
 	CreatedAt   time.Time              `json:"created_at,omitempty" db:"created_at"`
 	UpdatedAt   time.Time              `json:"updated_at,omitempty" db:"updated_at"`
 	DeletedAt   time.Time              `json:"deleted_at,omitempty" db:"deleted_at"`
 }
+
+func (s *Screen) EntityName() string {
+	return "screens"
+}
+
+func (s *Screen) Validate() error {
+	if s.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(s.Name) > 100 {
+		return errors.New("name must be less than 100 characters")
+	}
+	if len(s.Description) > 4000 {
+		return errors.New("description must be less than 4000 characters")
+	}
+	if s.BaseVersion < 0 {
+		return errors.New("base_version must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Категории дизайн-токенов. Категория - первый сегмент имени токена: color.primary, spacing.md.
+const (
+	TokenCategoryColor      = "color"
+	TokenCategoryTypography = "typography"
+	TokenCategorySpacing    = "spacing"
+	TokenCategoryRadius     = "radius"
+)
+
+// Темы проекта. Value токена относится к теме по умолчанию (light),
+// Themes переопределяет значение для остальных тем.
+const (
+	ThemeLight = "light"
+	ThemeDark  = "dark"
+)
+
+// Themes - темы проекта; первая - тема по умолчанию.
+var Themes = []string{ThemeLight, ThemeDark}
+
+// DesignTokensMax - максимальное число токенов проекта.
+const DesignTokensMax = 1000
+
+// Форматы экспорта токенов: CSS-переменные и JSON в формате Design Tokens Community Group.
+const (
+	TokensExportCSS  = "css"
+	TokensExportJSON = "json"
+)
+
+// DesignTokens - дизайн-токены проекта. Хранятся одним документом и заменяются целиком;
+// Version растет с каждым сохранением.
+//
+// Свойства виджетов и настройки экрана ссылаются на токен строкой "{color.primary}".
+// Ссылки разрешаются при публикации: в релиз попадают значения темы по умолчанию,
+// а значения остальных тем - переопределениями по путям документа (ScreenRelease.Themes).
+type DesignTokens struct {
+	ProjectId string                 `json:"project_id"`
+	Tokens    map[string]DesignToken `json:"tokens"`
+	Version   int                    `json:"version"`
+	UpdatedBy string                 `json:"updated_by,omitempty"`
+	UpdatedAt time.Time              `json:"updated_at,omitempty"`
+}
+
+func (t *DesignTokens) EntityName() string {
+	return "projects_design_tokens"
+}
+
+// DesignToken - значение токена. Цвет - строка (#rrggbb, rgb(), hsl()), отступы и радиусы -
+// число пикселей или размер с единицей (16px, 1rem), типографика - объект TypographyFields.
+type DesignToken struct {
+	Value       interface{}            `json:"value"`
+	Themes      map[string]interface{} `json:"themes,omitempty"`
+	Description string                 `json:"description,omitempty"`
+}
+
+// TokenCategory возвращает категорию токена по имени.
+func TokenCategory(name string) string {
+	category, _, _ := strings.Cut(name, ".")
+	return category
+}
+
+// ThemeValue возвращает значение токена в теме; без переопределения - значение по умолчанию.
+func (t DesignToken) ThemeValue(theme string) interface{} {
+	if value, ok := t.Themes[theme]; ok {
+		return value
+	}
+	return t.Value
+}
+
+// Names возвращает имена токенов по алфавиту.
+func (t *DesignTokens) Names() []string {
+	names := make([]string, 0, len(t.Tokens))
+	for name := range t.Tokens {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DesignTokensUpdate - замена токенов проекта. BaseVersion - версия, от которой начиналось
+// редактирование; если токены успели сохранить, замена отклоняется.
+type DesignTokensUpdate struct {
+	Tokens      map[string]DesignToken `json:"tokens"`
+	BaseVersion int                    `json:"base_version,omitempty"`
+}
+
+var (
+	tokenNamePattern = regexp.MustCompile(`^(color|typography|spacing|radius)(\.[a-z0-9][a-z0-9_-]{0,63}){1,4}$`)
+	colorPattern     = regexp.MustCompile(`^(#([0-9a-fA-F]{3}|[0-9a-fA-F]{4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})|(rgb|rgba|hsl|hsla)\([0-9.,%\s/]+\))$`)
+	dimensionPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?(px|rem|em|%)$`)
+	// fontFamilyPattern - список семейств через запятую: имена в кавычках или без них
+	// и общие семейства. Значение попадает в CSS как есть, поэтому ;, {, }, < и >
+	// и экранирование в нем запрещены
+	fontFamilyPattern = regexp.MustCompile(`^\s*("[^"\\;{}<>\r\n]+"|'[^'\\;{}<>\r\n]+'|[A-Za-z][A-Za-z0-9 _-]*)\s*(,\s*("[^"\\;{}<>\r\n]+"|'[^'\\;{}<>\r\n]+'|[A-Za-z][A-Za-z0-9 _-]*)\s*)*$`)
+)
+
+// TypographyFields - допустимые поля токена типографики.
+var TypographyFields = []string{"font_family", "font_size", "font_weight", "line_height", "letter_spacing"}
+
+func (u *DesignTokensUpdate) Validate() error {
+	if u.BaseVersion < 0 {
+		return errors.New("base_version must be positive")
+	}
+	if len(u.Tokens) > DesignTokensMax {
+		return fmt.Errorf("project can have at most %d tokens", DesignTokensMax)
+	}
+	for name, token := range u.Tokens {
+		if !tokenNamePattern.MatchString(name) {
+			return fmt.Errorf("token %q: name must be <category>.<name> with category color, typography, spacing or radius", name)
+		}
+		if len(token.Description) > 500 {
+			return fmt.Errorf("token %q: description must be less than 500 characters", name)
+		}
+		if err := validateTokenValue(TokenCategory(name), token.Value); err != nil {
+			return fmt.Errorf("token %q: %w", name, err)
+		}
+		for theme, value := range token.Themes {
+			if theme == Themes[0] || !isTheme(theme) {
+				return fmt.Errorf("token %q: themes may override only %s", name, strings.Join(Themes[1:], ", "))
+			}
+			if err := validateTokenValue(TokenCategory(name), value); err != nil {
+				return fmt.Errorf("token %q, theme %s: %w", name, theme, err)
+			}
+		}
+	}
+	// Токен не может быть группой других токенов: в JSON-экспорте это один и тот же узел
+	for name := range u.Tokens {
+		for i := strings.LastIndex(name, "."); i > 0; i = strings.LastIndex(name[:i], ".") {
+			if _, ok := u.Tokens[name[:i]]; ok {
+				return fmt.Errorf("token %q: name is used as a group by %q", name[:i], name)
+			}
+		}
+	}
+	return nil
+}
+
+func isTheme(theme string) bool {
+	for _, t := range Themes {
+		if t == theme {
+			return true
+		}
+	}
+	return false
+}
+
+func validateTokenValue(category string, value interface{}) error {
+	switch category {
+	case TokenCategoryColor:
+		color, ok := value.(string)
+		if !ok || !colorPattern.MatchString(color) {
+			return errors.New("color must be #hex, rgb(), rgba(), hsl() or hsla()")
+		}
+	case TokenCategorySpacing, TokenCategoryRadius:
+		if !isDimension(value) {
+			return errors.New("value must be a number of pixels or a size with px, rem, em or %")
+		}
+	case TokenCategoryTypography:
+		fields, ok := value.(map[string]interface{})
+		if !ok || len(fields) == 0 {
+			return errors.New("typography must be an object with " + strings.Join(TypographyFields, ", "))
+		}
+		for field, fieldValue := range fields {
+			switch field {
+			case "font_family":
+				if family, ok := fieldValue.(string); !ok || !IsFontFamily(family) {
+					return errors.New("font_family must be a comma-separated list of quoted or plain font names up to 200 characters")
+				}
+			case "font_weight":
+				weight, ok := fieldValue.(float64)
+				if !ok || weight < 1 || weight > 1000 {
+					return errors.New("font_weight must be a number from 1 to 1000")
+				}
+			case "line_height":
+				if _, ok := fieldValue.(float64); !ok && !isDimension(fieldValue) {
+					return errors.New("line_height must be a number or a size")
+				}
+			case "font_size", "letter_spacing":
+				if !isDimension(fieldValue) {
+					return fmt.Errorf("%s must be a number of pixels or a size", field)
+				}
+			default:
+				return fmt.Errorf("unknown typography field %q", field)
+			}
+		}
+	}
+	return nil
+}
+
+// IsFontFamily проверяет значение font_family (см. fontFamilyPattern).
+func IsFontFamily(family string) bool {
+	return len(family) <= 200 && fontFamilyPattern.MatchString(family)
+}
+
+func isDimension(value interface{}) bool {
+	switch v := value.(type) {
+	case float64:
+		return true
+	case string:
+		return dimensionPattern.MatchString(v)
+	}
+	return false
+}
+
+var tokenRefPattern = regexp.MustCompile(`^\{([a-z0-9._-]+)\}$`)
+
+// ParseTokenRef разбирает ссылку на токен - строку вида "{color.primary}".
+func ParseTokenRef(value interface{}) (name string, ok bool) {
+	s, ok := value.(string)
+	if !ok {
+		return "", false
+	}
+	match := tokenRefPattern.FindStringSubmatch(s)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"ui-platform-backend-service/pkg/jsonpatch"
+)
+
+// ScreenVersion - неизменяемый снимок виджетов и настроек экрана в ветке.
+// Каждое сохранение добавляет новую версию с номером на единицу больше головной.
+type ScreenVersion struct {
+	Id                  string                 `json:"id" db:"id"`
+	BranchId            string                 `json:"branch_id" db:"branch_id"`
+	Version             int                    `json:"version" db:"version"`
+	Widgets             map[string]interface{} `json:"widgets,omitempty" db:"widgets"`
+	Settings            map[string]interface{} `json:"settings,omitempty" db:"settings"`
+	AuthorId            string                 `json:"author_id,omitempty" db:"author_id"`
+	Message             string                 `json:"message,omitempty" db:"message"`
+	RestoredFrom        int                    `json:"restored_from,omitempty" db:"restored_from"`
+	MergeSourceBranchId string                 `json:"merge_source_branch_id,omitempty" db:"merge_source_branch_id"`
+	MergeSourceVersion  int                    `json:"merge_source_version,omitempty" db:"merge_source_version"`
+	CreatedAt           time.Time              `json:"created_at,omitempty" db:"created_at"`
+}
+
+func (v *ScreenVersion) EntityName() string {
+	return "screens_widgets"
+}
+
+// ScreenVersionCreate - тело запроса на сохранение новой версии.
+// BaseVersion - головная версия, от которой клиент начинал редактирование (также передается в If-Match).
+type ScreenVersionCreate struct {
+	Widgets     map[string]interface{} `json:"widgets"`
+	Settings    map[string]interface{} `json:"settings"`
+	Message     string                 `json:"message,omitempty"`
+	BaseVersion int                    `json:"base_version,omitempty"`
+}
+
+func (v *ScreenVersionCreate) Validate() error {
+	if v.Widgets == nil {
+		return errors.New("widgets is required")
+	}
+	if len(v.Message) > 500 {
+		return errors.New("message must be less than 500 characters")
+	}
+	if v.BaseVersion < 0 {
+		return errors.New("base_version must be positive")
+	}
+	return nil
+}
+
+// ScreenPatchMaxOperations - максимальное число операций в одном JSON Patch.
+const ScreenPatchMaxOperations = 1000
+
+// ScreenPatch - инкрементальное изменение головной версии ветки: JSON Patch (RFC 6902)
+// в Operations или JSON Merge Patch (RFC 7396) в MergePatch над документом {"widgets", "settings"}.
+type ScreenPatch struct {
+	Operations  []jsonpatch.Operation
+	MergePatch  map[string]interface{}
+	BaseVersion int
+	Message     string
+}
+
+func (p *ScreenPatch) Validate() error {
+	if (p.Operations == nil) == (p.MergePatch == nil) {
+		return errors.New("either json patch or merge patch is required")
+	}
+	if len(p.Operations) > ScreenPatchMaxOperations {
+		return errors.New("too many patch operations")
+	}
+	if p.BaseVersion < 0 {
+		return errors.New("base_version must be positive")
+	}
+	if len(p.Message) > 500 {
+		return errors.New("message must be less than 500 characters")
+	}
+	return nil
+}
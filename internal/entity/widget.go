@@ -0,0 +1,64 @@
+package entity
+
+// Виджеты экрана хранятся плоской картой id -> виджет:
+//
+//	{"header": {"type": "container", "props": {...}, "children": ["title", "logo"]}}
+//
+// Дерево задается списками children, порядок детей значим. Виджеты,
+// которые не указаны ни в одном children, считаются корневыми.
+// Допустимые типы и схемы props задаются типами виджетов (WidgetType).
+
+// Widget - разобранный виджет из карты виджетов экрана.
+type Widget struct {
+	Id       string                 `json:"id"`
+	Type     string                 `json:"type"`
+	Props    map[string]interface{} `json:"props"`
+	Children []string               `json:"children"`
+}
+
+// ParseWidget разбирает виджет из его JSON-представления. Поля неверного типа игнорируются.
+func ParseWidget(id string, raw interface{}) Widget {
+	widget := Widget{Id: id, Props: map[string]interface{}{}}
+	object, ok := raw.(map[string]interface{})
+	if !ok {
+		return widget
+	}
+	widget.Type, _ = object["type"].(string)
+	if props, ok := object["props"].(map[string]interface{}); ok {
+		widget.Props = props
+	}
+	if children, ok := object["children"].([]interface{}); ok {
+		for _, child := range children {
+			if childId, ok := child.(string); ok {
+				widget.Children = append(widget.Children, childId)
+			}
+		}
+	}
+	return widget
+}
+
+// ParseWidgets разбирает всю карту виджетов экрана.
+func ParseWidgets(widgets map[string]interface{}) map[string]Widget {
+	parsed := make(map[string]Widget, len(widgets))
+	for id, raw := range widgets {
+		parsed[id] = ParseWidget(id, raw)
+	}
+	return parsed
+}
+
+// WidgetPosition - положение виджета в дереве: родитель (пустой у корневых) и индекс среди детей.
+type WidgetPosition struct {
+	Parent string `json:"parent"`
+	Index  int    `json:"index"`
+}
+
+// WidgetPositions вычисляет положение каждого виджета, на который ссылается родитель.
+func WidgetPositions(widgets map[string]Widget) map[string]WidgetPosition {
+	positions := make(map[string]WidgetPosition, len(widgets))
+	for id, widget := range widgets {
+		for index, childId := range widget.Children {
+			positions[childId] = WidgetPosition{Parent: id, Index: index}
+		}
+	}
+	return positions
+}
@@ -0,0 +1,188 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"time"
+
+	"ui-platform-backend-service/pkg/jsonschema"
+)
+
+// WidgetType - тип виджета. Schema описывает объект props виджета этого типа,
+// AllowChildren разрешает виджету иметь дочерние виджеты. Встроенные типы
+// доступны во всех проектах, пользовательские хранятся в проекте.
+type WidgetType struct {
+	Name          string             `json:"name" db:"name"`
+	Description   string             `json:"description,omitempty" db:"description"`
+	Schema        *jsonschema.Schema `json:"schema" db:"-"`
+	AllowChildren bool               `json:"allow_children" db:"allow_children"`
+	Builtin       bool               `json:"builtin" db:"-"`
+	ProjectId     string             `json:"project_id,omitempty" db:"project_id"`
+	CreatedBy     string             `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt     time.Time          `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+func (t *WidgetType) EntityName() string {
+	return "projects_widget_types"
+}
+
+var widgetTypeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,63}$`)
+
+func (t *WidgetType) Validate() error {
+	if !widgetTypeNamePattern.MatchString(t.Name) {
+		return errors.New("name must start with a letter and contain only lowercase letters, digits, '-' and '_' (up to 64 characters)")
+	}
+	if _, ok := FindBuiltinWidgetType(t.Name); ok {
+		return errors.New("name is reserved by a builtin widget type")
+	}
+	if len(t.Description) > 500 {
+		return errors.New("description must be less than 500 characters")
+	}
+	if t.Schema == nil {
+		return errors.New("schema is required")
+	}
+	if t.Schema.Type != "" && t.Schema.Type != jsonschema.TypeObject {
+		return errors.New("schema type must be object")
+	}
+	return t.Schema.Check()
+}
+
+// Встроенные типы виджетов. Схемы не запрещают дополнительные свойства,
+// чтобы клиенты могли хранить в props собственные данные.
+var builtinWidgetTypes = mustParseWidgetTypes(`[
+	{
+		"name": "container",
+		"description": "Layout container for child widgets",
+		"allow_children": true,
+		"schema": {
+			"type": "object",
+			"properties": {
+				"direction": {"type": "string", "enum": ["row", "column"]},
+				"gap": {"type": "number", "minimum": 0},
+				"padding": {"type": "number", "minimum": 0},
+				"align": {"type": "string", "enum": ["start", "center", "end", "stretch"]}
+			}
+		}
+	},
+	{
+		"name": "text",
+		"description": "Static text",
+		"schema": {
+			"type": "object",
+			"required": ["text"],
+			"properties": {
+				"text": {"type": "string", "maxLength": 10000},
+				"variant": {"type": "string", "enum": ["h1", "h2", "h3", "body", "caption"]},
+				"color": {"type": "string"}
+			}
+		}
+	},
+	{
+		"name": "button",
+		"description": "Clickable button with an action",
+		"schema": {
+			"type": "object",
+			"required": ["label"],
+			"properties": {
+				"label": {"type": "string", "maxLength": 200},
+				"variant": {"type": "string", "enum": ["primary", "secondary", "link"]},
+				"disabled": {"type": "boolean"},
+				"action": {
+					"type": "object",
+					"required": ["type"],
+					"properties": {
+						"type": {"type": "string", "enum": ["navigate", "open_url", "submit"]},
+						"target": {"type": "string"}
+					}
+				}
+			}
+		}
+	},
+	{
+		"name": "image",
+		"description": "Image",
+		"schema": {
+			"type": "object",
+			"required": ["src"],
+			"properties": {
+				"src": {"type": "string", "minLength": 1},
+				"alt": {"type": "string", "maxLength": 500},
+				"width": {"type": "number", "minimum": 0},
+				"height": {"type": "number", "minimum": 0},
+				"fit": {"type": "string", "enum": ["contain", "cover", "fill"]}
+			}
+		}
+	},
+	{
+		"name": "input",
+		"description": "Form input field",
+		"schema": {
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": {"type": "string", "minLength": 1, "maxLength": 100},
+				"label": {"type": "string", "maxLength": 200},
+				"placeholder": {"type": "string", "maxLength": 200},
+				"input_type": {"type": "string", "enum": ["text", "email", "password", "number"]},
+				"required": {"type": "boolean"}
+			}
+		}
+	},
+	{
+		"name": "list",
+		"description": "Repeating list of child widgets",
+		"allow_children": true,
+		"schema": {
+			"type": "object",
+			"properties": {
+				"direction": {"type": "string", "enum": ["row", "column"]},
+				"gap": {"type": "number", "minimum": 0}
+			}
+		}
+	},
+	{
+		"name": "component",
+		"description": "Instance of a reusable component, latest version unless pinned",
+		"schema": {
+			"type": "object",
+			"required": ["component_id"],
+			"properties": {
+				"component_id": {"type": "string", "minLength": 1},
+				"version": {"type": "integer", "minimum": 1}
+			}
+		}
+	}
+]`)
+
+// BuiltinWidgetTypes возвращает встроенные типы виджетов.
+func BuiltinWidgetTypes() []WidgetType {
+	types := make([]WidgetType, len(builtinWidgetTypes))
+	copy(types, builtinWidgetTypes)
+	return types
+}
+
+// FindBuiltinWidgetType ищет встроенный тип по имени.
+func FindBuiltinWidgetType(name string) (WidgetType, bool) {
+	for _, t := range builtinWidgetTypes {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return WidgetType{}, false
+}
+
+func mustParseWidgetTypes(data string) []WidgetType {
+	var types []WidgetType
+	if err := json.Unmarshal([]byte(data), &types); err != nil {
+		panic(err)
+	}
+	for i := range types {
+		if err := types[i].Schema.Check(); err != nil {
+			panic(err)
+		}
+		types[i].Builtin = true
+	}
+	return types
+}
@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// Workspace - команда или организация, которой принадлежат проекты.
+// Роли участников воркспейса совпадают с ролями проекта и наследуются всеми его проектами.
+type Workspace struct {
+	ID          string    `json:"id,omitempty" db:"id"`
+	Name        string    `json:"name,omitempty" db:"name"`
+	Description string    `json:"description,omitempty" db:"description"`
+	Role        string    `json:"role,omitempty" db:"role"`
+	CreatedAt   time.Time `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at,omitempty" db:"updated_at"`
+	DeletedAt   time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+func (w *Workspace) EntityName() string {
+	return "workspaces"
+}
+
+func (w *Workspace) Validate() error {
+	if w.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(w.Name) > 100 {
+		return errors.New("name must be less than 100 characters")
+	}
+	if len(w.Description) > 4000 {
+		return errors.New("description must be less than 4000 characters")
+	}
+	return nil
+}
+
+// WorkspaceMember - участник воркспейса.
+type WorkspaceMember struct {
+	UserId  string    `json:"user_id,omitempty" db:"user_id"`
+	Email   string    `json:"email,omitempty" db:"email"`
+	Role    string    `json:"role,omitempty" db:"role"`
+	AddedAt time.Time `json:"added_at,omitempty" db:"added_at"`
+}
+
+func (m *WorkspaceMember) Validate() error {
+	if m.UserId == "" && m.Email == "" {
+		return errors.New("user_id or email is required")
+	}
+	switch m.Role {
+	case ProjectRoleOwner, ProjectRoleAdmin, ProjectRoleEditor, ProjectRoleViewer:
+	default:
+		return errors.New("role must be one of owner, admin, editor, viewer")
+	}
+	return nil
+}
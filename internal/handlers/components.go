@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+// componentScope определяет уровень компонентов по маршруту: обработчики компонентов
+// подключены и к проектам (/projects/:project_id), и к воркспейсам (/workspaces/:workspace_id).
+func componentScope(c *fiber.Ctx) entity.ComponentScope {
+	return entity.ComponentScope{
+		ProjectId:   c.Params("project_id"),
+		WorkspaceId: c.Params("workspace_id"),
+	}
+}
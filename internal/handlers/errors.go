@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/services"
+)
+
+// serviceError преобразует ошибку сервиса в HTTP-ответ.
+// Для неизвестных ошибок возвращается 500 с переданным сообщением.
+func (h *Handler) serviceError(c *fiber.Ctx, err error, message string) error {
+	// Устаревшее сохранение: отдаем текущую голову, чтобы клиент мог перебазировать правки
+	var stale *services.StaleVersionError
+	if errors.As(err, &stale) {
+		c.Set(fiber.HeaderETag, versionETag(stale.Head.Version))
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": err.Error(),
+			"details": fiber.Map{
+				"head": stale.Head,
+			},
+		})
+	}
+	// Ошибки проверки документа экрана отдаем с путями нарушений
+	var invalid *services.ValidationError
+	if errors.As(err, &invalid) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+			"details": fiber.Map{
+				"errors": invalid.Errors,
+			},
+		})
+	}
+	switch {
+	case errors.Is(err, services.ErrInvalid):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	case errors.Is(err, services.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	case errors.Is(err, services.ErrForbidden), errors.Is(err, services.ErrReadOnly):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	case errors.Is(err, services.ErrConflict):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	h.log.Error().Msgf("%s: %v", message, err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": message,
+	})
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ETag версии экрана - номер версии в кавычках. Клиент возвращает его
+// в If-Match при сохранении, чтобы не перезаписать чужие изменения.
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// baseVersion возвращает базовую версию сохранения из If-Match или из тела запроса.
+// Если заданы оба значения, они должны совпадать; ноль означает сохранение без проверки.
+func baseVersion(c *fiber.Ctx, bodyVersion int) (int, error) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" || header == "*" {
+		return bodyVersion, nil
+	}
+	header = strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	version, err := strconv.Atoi(header)
+	if err != nil || version <= 0 {
+		return 0, errors.New("invalid If-Match header")
+	}
+	if bodyVersion != 0 && bodyVersion != version {
+		return 0, errors.New("If-Match does not match base_version")
+	}
+	return version, nil
+}
+
+// sendCacheable отправляет JSON-ответ с ETag по содержимому и Last-Modified.
+// На условный запрос с совпавшим If-None-Match (или, без него, с не более
+// старым If-Modified-Since) отвечает 304 без тела.
+func sendCacheable(c *fiber.Ctx, payload fiber.Map, lastModified time.Time, maxAge time.Duration) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(body)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+	lastModified = lastModified.UTC().Truncate(time.Second)
+
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderLastModified, lastModified.Format(http.TimeFormat))
+	c.Set(fiber.HeaderCacheControl, "public, max-age="+strconv.Itoa(int(maxAge.Seconds())))
+	if notModified(c, etag, lastModified) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Status(fiber.StatusOK).Send(body)
+}
+
+func notModified(c *fiber.Ctx, etag string, lastModified time.Time) bool {
+	if noneMatch := c.Get(fiber.HeaderIfNoneMatch); noneMatch != "" {
+		for _, candidate := range strings.Split(noneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	if modifiedSince := c.Get(fiber.HeaderIfModifiedSince); modifiedSince != "" {
+		since, err := http.ParseTime(modifiedSince)
+		return err == nil && !lastModified.After(since)
+	}
+	return false
+}
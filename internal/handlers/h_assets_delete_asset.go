@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) deleteAsset(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId и assetId из параметров Path
+	projectId := c.Params("project_id")
+	assetId := c.Params("asset_id")
+	h.log.Debug().Msgf("projectId: %v, assetId: %v", projectId, assetId)
+	// Удаляем ассет
+	if err := h.services.Asset.Delete(projectId, assetId, userId); err != nil {
+		return h.serviceError(c, err, "error deleting asset")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+	})
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// downloadAsset отдает файл ассета по хешу. Содержимое по хешу не меняется,
+// поэтому ответ кешируется бессрочно.
+func (h *Handler) downloadAsset(thumbnail bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Получаем hash из параметров Path
+		hash := c.Params("hash")
+		h.log.Debug().Msgf("hash: %v, thumbnail: %v", hash, thumbnail)
+		etag := `"` + hash + `"`
+		if c.Get(fiber.HeaderIfNoneMatch) == etag {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+		// Получаем содержимое ассета
+		data, mimeType, err := h.services.Asset.Download(hash, thumbnail)
+		if err != nil {
+			return h.serviceError(c, err, "error downloading asset")
+		}
+		// Возвращаем файл; SVG открывается без выполнения скриптов
+		c.Set(fiber.HeaderContentType, mimeType)
+		c.Set(fiber.HeaderETag, etag)
+		c.Set(fiber.HeaderCacheControl, "public, max-age=31536000, immutable")
+		c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+		c.Set(fiber.HeaderContentSecurityPolicy, "default-src 'none'; style-src 'unsafe-inline'; img-src data:")
+		return c.Status(fiber.StatusOK).Send(data)
+	}
+}
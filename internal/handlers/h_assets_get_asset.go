@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) getAsset(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId и assetId из параметров Path
+	projectId := c.Params("project_id")
+	assetId := c.Params("asset_id")
+	h.log.Debug().Msgf("projectId: %v, assetId: %v", projectId, assetId)
+	// Получаем ассет
+	asset, err := h.services.Asset.Get(projectId, assetId, userId)
+	if err != nil {
+		return h.serviceError(c, err, "error getting asset")
+	}
+	// Возвращаем asset
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"asset": asset,
+		},
+	})
+}
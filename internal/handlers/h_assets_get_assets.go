@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) getAssets(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId из параметров Path и фильтр из query
+	projectId := c.Params("project_id")
+	unused := c.QueryBool("unused")
+	h.log.Debug().Msgf("projectId: %v, unused: %v", projectId, unused)
+	// Получаем ассеты проекта
+	assets, err := h.services.Asset.GetAll(projectId, userId, unused)
+	if err != nil {
+		return h.serviceError(c, err, "error getting assets")
+	}
+	// Возвращаем assets
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"assets": assets,
+		},
+	})
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) uploadAsset(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId из параметров Path
+	projectId := c.Params("project_id")
+	h.log.Debug().Msgf("projectId: %v", projectId)
+	// Получаем файл из multipart-формы
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "file is required",
+		})
+	}
+	if file.Size > entity.AssetMaxSize {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"message": "file is too large",
+		})
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid file",
+		})
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(io.LimitReader(reader, entity.AssetMaxSize+1))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid file",
+		})
+	}
+	upload := entity.AssetUpload{Name: c.FormValue("name", file.Filename), Data: data}
+	h.log.Debug().Msgf("name: %v, size: %v", upload.Name, len(upload.Data))
+	// Проверяем валидность данных
+	if err = upload.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Сохраняем ассет
+	asset, err := h.services.Asset.Upload(projectId, userId, upload)
+	if err != nil {
+		return h.serviceError(c, err, "error uploading asset")
+	}
+	// Возвращаем asset
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"asset": asset,
+		},
+	})
+}
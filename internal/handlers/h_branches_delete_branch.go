@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) deleteBranch(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId, screenId и branchId из параметров Path
+	projectId := c.Params("project_id")
+	screenId := c.Params("screen_id")
+	branchId := c.Params("branch_id")
+	h.log.Debug().Msgf("projectId: %v, screenId: %v, branchId: %v", projectId, screenId, branchId)
+	// Удаляем ветку
+	if err := h.services.Branch.DeleteById(projectId, screenId, branchId, userId); err != nil {
+		return h.serviceError(c, err, "error deleting branch")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+	})
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) mergeBranch(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId, screenId и целевую ветку из параметров Path
+	projectId := c.Params("project_id")
+	screenId := c.Params("screen_id")
+	branchId := c.Params("branch_id")
+	h.log.Debug().Msgf("projectId: %v, screenId: %v, branchId: %v", projectId, screenId, branchId)
+	// Парсим тело запроса
+	var request entity.MergeRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+	if err := request.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Сливаем ветки
+	result, err := h.services.Merge.Merge(projectId, screenId, branchId, userId, request)
+	if err != nil {
+		return h.serviceError(c, err, "error merging branches")
+	}
+	// Неразрешенные конфликты возвращаем клиенту для решения
+	if len(result.Conflicts) > 0 && !request.DryRun {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": "merge conflicts",
+			"details": fiber.Map{
+				"merge": result,
+			},
+		})
+	}
+	status := fiber.StatusOK
+	if result.Merged {
+		status = fiber.StatusCreated
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"merge": result,
+		},
+	})
+}
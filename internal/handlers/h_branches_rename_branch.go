@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) renameBranch(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId, screenId и branchId из параметров Path
+	projectId := c.Params("project_id")
+	screenId := c.Params("screen_id")
+	branchId := c.Params("branch_id")
+	h.log.Debug().Msgf("projectId: %v, screenId: %v, branchId: %v", projectId, screenId, branchId)
+	// Парсим тело запроса
+	var branch entity.ScreenBranch
+	if err := c.BodyParser(&branch); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+	if err := branch.ValidateName(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Переименовываем ветку
+	if err := h.services.Branch.Rename(projectId, screenId, branchId, userId, branch.Name); err != nil {
+		return h.serviceError(c, err, "error renaming branch")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+	})
+}
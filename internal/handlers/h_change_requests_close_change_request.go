@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) closeChangeRequest(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId, screenId и requestId из параметров Path
+	projectId := c.Params("project_id")
+	screenId := c.Params("screen_id")
+	requestId := c.Params("request_id")
+	h.log.Debug().Msgf("projectId: %v, screenId: %v, requestId: %v", projectId, screenId, requestId)
+	// Закрываем запрос без слияния
+	request, err := h.services.ChangeRequest.Close(projectId, screenId, requestId, userId)
+	if err != nil {
+		return h.serviceError(c, err, "error closing change request")
+	}
+	// Возвращаем change_request
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"change_request": request,
+		},
+	})
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) createChangeRequest(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId и screenId из параметров Path
+	projectId := c.Params("project_id")
+	screenId := c.Params("screen_id")
+	h.log.Debug().Msgf("projectId: %v, screenId: %v", projectId, screenId)
+	// Парсим тело запроса
+	var params entity.ChangeRequestCreate
+	if err := c.BodyParser(&params); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+	if err := params.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Открываем запрос на изменение
+	request, err := h.services.ChangeRequest.Create(projectId, screenId, userId, params)
+	if err != nil {
+		return h.serviceError(c, err, "error creating change request")
+	}
+	// Возвращаем change_request
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"change_request": request,
+		},
+	})
+}
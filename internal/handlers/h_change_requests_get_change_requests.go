@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) getChangeRequests(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId и screenId из параметров Path
+	projectId := c.Params("project_id")
+	screenId := c.Params("screen_id")
+	// Фильтр по статусу из query
+	status := c.Query("status")
+	h.log.Debug().Msgf("projectId: %v, screenId: %v, status: %v", projectId, screenId, status)
+	switch status {
+	case "", entity.ChangeRequestOpen, entity.ChangeRequestMerged, entity.ChangeRequestClosed:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "status must be open, merged or closed",
+		})
+	}
+	// Получаем запросы на изменение
+	requests, err := h.services.ChangeRequest.GetAll(projectId, screenId, userId, status)
+	if err != nil {
+		return h.serviceError(c, err, "error getting change requests")
+	}
+	// Возвращаем change_requests
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"change_requests": requests,
+		},
+	})
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// collabReadLimit - максимальный размер входящего сообщения
+	collabReadLimit = 1 << 20
+	// collabPingInterval - как часто проверяем, что клиент на связи
+	collabPingInterval = 30 * time.Second
+	collabWriteTimeout = 10 * time.Second
+)
+
+func (h *Handler) connectCollab(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Сессия доступна только по WebSocket
+	if !websocket.IsWebSocketUpgrade(c) {
+		return c.Status(fiber.StatusUpgradeRequired).JSON(fiber.Map{
+			"message": "websocket upgrade required",
+		})
+	}
+	// Получаем projectId, screenId и branchId из параметров Path
+	projectId := c.Params("project_id")
+	screenId := c.Params("screen_id")
+	branchId := c.Params("branch_id")
+	h.log.Debug().Msgf("projectId: %v, screenId: %v, branchId: %v", projectId, screenId, branchId)
+	// Проверяем доступ до установки соединения, чтобы вернуть обычный HTTP-ответ
+	canEdit, err := h.services.Collab.Authorize(projectId, screenId, branchId, userId)
+	if err != nil {
+		return h.serviceError(c, err, "error connecting to collaboration session")
+	}
+	c.Locals("collabCanEdit", canEdit)
+	return websocket.New(h.collabSession)(c)
+}
+
+// collabSession обслуживает соединение: читает сообщения клиента в сессию
+// и пишет ему сообщения сессии, пока одна из сторон не закроет соединение.
+func (h *Handler) collabSession(conn *websocket.Conn) {
+	userId := conn.Locals("UID").(string)
+	canEdit := conn.Locals("collabCanEdit").(bool)
+	client, err := h.services.Collab.Join(conn.Params("project_id"), conn.Params("branch_id"), userId, canEdit)
+	if err != nil {
+		h.log.Error().Err(err).Msg("error joining collaboration session")
+		_ = conn.WriteJSON(fiber.Map{"type": "error", "message": "error joining collaboration session"})
+		return
+	}
+
+	// Пишем сообщения сессии и пинги
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer conn.Close()
+		ticker := time.NewTicker(collabPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case message, ok := <-client.Messages():
+				if !ok {
+					return
+				}
+				_ = conn.SetWriteDeadline(time.Now().Add(collabWriteTimeout))
+				if err := conn.WriteMessage(websocket.TextMessage, message); err != nil {
+					return
+				}
+			case <-ticker.C:
+				_ = conn.SetWriteDeadline(time.Now().Add(collabWriteTimeout))
+				if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	// Читаем сообщения клиента; ответ на пинг продлевает ожидание
+	conn.SetReadLimit(collabReadLimit)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * collabPingInterval))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * collabPingInterval))
+	})
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(2 * collabPingInterval))
+		if messageType == websocket.TextMessage {
+			client.Handle(data)
+		}
+	}
+	client.Leave()
+	<-done
+}
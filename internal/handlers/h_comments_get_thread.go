@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) getCommentThread(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId, screenId и threadId из параметров Path
+	projectId := c.Params("project_id")
+	screenId := c.Params("screen_id")
+	threadId := c.Params("thread_id")
+	h.log.Debug().Msgf("projectId: %v, screenId: %v, threadId: %v", projectId, screenId, threadId)
+	// Версия ветки обсуждения для привязки к виджету, по умолчанию головная
+	version := c.QueryInt("version")
+	if version < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "version must be positive",
+		})
+	}
+	// Получаем обсуждение
+	thread, err := h.services.Comment.GetThread(projectId, screenId, threadId, userId, version)
+	if err != nil {
+		return h.serviceError(c, err, "error getting comment thread")
+	}
+	// Возвращаем thread
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"thread": thread,
+		},
+	})
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) getCommentThreads(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId и screenId из параметров Path
+	projectId := c.Params("project_id")
+	screenId := c.Params("screen_id")
+	// Фильтр обсуждений из query: ветка, версия для привязки и статус
+	filter := entity.CommentThreadFilter{
+		BranchId: c.Query("branch_id"),
+		Version:  c.QueryInt("version"),
+		Status:   c.Query("status"),
+	}
+	h.log.Debug().Msgf("projectId: %v, screenId: %v, filter: %+v", projectId, screenId, filter)
+	if err := filter.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Получаем обсуждения
+	threads, err := h.services.Comment.GetThreads(projectId, screenId, userId, filter)
+	if err != nil {
+		return h.serviceError(c, err, "error getting comment threads")
+	}
+	// Возвращаем threads
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"threads": threads,
+		},
+	})
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) replyCommentThread(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId, screenId и threadId из параметров Path
+	projectId := c.Params("project_id")
+	screenId := c.Params("screen_id")
+	threadId := c.Params("thread_id")
+	h.log.Debug().Msgf("projectId: %v, screenId: %v, threadId: %v", projectId, screenId, threadId)
+	// Парсим тело запроса
+	var params entity.CommentCreate
+	if err := c.BodyParser(&params); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+	if err := params.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Добавляем ответ
+	comment, err := h.services.Comment.Reply(projectId, screenId, threadId, userId, params)
+	if err != nil {
+		return h.serviceError(c, err, "error adding comment")
+	}
+	// Возвращаем comment
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"comment": comment,
+		},
+	})
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// setCommentThreadStatus возвращает обработчик закрытия или повторного открытия обсуждения.
+func (h *Handler) setCommentThreadStatus(status string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Получаем userId из контекста
+		userId := c.Locals("UID").(string)
+		h.log.Debug().Msgf("userId: %v", userId)
+		// Получаем projectId, screenId и threadId из параметров Path
+		projectId := c.Params("project_id")
+		screenId := c.Params("screen_id")
+		threadId := c.Params("thread_id")
+		h.log.Debug().Msgf("projectId: %v, screenId: %v, threadId: %v, status: %v", projectId, screenId, threadId, status)
+		// Меняем статус обсуждения
+		thread, err := h.services.Comment.SetStatus(projectId, screenId, threadId, userId, status)
+		if err != nil {
+			return h.serviceError(c, err, "error changing comment thread status")
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "ok",
+			"details": fiber.Map{
+				"thread": thread,
+			},
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) createComponent(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем проект или воркспейс из параметров Path
+	scope := componentScope(c)
+	h.log.Debug().Msgf("scope: %+v", scope)
+	// Парсим тело запроса
+	var params entity.ComponentCreate
+	if err := c.BodyParser(&params); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+	if err := params.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Создаем компонент с первой версией
+	component, err := h.services.Component.Create(scope, userId, params)
+	if err != nil {
+		return h.serviceError(c, err, "error creating component")
+	}
+	// Возвращаем component
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"component": component,
+		},
+	})
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) createComponentVersion(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем уровень и componentId из параметров Path
+	scope := componentScope(c)
+	componentId := c.Params("component_id")
+	h.log.Debug().Msgf("scope: %+v, componentId: %v", scope, componentId)
+	// Парсим тело запроса
+	var params entity.ComponentVersionCreate
+	if err := c.BodyParser(&params); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+	if err := params.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Сохраняем новую версию компонента
+	version, err := h.services.Component.CreateVersion(scope, componentId, userId, params)
+	if err != nil {
+		return h.serviceError(c, err, "error saving component version")
+	}
+	// Возвращаем version
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"version": version,
+		},
+	})
+}
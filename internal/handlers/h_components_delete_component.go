@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) deleteComponent(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем уровень и componentId из параметров Path
+	scope := componentScope(c)
+	componentId := c.Params("component_id")
+	h.log.Debug().Msgf("scope: %+v, componentId: %v", scope, componentId)
+	// Удаляем компонент
+	if err := h.services.Component.Delete(scope, componentId, userId); err != nil {
+		return h.serviceError(c, err, "error deleting component")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+	})
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) getComponent(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем уровень и componentId из параметров Path, версию из query (0 - последняя)
+	scope := componentScope(c)
+	componentId := c.Params("component_id")
+	version := c.QueryInt("version")
+	h.log.Debug().Msgf("scope: %+v, componentId: %v, version: %v", scope, componentId, version)
+	if version < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid version",
+		})
+	}
+	// Получаем компонент
+	component, err := h.services.Component.Get(scope, componentId, userId, version)
+	if err != nil {
+		return h.serviceError(c, err, "error getting component")
+	}
+	// Возвращаем component
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"component": component,
+		},
+	})
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) getComponentVersions(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем уровень и componentId из параметров Path
+	scope := componentScope(c)
+	componentId := c.Params("component_id")
+	h.log.Debug().Msgf("scope: %+v, componentId: %v", scope, componentId)
+	// Получаем историю версий
+	versions, err := h.services.Component.GetVersions(scope, componentId, userId)
+	if err != nil {
+		return h.serviceError(c, err, "error getting component versions")
+	}
+	// Возвращаем versions
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"versions": versions,
+		},
+	})
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) getComponents(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем проект или воркспейс из параметров Path
+	scope := componentScope(c)
+	h.log.Debug().Msgf("scope: %+v", scope)
+	// Получаем компоненты
+	components, err := h.services.Component.GetAll(scope, userId)
+	if err != nil {
+		return h.serviceError(c, err, "error getting components")
+	}
+	// Возвращаем components
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"components": components,
+		},
+	})
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) createDataSource(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Парсим тело запроса
+	var source entity.DataSource
+	if err := c.BodyParser(&source); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+	// Получаем projectId из параметров Path
+	source.ProjectId = c.Params("project_id")
+	h.log.Debug().Msgf("projectId: %v, name: %v, kind: %v", source.ProjectId, source.Name, source.Kind)
+	// Проверяем валидность данных
+	if err := source.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Создаем источник данных
+	source, err := h.services.DataSource.Create(source, userId)
+	if err != nil {
+		return h.serviceError(c, err, "error creating data source")
+	}
+	// Возвращаем data_source
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"data_source": source,
+		},
+	})
+}
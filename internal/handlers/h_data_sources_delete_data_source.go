@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) deleteDataSource(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId и имя источника из параметров Path
+	projectId := c.Params("project_id")
+	name := c.Params("name")
+	h.log.Debug().Msgf("projectId: %v, name: %v", projectId, name)
+	// Удаляем источник данных
+	if err := h.services.DataSource.Delete(projectId, name, userId); err != nil {
+		return h.serviceError(c, err, "error deleting data source")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+	})
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) getDataSource(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId и имя источника из параметров Path
+	projectId := c.Params("project_id")
+	name := c.Params("name")
+	h.log.Debug().Msgf("projectId: %v, name: %v", projectId, name)
+	// Получаем источник данных вместе с записанным ответом
+	source, err := h.services.DataSource.Get(projectId, name, userId)
+	if err != nil {
+		return h.serviceError(c, err, "error getting data source")
+	}
+	// Возвращаем data_source
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"data_source": source,
+		},
+	})
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) recordDataSource(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId и имя источника из параметров Path
+	projectId := c.Params("project_id")
+	name := c.Params("name")
+	h.log.Debug().Msgf("projectId: %v, name: %v", projectId, name)
+	// Выполняем запрос источника и сохраняем ответ
+	source, err := h.services.DataSource.Record(projectId, name, userId)
+	if err != nil {
+		return h.serviceError(c, err, "error recording data source response")
+	}
+	// Возвращаем data_source
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"data_source": source,
+		},
+	})
+}
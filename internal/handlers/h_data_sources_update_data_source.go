@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) updateDataSource(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Парсим тело запроса
+	var source entity.DataSource
+	if err := c.BodyParser(&source); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+	// Получаем projectId и имя источника из параметров Path
+	source.ProjectId = c.Params("project_id")
+	source.Name = c.Params("name")
+	h.log.Debug().Msgf("projectId: %v, name: %v, kind: %v", source.ProjectId, source.Name, source.Kind)
+	// Проверяем валидность данных
+	if err := source.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Обновляем источник данных
+	source, err := h.services.DataSource.Update(source, userId)
+	if err != nil {
+		return h.serviceError(c, err, "error updating data source")
+	}
+	// Возвращаем data_source
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"data_source": source,
+		},
+	})
+}
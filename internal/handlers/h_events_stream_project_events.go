@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) streamProjectEvents(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId из параметров Path
+	projectId := c.Params("project_id")
+	h.log.Debug().Msgf("projectId: %v", projectId)
+	// Открываем ленту событий проекта
+	subscription, err := h.services.Event.StreamProject(projectId, userId, lastEventId(c))
+	if err != nil {
+		return h.serviceError(c, err, "error opening event stream")
+	}
+	// Отдаем события
+	return h.streamEvents(c, subscription)
+}
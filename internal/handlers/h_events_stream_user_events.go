@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) streamUserEvents(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Открываем ленту событий пользователя
+	subscription, err := h.services.Event.StreamUser(userId, lastEventId(c))
+	if err != nil {
+		return h.serviceError(c, err, "error opening event stream")
+	}
+	// Отдаем события
+	return h.streamEvents(c, subscription)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) createLocale(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Парсим тело запроса
+	var locale entity.Locale
+	if err := c.BodyParser(&locale); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+	// Получаем projectId из параметров Path
+	locale.ProjectId = c.Params("project_id")
+	h.log.Debug().Msgf("projectId: %v, code: %v", locale.ProjectId, locale.Code)
+	// Проверяем валидность данных
+	if err := locale.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Добавляем локаль
+	locale, err := h.services.Localization.CreateLocale(locale, userId)
+	if err != nil {
+		return h.serviceError(c, err, "error creating locale")
+	}
+	// Возвращаем locale
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"locale": locale,
+		},
+	})
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) getLocales(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId из параметров Path
+	projectId := c.Params("project_id")
+	h.log.Debug().Msgf("projectId: %v", projectId)
+	// Получаем локали проекта
+	locales, err := h.services.Localization.GetLocales(projectId, userId)
+	if err != nil {
+		return h.serviceError(c, err, "error getting locales")
+	}
+	// Возвращаем locales
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"locales": locales,
+		},
+	})
+}
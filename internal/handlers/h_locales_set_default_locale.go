@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) setDefaultLocale(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId и код локали из параметров Path
+	projectId := c.Params("project_id")
+	code := c.Params("code")
+	h.log.Debug().Msgf("projectId: %v, code: %v", projectId, code)
+	// Делаем локаль локалью по умолчанию
+	if err := h.services.Localization.SetDefaultLocale(projectId, code, userId); err != nil {
+		return h.serviceError(c, err, "error setting default locale")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+	})
+}
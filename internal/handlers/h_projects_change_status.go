@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// changeProjectStatus возвращает обработчик перехода жизненного цикла проекта
+// (publish/unpublish/archive).
+func (h *Handler) changeProjectStatus(action string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Получаем userId из контекста
+		userId := c.Locals("UID").(string)
+		h.log.Debug().Msgf("userId: %v", userId)
+		// Получаем projectId из параметров Path
+		projectId := c.Params("project_id")
+		h.log.Debug().Msgf("projectId: %v, action: %v", projectId, action)
+		if projectId == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "project id is empty",
+			})
+		}
+		// Меняем статус проекта
+		project, err := h.services.Project.ChangeStatus(projectId, userId, action)
+		if err != nil {
+			return h.serviceError(c, err, "error changing project status")
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "ok",
+			"details": fiber.Map{
+				"project": project,
+			},
+		})
+	}
+}
@@ -27,10 +27,7 @@ func (h *Handler) createProject(c *fiber.Ctx) error {
 	// Создаем проект
 	projectId, err := h.services.Project.Create(project, userId)
 	if err != nil {
-		h.log.Error().Msgf("error creating project: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": "error creating project",
-		})
+		return h.serviceError(c, err, "error creating project")
 	}
 	// Возвращаем projectId
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -17,12 +17,9 @@ func (h *Handler) deleteProject(c *fiber.Ctx) error {
 		})
 	}
 	// Удаляем проект
-	err := h.services.Project.DeleteById(projectId)
+	err := h.services.Project.DeleteById(projectId, userId)
 	if err != nil {
-		h.log.Error().Msgf("error deleting project: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": "error deleting project",
-		})
+		return h.serviceError(c, err, "error deleting project")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
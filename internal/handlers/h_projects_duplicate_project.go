@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) duplicateProject(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId из параметров Path
+	projectId := c.Params("project_id")
+	h.log.Debug().Msgf("projectId: %v", projectId)
+	if projectId == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "project id is empty",
+		})
+	}
+	// Парсим тело запроса, оно необязательно
+	var params entity.ProjectCopy
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "invalid request body",
+			})
+		}
+	}
+	if err := params.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Копируем проект
+	newProjectId, err := h.services.Project.Duplicate(projectId, userId, params.Name)
+	if err != nil {
+		return h.serviceError(c, err, "error duplicating project")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"project_id": newProjectId,
+		},
+	})
+}
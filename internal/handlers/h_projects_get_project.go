@@ -1,26 +1,49 @@
 package handlers
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/internal/storages"
 )
 
 func (h *Handler) getProjects(c *fiber.Ctx) error {
 	// Получаем userId из контекста
 	userId := c.Locals("UID").(string)
 	h.log.Debug().Msgf("userId: %v", userId)
-	// Создаем проект
-	projects, err := h.services.Project.GetAllByUserId(userId)
+	// Парсим параметры выборки
+	var filter entity.ProjectFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid query parameters",
+		})
+	}
+	if err := filter.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Получаем проекты
+	list, err := h.services.Project.GetAllByUserId(userId, filter)
 	if err != nil {
-		h.log.Error().Msgf("error creating project: %v", err)
+		if errors.Is(err, storages.ErrInvalidCursor) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "invalid cursor",
+			})
+		}
+		h.log.Error().Msgf("error getting projects: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": "error creating project",
+			"message": "error getting projects",
 		})
 	}
 	// Возвращаем projects
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "ok",
 		"details": fiber.Map{
-			"projects": projects,
+			"projects":    list.Projects,
+			"next_cursor": list.NextCursor,
+			"total":       list.Total,
 		},
 	})
 }
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) getProjectTemplates(c *fiber.Ctx) error {
+	// Получаем каталог шаблонов
+	templates, err := h.services.Project.GetTemplates()
+	if err != nil {
+		return h.serviceError(c, err, "error getting templates")
+	}
+	// Возвращаем templates
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"templates": templates,
+		},
+	})
+}
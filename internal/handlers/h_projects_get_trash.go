@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) getProjectsTrash(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем удаленные проекты
+	projects, err := h.services.Project.GetTrash(userId)
+	if err != nil {
+		return h.serviceError(c, err, "error getting deleted projects")
+	}
+	// Возвращаем projects
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"projects": projects,
+		},
+	})
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) instantiateTemplate(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем templateId из параметров Path
+	templateId := c.Params("template_id")
+	h.log.Debug().Msgf("templateId: %v", templateId)
+	if templateId == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "template id is empty",
+		})
+	}
+	// Парсим тело запроса, оно необязательно
+	var params entity.ProjectCopy
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&params); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "invalid request body",
+			})
+		}
+	}
+	if err := params.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Создаем проект из шаблона
+	projectId, err := h.services.Project.Instantiate(templateId, userId, params.Name)
+	if err != nil {
+		return h.serviceError(c, err, "error creating project from template")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"project_id": projectId,
+		},
+	})
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) moveProjectToWorkspace(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId из параметров Path
+	projectId := c.Params("project_id")
+	h.log.Debug().Msgf("projectId: %v", projectId)
+	// Парсим тело запроса
+	var body struct {
+		WorkspaceId string `json:"workspace_id"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+	if body.WorkspaceId == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "workspace_id is required",
+		})
+	}
+	// Передаем проект воркспейсу
+	if err := h.services.Project.MoveToWorkspace(projectId, userId, body.WorkspaceId); err != nil {
+		return h.serviceError(c, err, "error moving project to workspace")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+	})
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) setProjectTemplate(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId из параметров Path
+	projectId := c.Params("project_id")
+	h.log.Debug().Msgf("projectId: %v", projectId)
+	if projectId == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "project id is empty",
+		})
+	}
+	// Парсим тело запроса
+	var body struct {
+		IsTemplate bool `json:"is_template"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+	// Помечаем проект как шаблон или снимаем отметку
+	err := h.services.Project.SetTemplate(projectId, userId, body.IsTemplate)
+	if err != nil {
+		return h.serviceError(c, err, "error updating project template")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+	})
+}
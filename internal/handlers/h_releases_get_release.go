@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) getRelease(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId, screenId и номер релиза из параметров Path ("live" - живой релиз)
+	projectId := c.Params("project_id")
+	screenId := c.Params("screen_id")
+	releaseNumber := 0
+	if c.Params("release") != "live" {
+		number, err := c.ParamsInt("release")
+		if err != nil || number <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "invalid release number",
+			})
+		}
+		releaseNumber = number
+	}
+	h.log.Debug().Msgf("projectId: %v, screenId: %v, release: %v", projectId, screenId, releaseNumber)
+	// Получаем релиз
+	release, err := h.services.Release.Get(projectId, screenId, userId, releaseNumber)
+	if err != nil {
+		return h.serviceError(c, err, "error getting release")
+	}
+	// Возвращаем release
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"release": release,
+		},
+	})
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) getReleases(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId и screenId из параметров Path
+	projectId := c.Params("project_id")
+	screenId := c.Params("screen_id")
+	h.log.Debug().Msgf("projectId: %v, screenId: %v", projectId, screenId)
+	// Получаем релизы
+	releases, err := h.services.Release.GetAll(projectId, screenId, userId)
+	if err != nil {
+		return h.serviceError(c, err, "error getting releases")
+	}
+	// Возвращаем releases
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"releases": releases,
+		},
+	})
+}
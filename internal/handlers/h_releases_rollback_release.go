@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) rollbackRelease(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId, screenId и номер релиза из параметров Path
+	projectId := c.Params("project_id")
+	screenId := c.Params("screen_id")
+	releaseNumber, err := c.ParamsInt("release")
+	if err != nil || releaseNumber <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid release number",
+		})
+	}
+	h.log.Debug().Msgf("projectId: %v, screenId: %v, release: %v", projectId, screenId, releaseNumber)
+	// Делаем релиз живым
+	release, err := h.services.Release.Rollback(projectId, screenId, userId, releaseNumber)
+	if err != nil {
+		return h.serviceError(c, err, "error rolling back release")
+	}
+	// Возвращаем release
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"release": release,
+		},
+	})
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) createRoute(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Парсим тело запроса
+	var route entity.Route
+	if err := c.BodyParser(&route); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+	// Получаем projectId из параметров Path
+	route.ProjectId = c.Params("project_id")
+	route.Id = ""
+	h.log.Debug().Msgf("projectId: %v, path: %v", route.ProjectId, route.Path)
+	// Проверяем валидность данных
+	if err := route.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Создаем маршрут
+	route, err := h.services.Route.Create(route, userId)
+	if err != nil {
+		return h.serviceError(c, err, "error creating route")
+	}
+	// Возвращаем route
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"route": route,
+		},
+	})
+}
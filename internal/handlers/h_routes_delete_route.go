@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) deleteRoute(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId и routeId из параметров Path
+	projectId := c.Params("project_id")
+	routeId := c.Params("route_id")
+	h.log.Debug().Msgf("projectId: %v, routeId: %v", projectId, routeId)
+	// Удаляем маршрут
+	if err := h.services.Route.Delete(projectId, routeId, userId); err != nil {
+		return h.serviceError(c, err, "error deleting route")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+	})
+}
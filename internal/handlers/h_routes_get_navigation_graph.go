@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) getNavigationGraph(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId из параметров Path
+	projectId := c.Params("project_id")
+	h.log.Debug().Msgf("projectId: %v", projectId)
+	// Строим граф переходов
+	graph, err := h.services.Route.GetNavigationGraph(projectId, userId)
+	if err != nil {
+		return h.serviceError(c, err, "error building navigation graph")
+	}
+	// Возвращаем graph
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"graph": graph,
+		},
+	})
+}
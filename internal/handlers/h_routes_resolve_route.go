@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) resolveRoute(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId из параметров Path и deep link из query
+	projectId := c.Params("project_id")
+	link := c.Query("path")
+	h.log.Debug().Msgf("projectId: %v, path: %v", projectId, link)
+	if link == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "path is required",
+		})
+	}
+	// Находим экран по маршрутам проекта
+	resolution, err := h.services.Route.Resolve(projectId, userId, link)
+	if err != nil {
+		return h.serviceError(c, err, "error resolving route")
+	}
+	// Возвращаем resolution
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"resolution": resolution,
+		},
+	})
+}
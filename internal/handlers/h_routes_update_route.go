@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) updateRoute(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Парсим тело запроса
+	var route entity.Route
+	if err := c.BodyParser(&route); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+	// Получаем projectId и routeId из параметров Path
+	route.ProjectId = c.Params("project_id")
+	route.Id = c.Params("route_id")
+	h.log.Debug().Msgf("projectId: %v, routeId: %v, path: %v", route.ProjectId, route.Id, route.Path)
+	// Проверяем валидность данных
+	if err := route.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Обновляем маршрут
+	route, err := h.services.Route.Update(route, userId)
+	if err != nil {
+		return h.serviceError(c, err, "error updating route")
+	}
+	// Возвращаем route
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"route": route,
+		},
+	})
+}
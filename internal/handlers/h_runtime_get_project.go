@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// runtimeMaxAge - сколько клиенты и прокси могут не перепроверять ответы runtime.
+const runtimeMaxAge = time.Minute
+
+func (h *Handler) getRuntimeProject(c *fiber.Ctx) error {
+	// Получаем projectId из параметров Path
+	projectId := c.Params("project_id")
+	h.log.Debug().Msgf("projectId: %v", projectId)
+	// Получаем снимок опубликованного проекта на запрошенном языке
+	project, err := h.services.Runtime.GetProject(projectId, requestedLocales(c))
+	if err != nil {
+		return h.serviceError(c, err, "error getting published project")
+	}
+	// Возвращаем project с поддержкой условных запросов
+	return sendCacheable(c, fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"project": project,
+		},
+	}, project.UpdatedAt, runtimeMaxAge)
+}
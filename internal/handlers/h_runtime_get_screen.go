@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) getRuntimeScreen(c *fiber.Ctx) error {
+	// Получаем projectId и screenId из параметров Path
+	projectId := c.Params("project_id")
+	screenId := c.Params("screen_id")
+	h.log.Debug().Msgf("projectId: %v, screenId: %v", projectId, screenId)
+	// Получаем живой релиз экрана на запрошенном языке
+	screen, locale, updatedAt, err := h.services.Runtime.GetScreen(projectId, screenId, requestedLocales(c))
+	if err != nil {
+		return h.serviceError(c, err, "error getting published screen")
+	}
+	// Возвращаем screen с поддержкой условных запросов
+	return sendCacheable(c, fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"screen": screen,
+			"locale": locale,
+		},
+	}, updatedAt, runtimeMaxAge)
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) resolveRuntimeLink(c *fiber.Ctx) error {
+	// Получаем projectId из параметров Path и deep link из query
+	projectId := c.Params("project_id")
+	link := c.Query("path")
+	h.log.Debug().Msgf("projectId: %v, path: %v", projectId, link)
+	if link == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "path is required",
+		})
+	}
+	// Находим экран по маршрутам опубликованного проекта
+	resolution, updatedAt, err := h.services.Runtime.ResolveLink(projectId, link)
+	if err != nil {
+		return h.serviceError(c, err, "error resolving published route")
+	}
+	// Возвращаем resolution с поддержкой условных запросов
+	return sendCacheable(c, fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"resolution": resolution,
+		},
+	}, updatedAt, runtimeMaxAge)
+}
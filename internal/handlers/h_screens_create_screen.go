@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) createScreen(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Парсим тело запроса
+	var screen entity.Screen
+	if err := c.BodyParser(&screen); err != nil {
+		h.log.Error().Msgf("invalid request body: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+	// Получаем projectId из параметров Path
+	screen.ProjectId = c.Params("project_id")
+	h.log.Debug().Msgf("projectId: %v", screen.ProjectId)
+	// Проверяем валидность данных
+	if err := screen.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Создаем экран
+	screenId, err := h.services.Screen.Create(&screen, userId)
+	if err != nil {
+		return h.serviceError(c, err, "error creating screen")
+	}
+	// Возвращаем screenId
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"screen_id": screenId,
+		},
+	})
+}
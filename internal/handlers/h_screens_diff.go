@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) diffScreen(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId и screenId из параметров Path
+	projectId := c.Params("project_id")
+	screenId := c.Params("screen_id")
+	h.log.Debug().Msgf("projectId: %v, screenId: %v", projectId, screenId)
+	// Получаем сравниваемые версии из query: пустая ветка - Main, нулевая версия - головная
+	from := entity.VersionRef{BranchId: c.Query("from_branch"), Version: c.QueryInt("from_version")}
+	to := entity.VersionRef{BranchId: c.Query("to_branch"), Version: c.QueryInt("to_version")}
+	if from.Version < 0 || to.Version < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid version",
+		})
+	}
+	h.log.Debug().Msgf("from: %+v, to: %+v", from, to)
+	// Сравниваем версии
+	diff, err := h.services.Diff.Diff(projectId, screenId, userId, from, to)
+	if err != nil {
+		return h.serviceError(c, err, "error comparing versions")
+	}
+	// Возвращаем diff
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"diff": diff,
+		},
+	})
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) getScreen(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId и screenId из параметров Path
+	projectId := c.Params("project_id")
+	screenId := c.Params("screen_id")
+	h.log.Debug().Msgf("projectId: %v, screenId: %v", projectId, screenId)
+	// Получаем экран
+	screen, err := h.services.Screen.GetById(projectId, screenId, userId)
+	if err != nil {
+		return h.serviceError(c, err, "error getting screen")
+	}
+	// По запросу разворачиваем экземпляры компонентов. Развернутый документ зависит
+	// еще и от версий компонентов, поэтому ETag версии экрана для него не отдается
+	if c.QueryBool("resolve") {
+		if screen.Widgets, err = h.services.Component.Resolve(projectId, userId, screen.Widgets); err != nil {
+			return h.serviceError(c, err, "error resolving components")
+		}
+	} else {
+		c.Set(fiber.HeaderETag, versionETag(screen.Version))
+	}
+	// Возвращаем screen
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"screen": screen,
+		},
+	})
+}
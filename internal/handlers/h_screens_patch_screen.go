@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+const (
+	contentTypeJSONPatch  = "application/json-patch+json"
+	contentTypeMergePatch = "application/merge-patch+json"
+)
+
+// patchScreen применяет JSON Patch или JSON Merge Patch к головной версии ветки.
+// Без branch_id в пути патч применяется к ветке Main.
+func (h *Handler) patchScreen(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId, screenId и branchId из параметров Path
+	projectId := c.Params("project_id")
+	screenId := c.Params("screen_id")
+	branchId := c.Params("branch_id")
+	h.log.Debug().Msgf("projectId: %v, screenId: %v, branchId: %v", projectId, screenId, branchId)
+	// Парсим тело запроса по Content-Type
+	patch := entity.ScreenPatch{Message: c.Query("message")}
+	contentType := strings.ToLower(strings.TrimSpace(strings.Split(c.Get(fiber.HeaderContentType), ";")[0]))
+	switch contentType {
+	case contentTypeJSONPatch:
+		if err := json.Unmarshal(c.Body(), &patch.Operations); err != nil || patch.Operations == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "invalid json patch",
+			})
+		}
+	case contentTypeMergePatch:
+		if err := json.Unmarshal(c.Body(), &patch.MergePatch); err != nil || patch.MergePatch == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "invalid merge patch",
+			})
+		}
+	default:
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{
+			"message": "content type must be " + contentTypeJSONPatch + " or " + contentTypeMergePatch,
+		})
+	}
+	// Базовая версия из If-Match
+	base, err := baseVersion(c, 0)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	patch.BaseVersion = base
+	if err = patch.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Применяем патч
+	version, err := h.services.Version.Patch(projectId, screenId, branchId, userId, patch)
+	if err != nil {
+		return h.serviceError(c, err, "error patching screen")
+	}
+	// Возвращаем version
+	c.Set(fiber.HeaderETag, versionETag(version.Version))
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"version": version,
+		},
+	})
+}
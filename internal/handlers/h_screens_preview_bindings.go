@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) previewBindings(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId и screenId из параметров Path
+	projectId := c.Params("project_id")
+	screenId := c.Params("screen_id")
+	h.log.Debug().Msgf("projectId: %v, screenId: %v", projectId, screenId)
+	// Парсим тело запроса; пустое тело - предпросмотр головной версии Main
+	var request entity.BindingPreviewRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "invalid request body",
+			})
+		}
+	}
+	// Подставляем данные источников в документ экрана
+	preview, err := h.services.DataSource.Preview(projectId, screenId, userId, request)
+	if err != nil {
+		return h.serviceError(c, err, "error previewing bindings")
+	}
+	// Возвращаем preview
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"preview": preview,
+		},
+	})
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) updateScreen(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Парсим тело запроса
+	var screen entity.Screen
+	if err := c.BodyParser(&screen); err != nil {
+		h.log.Error().Msgf("invalid request body: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+	// Получаем projectId и screenId из параметров Path
+	screen.ProjectId = c.Params("project_id")
+	screen.Id = c.Params("screen_id")
+	h.log.Debug().Msgf("projectId: %v, screenId: %v", screen.ProjectId, screen.Id)
+	// Проверяем валидность данных
+	if err := screen.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Базовая версия Main из If-Match или из тела запроса
+	base, err := baseVersion(c, screen.BaseVersion)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	screen.BaseVersion = base
+	// Обновляем экран
+	if err := h.services.Screen.UpdateById(&screen, userId); err != nil {
+		return h.serviceError(c, err, "error updating screen")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+	})
+}
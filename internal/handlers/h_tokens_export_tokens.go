@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) exportTokens(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId из параметров Path и формат из query
+	projectId := c.Params("project_id")
+	format := c.Query("format", entity.TokensExportCSS)
+	h.log.Debug().Msgf("projectId: %v, format: %v", projectId, format)
+	// Выгружаем токены в файл для фронтенда
+	document, err := h.services.DesignToken.Export(projectId, userId, format)
+	if err != nil {
+		return h.serviceError(c, err, "error exporting design tokens")
+	}
+	// Возвращаем файл токенов
+	if format == entity.TokensExportCSS {
+		c.Set(fiber.HeaderContentType, "text/css; charset=utf-8")
+	} else {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	}
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="tokens.`+format+`"`)
+	return c.Status(fiber.StatusOK).Send(document)
+}
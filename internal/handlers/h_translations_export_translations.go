@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) exportTranslations(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId из параметров Path, формат и локаль из query
+	projectId := c.Params("project_id")
+	format := c.Query("format", entity.TranslationsFormatJSON)
+	locale := c.Query("locale")
+	h.log.Debug().Msgf("projectId: %v, format: %v, locale: %v", projectId, format, locale)
+	// Выгружаем переводы локали
+	document, err := h.services.Localization.Export(projectId, userId, format, locale)
+	if err != nil {
+		return h.serviceError(c, err, "error exporting translations")
+	}
+	// Возвращаем файл переводов
+	extension := "json"
+	if format == entity.TranslationsFormatXLIFF {
+		c.Set(fiber.HeaderContentType, "application/x-xliff+xml; charset=utf-8")
+		extension = "xlf"
+	} else {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	}
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+entity.NormalizeLocale(locale)+`.`+extension+`"`)
+	return c.Status(fiber.StatusOK).Send(document)
+}
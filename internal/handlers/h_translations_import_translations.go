@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) importTranslations(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId из параметров Path, формат и локаль из query
+	projectId := c.Params("project_id")
+	format := c.Query("format", entity.TranslationsFormatJSON)
+	locale := c.Query("locale")
+	h.log.Debug().Msgf("projectId: %v, format: %v, locale: %v", projectId, format, locale)
+	// Загружаем переводы из тела запроса
+	imported, err := h.services.Localization.Import(projectId, userId, format, locale, c.Body())
+	if err != nil {
+		return h.serviceError(c, err, "error importing translations")
+	}
+	// Возвращаем число загруженных переводов
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"imported": imported,
+		},
+	})
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) getVersionHistory(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId, screenId и branchId из параметров Path
+	projectId := c.Params("project_id")
+	screenId := c.Params("screen_id")
+	branchId := c.Params("branch_id")
+	h.log.Debug().Msgf("projectId: %v, screenId: %v, branchId: %v", projectId, screenId, branchId)
+	// Получаем историю версий
+	versions, err := h.services.Version.GetHistory(projectId, screenId, branchId, userId, c.QueryInt("limit"), c.QueryInt("before"))
+	if err != nil {
+		return h.serviceError(c, err, "error getting version history")
+	}
+	// Возвращаем versions
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"versions": versions,
+		},
+	})
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) getVersion(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId, screenId, branchId и номер версии из параметров Path
+	projectId := c.Params("project_id")
+	screenId := c.Params("screen_id")
+	branchId := c.Params("branch_id")
+	version, err := c.ParamsInt("version")
+	if err != nil || version <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid version",
+		})
+	}
+	h.log.Debug().Msgf("projectId: %v, screenId: %v, branchId: %v, version: %v", projectId, screenId, branchId, version)
+	// Получаем версию
+	screenVersion, err := h.services.Version.Get(projectId, screenId, branchId, userId, version)
+	if err != nil {
+		return h.serviceError(c, err, "error getting version")
+	}
+	// По запросу разворачиваем экземпляры компонентов
+	if c.QueryBool("resolve") {
+		if screenVersion.Widgets, err = h.services.Component.Resolve(projectId, userId, screenVersion.Widgets); err != nil {
+			return h.serviceError(c, err, "error resolving components")
+		}
+	} else {
+		c.Set(fiber.HeaderETag, versionETag(screenVersion.Version))
+	}
+	// Возвращаем version
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"version": screenVersion,
+		},
+	})
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) restoreVersion(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId, screenId, branchId и номер версии из параметров Path
+	projectId := c.Params("project_id")
+	screenId := c.Params("screen_id")
+	branchId := c.Params("branch_id")
+	version, err := c.ParamsInt("version")
+	if err != nil || version <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid version",
+		})
+	}
+	h.log.Debug().Msgf("projectId: %v, screenId: %v, branchId: %v, version: %v", projectId, screenId, branchId, version)
+	// Парсим тело запроса, оно необязательно
+	var body struct {
+		Message string `json:"message"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "invalid request body",
+			})
+		}
+	}
+	if len(body.Message) > 500 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "message must be less than 500 characters",
+		})
+	}
+	// Восстанавливаем версию как новую головную
+	screenVersion, err := h.services.Version.Restore(projectId, screenId, branchId, userId, version, body.Message)
+	if err != nil {
+		return h.serviceError(c, err, "error restoring version")
+	}
+	// Возвращаем version
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"version": screenVersion,
+		},
+	})
+}
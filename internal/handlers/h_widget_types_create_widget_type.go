@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) createWidgetType(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Парсим тело запроса
+	var widgetType entity.WidgetType
+	if err := c.BodyParser(&widgetType); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+	// Получаем projectId из параметров Path
+	widgetType.ProjectId = c.Params("project_id")
+	widgetType.Builtin = false
+	h.log.Debug().Msgf("projectId: %v, name: %v", widgetType.ProjectId, widgetType.Name)
+	// Проверяем валидность данных
+	if err := widgetType.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Создаем тип виджета
+	if err := h.services.WidgetType.Create(widgetType, userId); err != nil {
+		return h.serviceError(c, err, "error creating widget type")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"name": widgetType.Name,
+		},
+	})
+}
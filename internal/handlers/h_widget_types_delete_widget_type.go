@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) deleteWidgetType(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем projectId и имя типа из параметров Path
+	projectId := c.Params("project_id")
+	name := c.Params("name")
+	h.log.Debug().Msgf("projectId: %v, name: %v", projectId, name)
+	// Удаляем тип виджета
+	if err := h.services.WidgetType.Delete(projectId, name, userId); err != nil {
+		return h.serviceError(c, err, "error deleting widget type")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+	})
+}
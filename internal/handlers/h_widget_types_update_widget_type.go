@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) updateWidgetType(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Парсим тело запроса
+	var widgetType entity.WidgetType
+	if err := c.BodyParser(&widgetType); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+	// Получаем projectId и имя типа из параметров Path
+	widgetType.ProjectId = c.Params("project_id")
+	widgetType.Name = c.Params("name")
+	h.log.Debug().Msgf("projectId: %v, name: %v", widgetType.ProjectId, widgetType.Name)
+	// Проверяем валидность данных
+	if err := widgetType.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Обновляем тип виджета
+	if err := h.services.WidgetType.Update(widgetType, userId); err != nil {
+		return h.serviceError(c, err, "error updating widget type")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+	})
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) createWorkspace(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Парсим тело запроса
+	var workspace entity.Workspace
+	if err := c.BodyParser(&workspace); err != nil {
+		h.log.Error().Msgf("invalid request body: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+	// Проверяем валидность данных
+	if err := workspace.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Создаем воркспейс
+	workspaceId, err := h.services.Workspace.Create(workspace, userId)
+	if err != nil {
+		return h.serviceError(c, err, "error creating workspace")
+	}
+	// Возвращаем workspaceId
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"workspace_id": workspaceId,
+		},
+	})
+}
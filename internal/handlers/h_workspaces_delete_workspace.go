@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) deleteWorkspace(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем workspaceId из параметров Path
+	workspaceId := c.Params("workspace_id")
+	h.log.Debug().Msgf("workspaceId: %v", workspaceId)
+	// Удаляем воркспейс
+	if err := h.services.Workspace.DeleteById(workspaceId, userId); err != nil {
+		return h.serviceError(c, err, "error deleting workspace")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+	})
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) getWorkspaceMembers(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем workspaceId из параметров Path
+	workspaceId := c.Params("workspace_id")
+	h.log.Debug().Msgf("workspaceId: %v", workspaceId)
+	// Получаем участников
+	members, err := h.services.Workspace.GetMembers(workspaceId, userId)
+	if err != nil {
+		return h.serviceError(c, err, "error getting workspace members")
+	}
+	// Возвращаем members
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"members": members,
+		},
+	})
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/internal/storages"
+)
+
+func (h *Handler) getWorkspaceProjects(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем workspaceId из параметров Path
+	workspaceId := c.Params("workspace_id")
+	h.log.Debug().Msgf("workspaceId: %v", workspaceId)
+	// Парсим параметры выборки
+	var filter entity.ProjectFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid query parameters",
+		})
+	}
+	if err := filter.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Получаем проекты воркспейса
+	list, err := h.services.Workspace.GetProjects(workspaceId, userId, filter)
+	if err != nil {
+		if errors.Is(err, storages.ErrInvalidCursor) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "invalid cursor",
+			})
+		}
+		return h.serviceError(c, err, "error getting workspace projects")
+	}
+	// Возвращаем projects
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"projects":    list.Projects,
+			"next_cursor": list.NextCursor,
+			"total":       list.Total,
+		},
+	})
+}
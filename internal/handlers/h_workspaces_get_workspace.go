@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) getWorkspace(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем workspaceId из параметров Path
+	workspaceId := c.Params("workspace_id")
+	h.log.Debug().Msgf("workspaceId: %v", workspaceId)
+	// Получаем воркспейс
+	workspace, err := h.services.Workspace.GetById(workspaceId, userId)
+	if err != nil {
+		return h.serviceError(c, err, "error getting workspace")
+	}
+	// Возвращаем workspace
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"workspace": workspace,
+		},
+	})
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) getWorkspaces(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем воркспейсы пользователя
+	workspaces, err := h.services.Workspace.GetAllByUserId(userId)
+	if err != nil {
+		return h.serviceError(c, err, "error getting workspaces")
+	}
+	// Возвращаем workspaces
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+		"details": fiber.Map{
+			"workspaces": workspaces,
+		},
+	})
+}
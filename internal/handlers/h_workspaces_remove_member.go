@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) removeWorkspaceMember(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем workspaceId и userId участника из параметров Path
+	workspaceId := c.Params("workspace_id")
+	memberId := c.Params("user_id")
+	h.log.Debug().Msgf("workspaceId: %v, memberId: %v", workspaceId, memberId)
+	// Исключаем участника
+	if err := h.services.Workspace.RemoveMember(workspaceId, userId, memberId); err != nil {
+		return h.serviceError(c, err, "error removing workspace member")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+	})
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) setWorkspaceMember(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Получаем workspaceId из параметров Path
+	workspaceId := c.Params("workspace_id")
+	h.log.Debug().Msgf("workspaceId: %v", workspaceId)
+	// Парсим тело запроса
+	var member entity.WorkspaceMember
+	if err := c.BodyParser(&member); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+	if err := member.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Добавляем участника или меняем его роль
+	if err := h.services.Workspace.SetMember(workspaceId, userId, member); err != nil {
+		return h.serviceError(c, err, "error setting workspace member")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+	})
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/entity"
+)
+
+func (h *Handler) updateWorkspace(c *fiber.Ctx) error {
+	// Получаем userId из контекста
+	userId := c.Locals("UID").(string)
+	h.log.Debug().Msgf("userId: %v", userId)
+	// Парсим тело запроса
+	var workspace entity.Workspace
+	if err := c.BodyParser(&workspace); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+	// Получаем workspaceId из параметров Path
+	workspace.ID = c.Params("workspace_id")
+	h.log.Debug().Msgf("workspaceId: %v", workspace.ID)
+	// Проверяем валидность данных
+	if err := workspace.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	// Обновляем воркспейс
+	if err := h.services.Workspace.UpdateById(workspace, userId); err != nil {
+		return h.serviceError(c, err, "error updating workspace")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ok",
+	})
+}
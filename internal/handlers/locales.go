@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// requestedLocales возвращает локали, запрошенные клиентом runtime: параметр ?locale=
+// или, без него, Accept-Language в порядке убывания веса.
+func requestedLocales(c *fiber.Ctx) []string {
+	if locale := c.Query("locale"); locale != "" {
+		return []string{locale}
+	}
+	c.Vary(fiber.HeaderAcceptLanguage)
+
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	var locales []weighted
+	for _, part := range strings.Split(c.Get(fiber.HeaderAcceptLanguage), ",") {
+		locale, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if locale == "" || locale == "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			locales = append(locales, weighted{locale: locale, q: q})
+		}
+	}
+	sort.SliceStable(locales, func(i, j int) bool { return locales[i].q > locales[j].q })
+
+	result := make([]string, len(locales))
+	for i, locale := range locales {
+		result[i] = locale.locale
+	}
+	return result
+}
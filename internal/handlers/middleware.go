@@ -1,14 +1,25 @@
 package handlers
 
 import (
+	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
+	"regexp"
 )
 
+// queryTokenPaths - ленты событий и совместное редактирование: браузер не передает заголовки
+// при открытии EventSource и WebSocket, поэтому только для них токен принимается из query
+var queryTokenPaths = regexp.MustCompile(`(?i)^/api/v1/projects/(events|[^/]+/events|[^/]+/screens/[^/]+/branches/[^/]+/collab)/?$`)
+
 func (h *Handler) middlewareAuth(c *fiber.Ctx) error {
 	// Получаем accessToken из заголовка
 	accessToken := c.Get("Authorization")
+	// Для лент событий и совместного редактирования токен может прийти в query (см. queryTokenPaths)
+	if accessToken == "" && c.Method() == fiber.MethodGet && queryTokenPaths.MatchString(c.Path()) &&
+		(websocket.IsWebSocketUpgrade(c) || c.Get(fiber.HeaderAccept) == "text/event-stream") {
+		accessToken = "Bearer " + c.Query("access_token")
+	}
 	// Проверяем accessToken
-	if accessToken == "" {
+	if accessToken == "" || accessToken == "Bearer " {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"message": "access token is empty",
 		})
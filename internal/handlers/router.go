@@ -2,10 +2,14 @@ package handlers
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/compress"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+	"regexp"
 	"time"
+	"ui-platform-backend-service/internal/entity"
 	"ui-platform-backend-service/internal/services"
 	"ui-platform-backend-service/pkg/jwt"
 )
@@ -24,11 +28,21 @@ func NewHandler(log zerolog.Logger, services *services.Service, jwtService *jwt.
 	}
 }
 
+// assetUploadPath - путь загрузки ассета (POST /api/v1/projects/:project_id/assets)
+var assetUploadPath = regexp.MustCompile(`(?i)^/api/v1/projects/[^/?]+/assets/?(\?|$)`)
+
 func (h *Handler) InitRoutes(port string) {
 	app := fiber.New(fiber.Config{
 		DisableDefaultContentType: true,
 		CaseSensitive:             false,
 	})
+	// Для загрузки ассетов лимит тела больше стандартного: файл и поля multipart-формы
+	app.Server().HeaderReceived = func(header *fasthttp.RequestHeader) fasthttp.RequestConfig {
+		if header.IsPost() && assetUploadPath.Match(header.RequestURI()) {
+			return fasthttp.RequestConfig{MaxRequestBodySize: entity.AssetMaxSize + 1<<20}
+		}
+		return fasthttp.RequestConfig{}
+	}
 
 	app.Use(cors.New())
 
@@ -70,9 +84,169 @@ func (h *Handler) InitRoutes(port string) {
 
 			projects.Post("/", h.createProject)
 			projects.Get("/", h.getProjects)
+			projects.Get("/trash", h.getProjectsTrash)
+			projects.Post("/trash/:project_id/restore", h.restoreProject)
+			projects.Delete("/trash/:project_id", h.purgeProject)
+			projects.Get("/templates", h.getProjectTemplates)
+			projects.Get("/events", h.streamUserEvents)
+			projects.Post("/templates/:template_id/instantiate", h.instantiateTemplate)
 			//projects.Get("/:id", nil)
 			//projects.Put("/:id", nil)
 			projects.Delete("/:project_id", h.deleteProject)
+			projects.Post("/:project_id/publish", h.changeProjectStatus(entity.ProjectActionPublish))
+			projects.Post("/:project_id/unpublish", h.changeProjectStatus(entity.ProjectActionUnpublish))
+			projects.Post("/:project_id/archive", h.changeProjectStatus(entity.ProjectActionArchive))
+			projects.Post("/:project_id/duplicate", h.duplicateProject)
+			projects.Put("/:project_id/template", h.setProjectTemplate)
+			projects.Put("/:project_id/workspace", h.moveProjectToWorkspace)
+			projects.Get("/:project_id/events", h.streamProjectEvents)
+
+			// widget types
+			projects.Get("/:project_id/widget-types", h.getWidgetTypes)
+			projects.Post("/:project_id/widget-types", h.createWidgetType)
+			projects.Put("/:project_id/widget-types/:name", h.updateWidgetType)
+			projects.Delete("/:project_id/widget-types/:name", h.deleteWidgetType)
+
+			// routes
+			projects.Get("/:project_id/routes", h.getRoutes)
+			projects.Post("/:project_id/routes", h.createRoute)
+			projects.Get("/:project_id/routes/resolve", h.resolveRoute)
+			projects.Put("/:project_id/routes/:route_id", h.updateRoute)
+			projects.Delete("/:project_id/routes/:route_id", h.deleteRoute)
+			projects.Get("/:project_id/navigation", h.getNavigationGraph)
+
+			// components
+			projects.Get("/:project_id/components", h.getComponents)
+			projects.Post("/:project_id/components", h.createComponent)
+			projects.Get("/:project_id/components/:component_id", h.getComponent)
+			projects.Delete("/:project_id/components/:component_id", h.deleteComponent)
+			projects.Get("/:project_id/components/:component_id/versions", h.getComponentVersions)
+			projects.Post("/:project_id/components/:component_id/versions", h.createComponentVersion)
+			projects.Get("/:project_id/components/:component_id/usage", h.getComponentUsage)
+
+			// design tokens
+			projects.Get("/:project_id/tokens", h.getTokens)
+			projects.Put("/:project_id/tokens", h.updateTokens)
+			projects.Get("/:project_id/tokens/export", h.exportTokens)
+
+			// assets
+			projects.Get("/:project_id/assets", h.getAssets)
+			projects.Post("/:project_id/assets", h.uploadAsset)
+			projects.Get("/:project_id/assets/:asset_id", h.getAsset)
+			projects.Delete("/:project_id/assets/:asset_id", h.deleteAsset)
+			projects.Get("/:project_id/assets/:asset_id/usage", h.getAssetUsage)
+
+			// localization
+			projects.Get("/:project_id/locales", h.getLocales)
+			projects.Post("/:project_id/locales", h.createLocale)
+			projects.Put("/:project_id/locales/:code/default", h.setDefaultLocale)
+			projects.Delete("/:project_id/locales/:code", h.deleteLocale)
+			projects.Get("/:project_id/translations", h.getTranslations)
+			projects.Put("/:project_id/translations", h.updateTranslations)
+			projects.Get("/:project_id/translations/report", h.getTranslationReport)
+			projects.Get("/:project_id/translations/export", h.exportTranslations)
+			projects.Post("/:project_id/translations/import", h.importTranslations)
+
+			// data sources
+			projects.Get("/:project_id/data-sources", h.getDataSources)
+			projects.Post("/:project_id/data-sources", h.createDataSource)
+			projects.Get("/:project_id/data-sources/:name", h.getDataSource)
+			projects.Put("/:project_id/data-sources/:name", h.updateDataSource)
+			projects.Delete("/:project_id/data-sources/:name", h.deleteDataSource)
+			projects.Post("/:project_id/data-sources/:name/record", h.recordDataSource)
+
+			// screens
+			screens := projects.Group("/:project_id/screens")
+			{
+				screens.Post("/", h.createScreen)
+				screens.Get("/", h.getScreens)
+				screens.Get("/:screen_id", h.getScreen)
+				screens.Put("/:screen_id", h.updateScreen)
+				screens.Patch("/:screen_id", h.patchScreen)
+				screens.Delete("/:screen_id", h.deleteScreen)
+
+				// branches
+				screens.Get("/:screen_id/branches", h.getBranches)
+				screens.Post("/:screen_id/branches", h.createBranch)
+				screens.Put("/:screen_id/branches/:branch_id", h.renameBranch)
+				screens.Patch("/:screen_id/branches/:branch_id", h.patchScreen)
+				screens.Delete("/:screen_id/branches/:branch_id", h.deleteBranch)
+				screens.Post("/:screen_id/branches/:branch_id/merge", h.mergeBranch)
+				screens.Get("/:screen_id/branches/:branch_id/collab", h.connectCollab)
+
+				// versions
+				screens.Get("/:screen_id/branches/:branch_id/versions", h.getVersionHistory)
+				screens.Post("/:screen_id/branches/:branch_id/versions", h.saveVersion)
+				screens.Get("/:screen_id/branches/:branch_id/versions/:version", h.getVersion)
+				screens.Post("/:screen_id/branches/:branch_id/versions/:version/restore", h.restoreVersion)
+
+				// diff
+				screens.Get("/:screen_id/diff", h.diffScreen)
+
+				// data bindings preview
+				screens.Post("/:screen_id/preview", h.previewBindings)
+
+				// releases
+				screens.Get("/:screen_id/releases", h.getReleases)
+				screens.Post("/:screen_id/releases", h.publishScreen)
+				screens.Get("/:screen_id/releases/:release", h.getRelease)
+				screens.Post("/:screen_id/releases/:release/rollback", h.rollbackRelease)
+
+				// comments
+				screens.Get("/:screen_id/threads", h.getCommentThreads)
+				screens.Post("/:screen_id/threads", h.createCommentThread)
+				screens.Get("/:screen_id/threads/:thread_id", h.getCommentThread)
+				screens.Post("/:screen_id/threads/:thread_id/comments", h.replyCommentThread)
+				screens.Post("/:screen_id/threads/:thread_id/resolve", h.setCommentThreadStatus(entity.CommentThreadResolved))
+				screens.Post("/:screen_id/threads/:thread_id/reopen", h.setCommentThreadStatus(entity.CommentThreadOpen))
+				screens.Get("/:screen_id/change-requests", h.getChangeRequests)
+				screens.Post("/:screen_id/change-requests", h.createChangeRequest)
+				screens.Get("/:screen_id/change-requests/:request_id", h.getChangeRequest)
+				screens.Post("/:screen_id/change-requests/:request_id/approve", h.approveChangeRequest)
+				screens.Post("/:screen_id/change-requests/:request_id/close", h.closeChangeRequest)
+			}
+		}
+
+		// workspaces
+		workspaces := api.Group("/workspaces")
+		{
+			workspaces.Use(limiter.New(limiter.Config{
+				Expiration: 1 * time.Second,
+				Max:        10,
+			}))
+
+			workspaces.Use(h.middlewareAuth)
+
+			workspaces.Post("/", h.createWorkspace)
+			workspaces.Get("/", h.getWorkspaces)
+			workspaces.Get("/:workspace_id", h.getWorkspace)
+			workspaces.Put("/:workspace_id", h.updateWorkspace)
+			workspaces.Delete("/:workspace_id", h.deleteWorkspace)
+			workspaces.Get("/:workspace_id/members", h.getWorkspaceMembers)
+			workspaces.Put("/:workspace_id/members", h.setWorkspaceMember)
+			workspaces.Delete("/:workspace_id/members/:user_id", h.removeWorkspaceMember)
+			workspaces.Get("/:workspace_id/projects", h.getWorkspaceProjects)
+			workspaces.Get("/:workspace_id/components", h.getComponents)
+			workspaces.Post("/:workspace_id/components", h.createComponent)
+			workspaces.Get("/:workspace_id/components/:component_id", h.getComponent)
+			workspaces.Delete("/:workspace_id/components/:component_id", h.deleteComponent)
+			workspaces.Get("/:workspace_id/components/:component_id/versions", h.getComponentVersions)
+			workspaces.Post("/:workspace_id/components/:component_id/versions", h.createComponentVersion)
+			workspaces.Get("/:workspace_id/components/:component_id/usage", h.getComponentUsage)
+		}
+
+		// public runtime: опубликованные экраны без авторизации
+		public := api.Group("/public")
+		{
+			public.Use(compress.New(compress.Config{
+				Level: compress.LevelBestSpeed,
+			}))
+
+			public.Get("/projects/:project_id", h.getRuntimeProject)
+			public.Get("/projects/:project_id/screens/:screen_id", h.getRuntimeScreen)
+			public.Get("/projects/:project_id/resolve", h.resolveRuntimeLink)
+			public.Get("/assets/:hash", h.downloadAsset(false))
+			public.Get("/assets/:hash/thumbnail", h.downloadAsset(true))
 		}
 
 	}
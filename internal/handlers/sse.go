@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"ui-platform-backend-service/internal/services"
+)
+
+// sseHeartbeat - как часто пишем комментарий, чтобы прокси не закрывали простаивающее соединение
+const sseHeartbeat = 15 * time.Second
+
+// lastEventId возвращает id, с которого клиент продолжает чтение ленты. Браузер передает его
+// в заголовке Last-Event-ID при переподключении, при первом подключении - в query.
+func lastEventId(c *fiber.Ctx) string {
+	if id := c.Get("Last-Event-ID"); id != "" {
+		return id
+	}
+	return c.Query("last_event_id")
+}
+
+// streamEvents отдает ленту событий в формате Server-Sent Events, пока клиент не отключится.
+func (h *Handler) streamEvents(c *fiber.Ctx, subscription *services.EventSubscription) error {
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer subscription.Close()
+		ticker := time.NewTicker(sseHeartbeat)
+		defer ticker.Stop()
+
+		// Сразу отправляем заголовки, чтобы клиент знал, что лента открыта
+		_, _ = fmt.Fprint(w, "retry: 3000\n\n")
+		if err := w.Flush(); err != nil {
+			return
+		}
+		for {
+			select {
+			case event, ok := <-subscription.Events():
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(event)
+				if err != nil {
+					h.log.Error().Err(err).Msg("error encoding event")
+					continue
+				}
+				if event.Id != "" {
+					_, _ = fmt.Fprintf(w, "id: %s\n", event.Id)
+				}
+				_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			case <-ticker.C:
+				_, _ = fmt.Fprint(w, ": ping\n\n")
+			}
+			// Ошибка записи означает, что клиент отключился
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
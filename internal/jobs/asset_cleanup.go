@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+	"ui-platform-backend-service/internal/services"
+)
+
+// AssetCleanup периодически удаляет файлы ассетов, на которые не ссылается ни один проект.
+type AssetCleanup struct {
+	log      zerolog.Logger
+	assets   services.Asset
+	interval time.Duration
+}
+
+func NewAssetCleanup(log zerolog.Logger, assets services.Asset, interval time.Duration) *AssetCleanup {
+	return &AssetCleanup{
+		log:      log,
+		assets:   assets,
+		interval: interval,
+	}
+}
+
+// Run выполняет очистку сразу и затем с заданным интервалом, пока не отменен ctx.
+func (j *AssetCleanup) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := j.assets.PurgeOrphans()
+		if err != nil {
+			j.log.Error().Err(err).Msg("error purging orphaned assets")
+		} else if deleted > 0 {
+			j.log.Info().Int("count", deleted).Msg("orphaned assets purged")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+	"ui-platform-backend-service/internal/services"
+)
+
+// TrashCleanup периодически удаляет из корзины проекты с истекшим сроком хранения.
+// Задача запускается на всех экземплярах; одновременно очистку выполняет только тот, кто взял блокировку.
+type TrashCleanup struct {
+	log      zerolog.Logger
+	projects services.Project
+	interval time.Duration
+}
+
+func NewTrashCleanup(log zerolog.Logger, projects services.Project, interval time.Duration) *TrashCleanup {
+	return &TrashCleanup{
+		log:      log,
+		projects: projects,
+		interval: interval,
+	}
+}
+
+// Run выполняет очистку сразу и затем с заданным интервалом, пока не отменен ctx.
+func (j *TrashCleanup) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := j.projects.PurgeExpired()
+		if err != nil {
+			j.log.Error().Err(err).Msg("error purging expired projects")
+		} else if purged > 0 {
+			j.log.Info().Int("count", purged).Msg("expired projects purged")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/internal/storages"
+)
+
+// authorizeProject проверяет, что пользователь состоит в проекте с ролью не ниже required.
+func authorizeProject(storage *storages.Storage, projectId, userId, required string) (role string, err error) {
+	role, err = storage.Project.GetMemberRole(projectId, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if !entity.RoleAtLeast(role, required) {
+		return "", ErrForbidden
+	}
+	return role, nil
+}
+
+// authorizeActiveProject проверяет роль пользователя в неудаленном проекте.
+// Для изменений (write) дополнительно запрещает работу с архивными проектами.
+func authorizeActiveProject(storage *storages.Storage, projectId, userId, required string, write bool) (role string, err error) {
+	role, err = authorizeProject(storage, projectId, userId, required)
+	if err != nil {
+		return "", err
+	}
+	project, err := storage.Project.GetById(projectId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if write && project.Status == entity.ProjectStatusArchived {
+		return "", ErrReadOnly
+	}
+	return role, nil
+}
+
+// authorizeWorkspace проверяет, что пользователь состоит в воркспейсе с ролью не ниже required.
+func authorizeWorkspace(storage *storages.Storage, workspaceId, userId, required string) (role string, err error) {
+	role, err = storage.Workspace.GetMemberRole(workspaceId, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if !entity.RoleAtLeast(role, required) {
+		return "", ErrForbidden
+	}
+	return role, nil
+}
+
+// authorizeScreen проверяет доступ к проекту и то, что экран принадлежит проекту.
+func authorizeScreen(storage *storages.Storage, projectId, screenId, userId, required string, write bool) error {
+	if _, err := authorizeActiveProject(storage, projectId, userId, required, write); err != nil {
+		return err
+	}
+	exists, err := storage.Screen.Exists(projectId, screenId)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// authorizeBranch проверяет доступ к экрану и возвращает его ветку.
+func authorizeBranch(storage *storages.Storage, projectId, screenId, branchId, userId, required string, write bool) (entity.ScreenBranch, error) {
+	if err := authorizeScreen(storage, projectId, screenId, userId, required, write); err != nil {
+		return entity.ScreenBranch{}, err
+	}
+	branch, err := storage.Branch.GetById(screenId, branchId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ScreenBranch{}, ErrNotFound
+	}
+	if err != nil {
+		return entity.ScreenBranch{}, err
+	}
+	return branch, nil
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/internal/storages"
+	"ui-platform-backend-service/pkg/thumbnail"
+)
+
+type Asset interface {
+	GetAll(projectId, userId string, unusedOnly bool) (assets []entity.Asset, err error)
+	Get(projectId, assetId, userId string) (asset entity.Asset, err error)
+	Upload(projectId, userId string, upload entity.AssetUpload) (asset entity.Asset, err error)
+	Delete(projectId, assetId, userId string) (err error)
+	GetUsages(projectId, assetId, userId string) (usages []entity.AssetUsage, err error)
+	Download(hash string, thumbnail bool) (data []byte, mimeType string, err error)
+	PurgeOrphans() (deleted int, err error)
+}
+
+type assetService struct {
+	log     zerolog.Logger
+	storage *storages.Storage
+}
+
+func NewAssetService(log zerolog.Logger, storage *storages.Storage) Asset {
+	return &assetService{
+		log:     log,
+		storage: storage,
+	}
+}
+
+// assetOrphanGrace - сколько хранится содержимое без ссылок: загрузка могла сохранить файл,
+// но еще не добавить ассет в проект.
+const assetOrphanGrace = time.Hour
+
+// GetAll возвращает ассеты проекта; unusedOnly оставляет только ассеты без ссылок.
+func (s *assetService) GetAll(projectId, userId string, unusedOnly bool) (assets []entity.Asset, err error) {
+	if _, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return nil, err
+	}
+	all, err := s.storage.Asset.GetAllByProjectId(projectId)
+	if err != nil {
+		return nil, err
+	}
+	assets = make([]entity.Asset, 0, len(all))
+	for _, asset := range all {
+		if unusedOnly && asset.Usages > 0 {
+			continue
+		}
+		asset.SetURLs()
+		assets = append(assets, asset)
+	}
+	return assets, nil
+}
+
+func (s *assetService) Get(projectId, assetId, userId string) (asset entity.Asset, err error) {
+	if _, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return entity.Asset{}, err
+	}
+	return s.find(projectId, assetId)
+}
+
+func (s *assetService) find(projectId, assetId string) (entity.Asset, error) {
+	asset, err := s.storage.Asset.GetById(projectId, assetId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Asset{}, fmt.Errorf("%w: asset", ErrNotFound)
+	}
+	if err != nil {
+		return entity.Asset{}, err
+	}
+	asset.SetURLs()
+	return asset, nil
+}
+
+// Upload определяет тип файла по содержимому, проверяет ограничения и сохраняет файл
+// по его хешу. Повторная загрузка того же файла в проект возвращает существующий ассет.
+func (s *assetService) Upload(projectId, userId string, upload entity.AssetUpload) (asset entity.Asset, err error) {
+	if _, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleEditor, true); err != nil {
+		return entity.Asset{}, err
+	}
+	mimeType := sniffAsset(upload.Data)
+	kind, ok := entity.AssetMimeTypes[mimeType]
+	if !ok {
+		return entity.Asset{}, fmt.Errorf("%w: unsupported file type %s", ErrInvalid, mimeType)
+	}
+	if limit := entity.AssetMaxSizes[kind]; int64(len(upload.Data)) > limit {
+		return entity.Asset{}, fmt.Errorf("%w: %s must be at most %d bytes", ErrInvalid, kind, limit)
+	}
+	if kind == entity.AssetKindSVG && svgActiveContent.Match(upload.Data) {
+		return entity.Asset{}, fmt.Errorf("%w: svg must not contain scripts or event handlers", ErrInvalid)
+	}
+
+	sum := sha256.Sum256(upload.Data)
+	blob := entity.AssetBlob{Hash: hex.EncodeToString(sum[:]), Kind: kind, MimeType: mimeType, Size: int64(len(upload.Data))}
+	var thumb []byte
+	// WebP стандартная библиотека не декодирует: такие изображения хранятся без размеров и превью
+	if kind == entity.AssetKindImage && mimeType != "image/webp" {
+		blob.Width, blob.Height, err = thumbnail.Size(upload.Data)
+		if err != nil {
+			return entity.Asset{}, fmt.Errorf("%w: image is corrupted", ErrInvalid)
+		}
+		if blob.Width*blob.Height > entity.AssetMaxPixels {
+			return entity.Asset{}, fmt.Errorf("%w: image must be at most %d pixels", ErrInvalid, entity.AssetMaxPixels)
+		}
+		if thumb, err = thumbnail.Make(upload.Data, entity.AssetThumbnailSize); err != nil {
+			return entity.Asset{}, fmt.Errorf("%w: image is corrupted", ErrInvalid)
+		}
+		blob.HasThumbnail = true
+	}
+	if err = s.storage.Asset.SaveBlob(blob, upload.Data, thumb); err != nil {
+		s.log.Error().Err(err).Str("hash", blob.Hash).Msg("error saving asset blob")
+		return entity.Asset{}, err
+	}
+
+	asset = entity.Asset{ProjectId: projectId, Name: upload.Name, Hash: blob.Hash, CreatedBy: userId}
+	err = s.storage.Asset.Create(&asset)
+	if errors.Is(err, storages.ErrAlreadyExists) {
+		asset, err = s.storage.Asset.GetByHash(projectId, blob.Hash)
+	}
+	if err != nil {
+		return entity.Asset{}, err
+	}
+	asset.Kind, asset.MimeType, asset.Size = blob.Kind, blob.MimeType, blob.Size
+	asset.Width, asset.Height, asset.HasThumbnail = blob.Width, blob.Height, blob.HasThumbnail
+	asset.SetURLs()
+	return asset, nil
+}
+
+// Delete удаляет ассет, на который не ссылаются экраны, релизы и компоненты проекта.
+func (s *assetService) Delete(projectId, assetId, userId string) (err error) {
+	if _, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleEditor, true); err != nil {
+		return err
+	}
+	asset, err := s.find(projectId, assetId)
+	if err != nil {
+		return err
+	}
+	usages, err := s.storage.Asset.GetUsages(projectId, asset.Hash)
+	if err != nil {
+		return err
+	}
+	if len(usages) > 0 {
+		return fmt.Errorf("%w: asset is used in %d places", ErrConflict, len(usages))
+	}
+	err = s.storage.Asset.DeleteById(projectId, assetId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: asset", ErrNotFound)
+	}
+	return err
+}
+
+func (s *assetService) GetUsages(projectId, assetId, userId string) (usages []entity.AssetUsage, err error) {
+	asset, err := s.Get(projectId, assetId, userId)
+	if err != nil {
+		return nil, err
+	}
+	usages, err = s.storage.Asset.GetUsages(projectId, asset.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if usages == nil {
+		return []entity.AssetUsage{}, nil
+	}
+	return usages, nil
+}
+
+var assetHashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Download отдает содержимое ассета по хешу для публичной выдачи.
+func (s *assetService) Download(hash string, thumbnail bool) (data []byte, mimeType string, err error) {
+	if !assetHashPattern.MatchString(hash) {
+		return nil, "", fmt.Errorf("%w: asset", ErrNotFound)
+	}
+	blob, err := s.storage.Asset.GetBlob(hash)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && thumbnail && !blob.HasThumbnail) {
+		return nil, "", fmt.Errorf("%w: asset", ErrNotFound)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	mimeType = blob.MimeType
+	if thumbnail {
+		mimeType = "image/png"
+	}
+	data, err = s.storage.Asset.ReadBlob(hash, thumbnail)
+	if errors.Is(err, storages.ErrBlobNotFound) {
+		s.log.Error().Str("hash", hash).Bool("thumbnail", thumbnail).Msg("asset blob is missing in storage")
+		return nil, "", fmt.Errorf("%w: asset", ErrNotFound)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return data, mimeType, nil
+}
+
+// PurgeOrphans удаляет файлы, на которые больше не ссылается ни один ассет.
+func (s *assetService) PurgeOrphans() (deleted int, err error) {
+	return s.storage.Asset.DeleteOrphanBlobs(time.Now().Add(-assetOrphanGrace))
+}
+
+var svgActiveContent = regexp.MustCompile(`(?i)<script|<foreignobject|javascript:|\son[a-z]+\s*=`)
+
+// sniffAsset определяет MIME-тип по содержимому. SVG стандартное определение
+// считает текстом, поэтому он распознается по корневому элементу.
+func sniffAsset(data []byte) string {
+	mimeType, _, _ := strings.Cut(http.DetectContentType(data), ";")
+	if strings.HasPrefix(mimeType, "text/") && isSVG(data) {
+		return "image/svg+xml"
+	}
+	return mimeType
+}
+
+var svgRoot = regexp.MustCompile(`^(<\?xml[^>]*\?>|<!--.*?-->|<!DOCTYPE[^>]*>|\s)*<svg[\s>]`)
+
+func isSVG(data []byte) bool {
+	head := bytes.TrimPrefix(data[:min(len(data), 4096)], []byte("\xef\xbb\xbf"))
+	return svgRoot.Match(head)
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/internal/storages"
+)
+
+type Branch interface {
+	GetAll(projectId, screenId, userId string) (branches []entity.ScreenBranch, err error)
+	Create(projectId, screenId, userId string, params entity.ScreenBranchCreate) (branch entity.ScreenBranch, err error)
+	Rename(projectId, screenId, branchId, userId, name string) (err error)
+	DeleteById(projectId, screenId, branchId, userId string) (err error)
+}
+
+type branchService struct {
+	log     zerolog.Logger
+	storage *storages.Storage
+}
+
+func NewBranchService(log zerolog.Logger, storage *storages.Storage) Branch {
+	return &branchService{
+		log:     log,
+		storage: storage,
+	}
+}
+
+func (s *branchService) GetAll(projectId, screenId, userId string) (branches []entity.ScreenBranch, err error) {
+	if err = authorizeScreen(s.storage, projectId, screenId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return nil, err
+	}
+	branches, err = s.storage.Branch.GetAllByScreenId(screenId)
+	if err != nil {
+		return nil, err
+	}
+	if branches == nil {
+		return []entity.ScreenBranch{}, nil
+	}
+	return branches, nil
+}
+
+// Create создает ветку из указанной версии исходной ветки.
+func (s *branchService) Create(projectId, screenId, userId string, params entity.ScreenBranchCreate) (branch entity.ScreenBranch, err error) {
+	if err = authorizeScreen(s.storage, projectId, screenId, userId, entity.ProjectRoleEditor, true); err != nil {
+		return entity.ScreenBranch{}, err
+	}
+	branchId, err := s.storage.Branch.Create(screenId, userId, params)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ScreenBranch{}, fmt.Errorf("%w: source branch or version", ErrNotFound)
+	}
+	if errors.Is(err, storages.ErrAlreadyExists) {
+		return entity.ScreenBranch{}, fmt.Errorf("%w: branch %s already exists", ErrConflict, params.Name)
+	}
+	if err != nil {
+		return entity.ScreenBranch{}, err
+	}
+	s.log.Info().Str("screen_id", screenId).Str("branch_id", branchId).Msg("branch created")
+	return s.storage.Branch.GetById(screenId, branchId)
+}
+
+func (s *branchService) Rename(projectId, screenId, branchId, userId, name string) (err error) {
+	if err = s.authorizeBranchChange(projectId, screenId, branchId, userId); err != nil {
+		return err
+	}
+	err = s.storage.Branch.Rename(screenId, branchId, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if errors.Is(err, storages.ErrAlreadyExists) {
+		return fmt.Errorf("%w: branch %s already exists", ErrConflict, name)
+	}
+	return err
+}
+
+func (s *branchService) DeleteById(projectId, screenId, branchId, userId string) (err error) {
+	if err = s.authorizeBranchChange(projectId, screenId, branchId, userId); err != nil {
+		return err
+	}
+	err = s.storage.Branch.DeleteById(screenId, branchId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// authorizeBranchChange проверяет права на изменение ветки. Ветку Main нельзя переименовать или удалить.
+func (s *branchService) authorizeBranchChange(projectId, screenId, branchId, userId string) error {
+	if err := authorizeScreen(s.storage, projectId, screenId, userId, entity.ProjectRoleEditor, true); err != nil {
+		return err
+	}
+	branch, err := s.storage.Branch.GetById(screenId, branchId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if branch.Name == entity.ScreenMainBranch {
+		return fmt.Errorf("%w: branch %s cannot be changed", ErrConflict, entity.ScreenMainBranch)
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/internal/storages"
+	"ui-platform-backend-service/pkg/jsonpatch"
+)
+
+type ChangeRequest interface {
+	GetAll(projectId, screenId, userId, status string) (requests []entity.ChangeRequest, err error)
+	Get(projectId, screenId, requestId, userId string) (request entity.ChangeRequest, err error)
+	Create(projectId, screenId, userId string, params entity.ChangeRequestCreate) (request entity.ChangeRequest, err error)
+	Approve(projectId, screenId, requestId, userId string) (request entity.ChangeRequest, err error)
+	Close(projectId, screenId, requestId, userId string) (request entity.ChangeRequest, err error)
+}
+
+type changeRequestService struct {
+	log     zerolog.Logger
+	storage *storages.Storage
+	merger  *mergeService
+}
+
+func NewChangeRequestService(log zerolog.Logger, storage *storages.Storage) ChangeRequest {
+	return &changeRequestService{
+		log:     log,
+		storage: storage,
+		merger:  &mergeService{log: log, storage: storage},
+	}
+}
+
+func (s *changeRequestService) GetAll(projectId, screenId, userId, status string) (requests []entity.ChangeRequest, err error) {
+	if err = authorizeScreen(s.storage, projectId, screenId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return nil, err
+	}
+	requests, err = s.storage.ChangeRequest.GetAllByScreenId(screenId, status)
+	if err != nil {
+		return nil, err
+	}
+	if requests == nil {
+		return []entity.ChangeRequest{}, nil
+	}
+	return requests, nil
+}
+
+// Get возвращает запрос с одобрениями, а для открытого запроса - еще проверки
+// и изменения, которые слияние внесет в Main.
+func (s *changeRequestService) Get(projectId, screenId, requestId, userId string) (request entity.ChangeRequest, err error) {
+	if err = authorizeScreen(s.storage, projectId, screenId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return entity.ChangeRequest{}, err
+	}
+	request, err = s.get(screenId, requestId)
+	if err != nil {
+		return entity.ChangeRequest{}, err
+	}
+	if request.Status != entity.ChangeRequestOpen {
+		return request, nil
+	}
+	if _, err = s.review(projectId, &request); err != nil {
+		return entity.ChangeRequest{}, err
+	}
+	return request, nil
+}
+
+// Create открывает запрос на слияние ветки в Main. Открывать запросы могут редакторы.
+func (s *changeRequestService) Create(projectId, screenId, userId string, params entity.ChangeRequestCreate) (request entity.ChangeRequest, err error) {
+	if err = authorizeScreen(s.storage, projectId, screenId, userId, entity.ProjectRoleEditor, true); err != nil {
+		return entity.ChangeRequest{}, err
+	}
+	source, err := s.storage.Branch.GetById(screenId, params.SourceBranchId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ChangeRequest{}, fmt.Errorf("%w: source branch", ErrNotFound)
+	}
+	if err != nil {
+		return entity.ChangeRequest{}, err
+	}
+	target, err := s.storage.Branch.GetByName(screenId, entity.ScreenMainBranch)
+	if err != nil {
+		return entity.ChangeRequest{}, err
+	}
+	if source.Id == target.Id {
+		return entity.ChangeRequest{}, fmt.Errorf("%w: cannot request to merge %s into itself", ErrInvalid, entity.ScreenMainBranch)
+	}
+
+	request = entity.ChangeRequest{
+		ScreenId:       screenId,
+		SourceBranchId: source.Id,
+		TargetBranchId: target.Id,
+		Title:          params.Title,
+		Description:    params.Description,
+		Status:         entity.ChangeRequestOpen,
+		CreatedBy:      userId,
+	}
+	err = s.storage.ChangeRequest.Create(&request)
+	if errors.Is(err, storages.ErrAlreadyExists) {
+		return entity.ChangeRequest{}, fmt.Errorf("%w: branch already has an open change request", ErrConflict)
+	}
+	if err != nil {
+		s.log.Error().Err(err).Str("screen_id", screenId).Msg("error creating change request")
+		return entity.ChangeRequest{}, err
+	}
+	s.log.Info().Str("screen_id", screenId).Str("change_request_id", request.Id).Msg("change request opened")
+	return s.Get(projectId, screenId, request.Id, userId)
+}
+
+// Approve одобряет текущую версию ветки-источника. Одобрять могут администраторы,
+// кроме автора запроса. Если после одобрения все проверки пройдены, ветка сливается в Main.
+func (s *changeRequestService) Approve(projectId, screenId, requestId, userId string) (request entity.ChangeRequest, err error) {
+	if err = authorizeScreen(s.storage, projectId, screenId, userId, entity.ProjectRoleAdmin, true); err != nil {
+		return entity.ChangeRequest{}, err
+	}
+	request, err = s.get(screenId, requestId)
+	if err != nil {
+		return entity.ChangeRequest{}, err
+	}
+	if request.Status != entity.ChangeRequestOpen {
+		return entity.ChangeRequest{}, fmt.Errorf("%w: change request is %s", ErrConflict, request.Status)
+	}
+	if request.CreatedBy == userId {
+		return entity.ChangeRequest{}, fmt.Errorf("%w: cannot approve own change request", ErrForbidden)
+	}
+	source, err := s.storage.Branch.GetById(screenId, request.SourceBranchId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ChangeRequest{}, fmt.Errorf("%w: source branch was deleted", ErrConflict)
+	}
+	if err != nil {
+		return entity.ChangeRequest{}, err
+	}
+	if err = s.storage.ChangeRequest.SetApproval(requestId, userId, source.HeadVersion); err != nil {
+		return entity.ChangeRequest{}, err
+	}
+
+	request, err = s.get(screenId, requestId)
+	if err != nil {
+		return entity.ChangeRequest{}, err
+	}
+	ready, err := s.review(projectId, &request)
+	if err != nil || !ready {
+		return request, err
+	}
+
+	// Все проверки пройдены - сливаем одобренную версию
+	target, err := s.storage.Branch.GetById(screenId, request.TargetBranchId)
+	if err != nil {
+		return entity.ChangeRequest{}, err
+	}
+	result, err := s.merger.merge(projectId, target, userId, entity.MergeRequest{
+		SourceBranchId: request.SourceBranchId,
+		SourceVersion:  request.Diff.To.Version,
+		TargetVersion:  request.Diff.From.Version,
+		Message:        fmt.Sprintf("Merge change request: %s", request.Title),
+	})
+	if err != nil {
+		return entity.ChangeRequest{}, err
+	}
+	if !result.Merged {
+		return entity.ChangeRequest{}, fmt.Errorf("%w: merge has conflicts", ErrConflict)
+	}
+	if err = s.storage.ChangeRequest.SetMerged(screenId, requestId, userId, result.Version.Version); err != nil {
+		return entity.ChangeRequest{}, err
+	}
+	s.log.Info().Str("change_request_id", requestId).Int("version", result.Version.Version).Msg("change request merged")
+	return s.get(screenId, requestId)
+}
+
+// Close закрывает запрос без слияния. Закрыть запрос может автор или администратор.
+func (s *changeRequestService) Close(projectId, screenId, requestId, userId string) (request entity.ChangeRequest, err error) {
+	role, err := authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleEditor, true)
+	if err != nil {
+		return entity.ChangeRequest{}, err
+	}
+	request, err = s.get(screenId, requestId)
+	if err != nil {
+		return entity.ChangeRequest{}, err
+	}
+	if request.CreatedBy != userId && !entity.RoleAtLeast(role, entity.ProjectRoleAdmin) {
+		return entity.ChangeRequest{}, ErrForbidden
+	}
+	err = s.storage.ChangeRequest.Close(screenId, requestId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ChangeRequest{}, fmt.Errorf("%w: change request is %s", ErrConflict, request.Status)
+	}
+	if err != nil {
+		return entity.ChangeRequest{}, err
+	}
+	return s.get(screenId, requestId)
+}
+
+func (s *changeRequestService) get(screenId, requestId string) (entity.ChangeRequest, error) {
+	request, err := s.storage.ChangeRequest.GetById(screenId, requestId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ChangeRequest{}, ErrNotFound
+	}
+	return request, err
+}
+
+// review заполняет у открытого запроса проверки и изменения Main после слияния
+// и сообщает, можно ли сливать. Одобрения устаревших версий источника не учитываются.
+func (s *changeRequestService) review(projectId string, request *entity.ChangeRequest) (ready bool, err error) {
+	source, err := s.storage.Branch.GetById(request.ScreenId, request.SourceBranchId)
+	if errors.Is(err, sql.ErrNoRows) {
+		request.Checks = []entity.ChangeRequestCheck{{
+			Name: entity.ChangeRequestCheckConflicts, Message: "source branch was deleted",
+		}}
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	target, err := s.storage.Branch.GetById(request.ScreenId, request.TargetBranchId)
+	if err != nil {
+		return false, err
+	}
+
+	// Одобрения
+	approvals := 0
+	for i := range request.Approvals {
+		request.Approvals[i].Stale = request.Approvals[i].SourceVersion != source.HeadVersion
+		if !request.Approvals[i].Stale {
+			approvals++
+		}
+	}
+	approvalCheck := entity.ChangeRequestCheck{
+		Name:    entity.ChangeRequestCheckApprovals,
+		Passed:  approvals >= entity.ChangeRequestRequiredApprovals,
+		Message: fmt.Sprintf("%d of %d required approvals", approvals, entity.ChangeRequestRequiredApprovals),
+	}
+
+	// Пробное слияние: конфликты и изменения Main
+	result, err := s.merger.merge(projectId, target, request.CreatedBy, entity.MergeRequest{
+		SourceBranchId: source.Id,
+		SourceVersion:  source.HeadVersion,
+		TargetVersion:  target.HeadVersion,
+		DryRun:         true,
+	})
+	if err != nil {
+		return false, err
+	}
+	conflictCheck := entity.ChangeRequestCheck{Name: entity.ChangeRequestCheckConflicts, Passed: len(result.Conflicts) == 0}
+	if !conflictCheck.Passed {
+		conflictCheck.Message = fmt.Sprintf("%d conflicts with %s, merge %s into the branch first",
+			len(result.Conflicts), entity.ScreenMainBranch, entity.ScreenMainBranch)
+	}
+
+	// Проверка результата по типам виджетов
+	validationCheck := entity.ChangeRequestCheck{Name: entity.ChangeRequestCheckValidation, Passed: true}
+	err = validateScreenDocument(s.storage, projectId, result.Widgets)
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		validationCheck.Passed = false
+		validationCheck.Message = "merged screen document is invalid"
+		validationCheck.Errors = validationErr.Errors
+	} else if err != nil {
+		return false, err
+	}
+	request.Checks = []entity.ChangeRequestCheck{approvalCheck, conflictCheck, validationCheck}
+
+	targetHead, err := s.storage.Version.Get(target.Id, result.Target.Version)
+	if err != nil {
+		return false, err
+	}
+	merged := entity.ScreenVersion{BranchId: source.Id, Version: source.HeadVersion, Widgets: result.Widgets, Settings: result.Settings}
+	request.Diff = &entity.ScreenDiff{
+		From:    result.Target,
+		To:      result.Source,
+		Patch:   jsonpatch.Diff(versionDocument(targetHead), versionDocument(merged)),
+		Summary: summarizeDiff(targetHead, merged),
+	}
+	return approvalCheck.Passed && conflictCheck.Passed && validationCheck.Passed, nil
+}
@@ -0,0 +1,742 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/internal/storages"
+	"ui-platform-backend-service/pkg/crdt"
+	"ui-platform-backend-service/pkg/jsonmerge"
+	"ui-platform-backend-service/pkg/jsonpatch"
+)
+
+const (
+	// collabPresenceTimeout - участник без обновлений присутствия дольше этого срока не показывается
+	collabPresenceTimeout = 2 * time.Minute
+	// collabLockTTL - время жизни блокировки виджета без продления
+	collabLockTTL = 30 * time.Second
+	// collabClientBuffer - очередь исходящих сообщений клиента; не успевающий клиент отключается
+	collabClientBuffer = 256
+	// collabSnapshotMessage - сообщение версий, сохраненных из сессии
+	collabSnapshotMessage = "Collaborative editing snapshot"
+	// collabSnapshotAttempts - сколько раз закрывающаяся сессия пробует сохранить последний снимок
+	collabSnapshotAttempts = 5
+	// collabAccessCheckInterval - как часто сессия перепроверяет права подключенных клиентов
+	collabAccessCheckInterval = time.Minute
+)
+
+type Collab interface {
+	Authorize(projectId, screenId, branchId, userId string) (canEdit bool, err error)
+	Join(projectId, branchId, userId string, canEdit bool) (client *CollabClient, err error)
+}
+
+// collabService держит сессии совместного редактирования веток, открытые на этом экземпляре.
+// Сессии одной ветки на разных экземплярах обмениваются операциями через Redis pub/sub,
+// а состояние периодически сохраняется новой версией ветки.
+type collabService struct {
+	log              zerolog.Logger
+	storage          *storages.Storage
+	snapshotInterval time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*collabSession
+}
+
+func NewCollabService(log zerolog.Logger, storage *storages.Storage, snapshotInterval time.Duration) Collab {
+	return &collabService{
+		log:              log,
+		storage:          storage,
+		snapshotInterval: snapshotInterval,
+		sessions:         map[string]*collabSession{},
+	}
+}
+
+// Authorize проверяет доступ к ветке: просматривать сессию может любой участник проекта,
+// редактировать - редактор и выше, пока проект не в архиве.
+func (s *collabService) Authorize(projectId, screenId, branchId, userId string) (canEdit bool, err error) {
+	if _, err = authorizeBranch(s.storage, projectId, screenId, branchId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return false, err
+	}
+	return s.canEdit(projectId, userId)
+}
+
+// canEdit сообщает, может ли участник сейчас править проект: роль редактора и выше
+// и проект не в архиве.
+func (s *collabService) canEdit(projectId, userId string) (bool, error) {
+	_, err := authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleEditor, true)
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrForbidden) || errors.Is(err, ErrReadOnly) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Join подключает клиента к сессии ветки, открывая ее при первом подключении.
+// Первым сообщением клиент получает welcome с текущим документом.
+func (s *collabService) Join(projectId, branchId, userId string, canEdit bool) (client *CollabClient, err error) {
+	client = &CollabClient{
+		Id:      uuid.NewString(),
+		UserId:  userId,
+		CanEdit: canEdit,
+		send:    make(chan []byte, collabClientBuffer),
+		locks:   map[string]bool{},
+	}
+	// Сессия могла закрыться между поиском и подключением - тогда открываем новую
+	for {
+		s.mu.Lock()
+		session, ok := s.sessions[branchId]
+		s.mu.Unlock()
+		if !ok {
+			if session, err = s.openSession(projectId, branchId); err != nil {
+				return nil, err
+			}
+		}
+		client.session = session
+		if session.join(client) {
+			return client, nil
+		}
+	}
+}
+
+// openSession открывает сессию ветки и регистрирует ее. Чтение головы и подписка идут
+// без s.mu; если другой запрос успел открыть сессию раньше, возвращается его сессия.
+func (s *collabService) openSession(projectId, branchId string) (*collabSession, error) {
+	head, err := s.storage.Version.Get(branchId, 0)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	messages, unsubscribe, err := s.storage.Collab.Subscribe(branchId)
+	if err != nil {
+		return nil, err
+	}
+
+	base := versionDocument(head)
+	replica := uuid.NewString()
+	session := &collabSession{
+		service:      s,
+		projectId:    projectId,
+		branchId:     branchId,
+		replica:      replica,
+		doc:          crdt.NewDocument(replica, base),
+		baseVersion:  head.Version,
+		baseDocument: base,
+		clients:      map[*CollabClient]bool{},
+		stop:         make(chan struct{}),
+	}
+
+	s.mu.Lock()
+	if opened, ok := s.sessions[branchId]; ok {
+		s.mu.Unlock()
+		if err = unsubscribe(); err != nil {
+			s.log.Warn().Err(err).Str("branch_id", branchId).Msg("error closing collab subscription")
+		}
+		return opened, nil
+	}
+	s.sessions[branchId] = session
+	s.mu.Unlock()
+
+	go session.run(messages, unsubscribe)
+	s.log.Info().Str("branch_id", branchId).Msg("collab session opened")
+	return session, nil
+}
+
+// CollabClient - подключение участника к сессии. Исходящие сообщения читаются из Messages;
+// канал закрывается после Leave, если клиент не успевает их забирать или теряет доступ к проекту.
+type CollabClient struct {
+	Id     string
+	UserId string
+	// CanEdit меняется под мьютексом сессии, когда у участника отзывают право редактирования
+	CanEdit bool
+
+	session *collabSession
+	send    chan []byte
+	closed  bool
+	// locks - виджеты, заблокированные клиентом; снимаются при отключении
+	locks map[string]bool
+}
+
+func (c *CollabClient) Messages() <-chan []byte {
+	return c.send
+}
+
+// Handle обрабатывает входящее сообщение клиента.
+func (c *CollabClient) Handle(data []byte) {
+	c.session.handle(c, data)
+}
+
+// Leave отключает клиента; последняя сессия на экземпляре сохраняет снимок и закрывается.
+func (c *CollabClient) Leave() {
+	c.session.leave(c)
+}
+
+// collabEnvelope - сообщение между сессиями ветки. Для снимка Vector - операции, вошедшие в него.
+type collabEnvelope struct {
+	Replica string               `json:"replica"`
+	Message entity.CollabMessage `json:"message"`
+	Vector  crdt.Vector          `json:"vector,omitempty"`
+}
+
+type collabSession struct {
+	service   *collabService
+	projectId string
+	branchId  string
+	// replica - идентификатор сессии в CRDT и pub/sub
+	replica string
+
+	mu sync.Mutex
+	// doc - документ ветки: база из версии baseVersion плюс еще не сохраненные операции
+	doc          *crdt.Document
+	baseVersion  int
+	baseDocument interface{}
+	dirty        bool
+	lastAuthor   string
+	clients      map[*CollabClient]bool
+	stop         chan struct{}
+	// closed - сессия закрывается и больше не принимает клиентов
+	closed bool
+}
+
+// join добавляет клиента в сессию. Возвращает false, если сессия уже закрывается.
+func (s *collabSession) join(client *CollabClient) bool {
+	presence := entity.CollabPresence{ClientId: client.Id, UserId: client.UserId, UpdatedAt: time.Now().UTC()}
+	if err := s.service.storage.Collab.SetPresence(s.branchId, presence); err != nil {
+		s.service.log.Warn().Err(err).Str("branch_id", s.branchId).Msg("error saving collab presence")
+	}
+	peers, err := s.service.storage.Collab.GetPresence(s.branchId)
+	if err != nil {
+		s.service.log.Warn().Err(err).Str("branch_id", s.branchId).Msg("error reading collab presence")
+	}
+	locks, err := s.service.storage.Collab.GetLocks(s.branchId)
+	if err != nil {
+		s.service.log.Warn().Err(err).Str("branch_id", s.branchId).Msg("error reading collab locks")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return false
+	}
+	s.clients[client] = true
+	s.deliver(client, entity.CollabMessage{
+		Type:     entity.CollabMessageWelcome,
+		ClientId: client.Id,
+		UserId:   client.UserId,
+		Document: s.doc.State(),
+		Version:  s.baseVersion,
+		ReadOnly: !client.CanEdit,
+		Peers:    activePeers(peers),
+		Locks:    locks,
+	})
+	message := entity.CollabMessage{Type: entity.CollabMessagePresence, Presence: &presence}
+	s.broadcast(message, client)
+	s.publish(message, nil)
+	return true
+}
+
+func (s *collabSession) leave(client *CollabClient) {
+	storage := s.service.storage.Collab
+	for widgetId := range client.locks {
+		if err := storage.ReleaseLock(s.branchId, widgetId, client.Id); err != nil {
+			s.service.log.Warn().Err(err).Str("branch_id", s.branchId).Msg("error releasing collab lock")
+		}
+	}
+	if err := storage.RemovePresence(s.branchId, client.Id); err != nil {
+		s.service.log.Warn().Err(err).Str("branch_id", s.branchId).Msg("error removing collab presence")
+	}
+
+	s.service.mu.Lock()
+	defer s.service.mu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.drop(client)
+	message := entity.CollabMessage{Type: entity.CollabMessageLeave, ClientId: client.Id, UserId: client.UserId}
+	s.broadcast(message, nil)
+	s.publish(message, nil)
+	if len(s.clients) == 0 && s.service.sessions[s.branchId] == s {
+		delete(s.service.sessions, s.branchId)
+		s.closed = true
+		close(s.stop)
+	}
+}
+
+func (s *collabSession) handle(client *CollabClient, data []byte) {
+	var message entity.CollabMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		s.reply(client, entity.CollabMessage{Type: entity.CollabMessageError, Message: "invalid message"})
+		return
+	}
+	switch message.Type {
+	case entity.CollabMessageOps:
+		s.handleOps(client, message.Ops)
+	case entity.CollabMessagePresence:
+		s.handlePresence(client, message.Presence)
+	case entity.CollabMessageLock:
+		s.handleLock(client, message.WidgetId)
+	case entity.CollabMessageUnlock:
+		s.handleUnlock(client, message.WidgetId)
+	default:
+		s.reply(client, entity.CollabMessage{Type: entity.CollabMessageError, Message: fmt.Sprintf("unknown message type %q", message.Type)})
+	}
+}
+
+// handleOps ставит на операции клиента метки сессии, применяет их и рассылает всем,
+// включая автора: по эхо клиент узнает итоговый порядок своих операций.
+func (s *collabSession) handleOps(client *CollabClient, ops []crdt.Op) {
+	if len(ops) == 0 || len(ops) > entity.CollabMaxOps {
+		s.reply(client, entity.CollabMessage{Type: entity.CollabMessageError, Message: fmt.Sprintf("ops must contain from 1 to %d operations", entity.CollabMaxOps)})
+		return
+	}
+	for _, op := range ops {
+		if err := checkCollabOp(op); err != nil {
+			s.reply(client, entity.CollabMessage{Type: entity.CollabMessageError, Message: err.Error()})
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !client.CanEdit {
+		s.deliver(client, entity.CollabMessage{Type: entity.CollabMessageError, Message: "read-only session"})
+		return
+	}
+	stamped := make([]crdt.Op, len(ops))
+	for i, op := range ops {
+		stamped[i] = s.doc.Local(op)
+	}
+	s.dirty = true
+	s.lastAuthor = client.UserId
+
+	message := entity.CollabMessage{Type: entity.CollabMessageOps, ClientId: client.Id, UserId: client.UserId, Ops: stamped}
+	s.broadcast(message, nil)
+	s.publish(message, nil)
+}
+
+func (s *collabSession) handlePresence(client *CollabClient, presence *entity.CollabPresence) {
+	if presence == nil {
+		presence = &entity.CollabPresence{}
+	}
+	presence.ClientId = client.Id
+	presence.UserId = client.UserId
+	presence.UpdatedAt = time.Now().UTC()
+	if err := s.service.storage.Collab.SetPresence(s.branchId, *presence); err != nil {
+		s.service.log.Warn().Err(err).Str("branch_id", s.branchId).Msg("error saving collab presence")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	message := entity.CollabMessage{Type: entity.CollabMessagePresence, Presence: presence}
+	s.broadcast(message, client)
+	s.publish(message, nil)
+}
+
+// handleLock берет или продлевает блокировку виджета. Если виджет занят,
+// клиент получает lock_denied с текущей блокировкой.
+func (s *collabSession) handleLock(client *CollabClient, widgetId string) {
+	s.mu.Lock()
+	canEdit := client.CanEdit
+	s.mu.Unlock()
+	if !canEdit {
+		s.reply(client, entity.CollabMessage{Type: entity.CollabMessageError, Message: "read-only session"})
+		return
+	}
+	if widgetId == "" {
+		s.reply(client, entity.CollabMessage{Type: entity.CollabMessageError, Message: "widget_id is required"})
+		return
+	}
+	lock := entity.CollabLock{
+		WidgetId:  widgetId,
+		ClientId:  client.Id,
+		UserId:    client.UserId,
+		ExpiresAt: time.Now().UTC().Add(collabLockTTL),
+	}
+	holder, acquired, err := s.service.storage.Collab.AcquireLock(s.branchId, lock)
+	if err != nil {
+		s.service.log.Error().Err(err).Str("branch_id", s.branchId).Msg("error acquiring collab lock")
+		s.reply(client, entity.CollabMessage{Type: entity.CollabMessageError, Message: "lock is unavailable"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !acquired {
+		s.deliver(client, entity.CollabMessage{Type: entity.CollabMessageLockDenied, WidgetId: widgetId, Lock: &holder})
+		return
+	}
+	client.locks[widgetId] = true
+	message := entity.CollabMessage{Type: entity.CollabMessageLock, WidgetId: widgetId, Lock: &holder}
+	s.broadcast(message, nil)
+	s.publish(message, nil)
+}
+
+func (s *collabSession) handleUnlock(client *CollabClient, widgetId string) {
+	if err := s.service.storage.Collab.ReleaseLock(s.branchId, widgetId, client.Id); err != nil {
+		s.service.log.Error().Err(err).Str("branch_id", s.branchId).Msg("error releasing collab lock")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !client.locks[widgetId] {
+		return
+	}
+	delete(client.locks, widgetId)
+	message := entity.CollabMessage{Type: entity.CollabMessageUnlock, ClientId: client.Id, WidgetId: widgetId}
+	s.broadcast(message, nil)
+	s.publish(message, nil)
+}
+
+// run обслуживает сессию: принимает сообщения других экземпляров и сохраняет снимки.
+// После закрытия сессии сохраняет последний снимок и отписывается от канала.
+func (s *collabSession) run(messages <-chan []byte, unsubscribe func() error) {
+	ticker := time.NewTicker(s.service.snapshotInterval)
+	defer ticker.Stop()
+	access := time.NewTicker(collabAccessCheckInterval)
+	defer access.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			s.snapshot(true)
+			if err := unsubscribe(); err != nil {
+				s.service.log.Warn().Err(err).Str("branch_id", s.branchId).Msg("error closing collab subscription")
+			}
+			s.service.log.Info().Str("branch_id", s.branchId).Msg("collab session closed")
+			return
+		case <-ticker.C:
+			s.snapshot(false)
+		case <-access.C:
+			s.checkAccess()
+		case data, ok := <-messages:
+			if !ok {
+				// Подписка оборвалась: операции других экземпляров больше не приходят
+				s.service.log.Error().Str("branch_id", s.branchId).Msg("collab subscription closed")
+				messages = nil
+				continue
+			}
+			s.remote(data)
+		}
+	}
+}
+
+// checkAccess перепроверяет права подключенных клиентов: потерявшие доступ к проекту
+// отключаются, потерявшие право редактирования переводятся в режим чтения. Их блокировки
+// не продлеваются и истекают через collabLockTTL.
+func (s *collabSession) checkAccess() {
+	s.mu.Lock()
+	users := map[string]bool{}
+	for client := range s.clients {
+		users[client.UserId] = true
+	}
+	s.mu.Unlock()
+
+	canView := make(map[string]bool, len(users))
+	canEdit := make(map[string]bool, len(users))
+	for userId := range users {
+		_, err := authorizeProject(s.service.storage, s.projectId, userId, entity.ProjectRoleViewer)
+		if err == nil {
+			canEdit[userId], err = s.service.canEdit(s.projectId, userId)
+		}
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrForbidden) {
+			canView[userId] = false
+			continue
+		}
+		if err != nil {
+			// Права не удалось проверить - оставляем клиента как есть до следующей проверки
+			s.service.log.Warn().Err(err).Str("branch_id", s.branchId).Msg("error checking collab access")
+			delete(users, userId)
+			continue
+		}
+		canView[userId] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for client := range s.clients {
+		if !users[client.UserId] {
+			continue
+		}
+		switch {
+		case !canView[client.UserId]:
+			s.drop(client)
+		case client.CanEdit && !canEdit[client.UserId]:
+			client.CanEdit = false
+			s.deliver(client, entity.CollabMessage{Type: entity.CollabMessageError, Message: "editing is no longer allowed", ReadOnly: true})
+		}
+	}
+}
+
+// remote применяет сообщение другой сессии ветки и пересылает его клиентам.
+func (s *collabSession) remote(data []byte) {
+	var envelope collabEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		s.service.log.Warn().Err(err).Str("branch_id", s.branchId).Msg("invalid collab message")
+		return
+	}
+	if envelope.Replica == s.replica {
+		return
+	}
+	message := envelope.Message
+
+	if message.Type == entity.CollabMessageSnapshot {
+		version, err := s.service.storage.Version.Get(s.branchId, message.Version)
+		if err != nil {
+			s.service.log.Error().Err(err).Str("branch_id", s.branchId).Msg("error loading collab snapshot")
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if version.Version > s.baseVersion {
+			s.rebase(version, envelope.Vector)
+		}
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if message.Type == entity.CollabMessageOps {
+		for _, op := range message.Ops {
+			s.doc.Apply(op)
+		}
+		s.dirty = true
+		s.lastAuthor = message.UserId
+	}
+	s.broadcast(message, nil)
+}
+
+// snapshot сохраняет состояние сессии новой версией ветки. Если голова ветки ушла вперед
+// мимо сессии (например, REST-сохранением), изменения сессии сливаются с ней трехсторонне,
+// при конфликте побеждает сессия. Автор снимка - последний редактор сессии; если он
+// больше не может править проект, правки не сохраняются. Одновременно снимок сохраняет
+// только один экземпляр; остальные получают его через pub/sub. Последний снимок
+// при конфликте повторяется до collabSnapshotAttempts раз.
+func (s *collabSession) snapshot(final bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return
+	}
+	log := s.service.log.With().Str("branch_id", s.branchId).Logger()
+	storage := s.service.storage
+
+	canEdit, err := s.service.canEdit(s.projectId, s.lastAuthor)
+	if err != nil {
+		log.Error().Err(err).Msg("error checking collab snapshot author access")
+		return
+	}
+	if !canEdit {
+		// Как и невалидный документ, правки останутся несохраненными до следующей правки
+		log.Warn().Str("user_id", s.lastAuthor).Msg("collab snapshot author can no longer edit the project, session edits are not saved")
+		s.dirty = false
+		s.broadcast(entity.CollabMessage{Type: entity.CollabMessageError, Message: "session edits are not saved: no write access"}, nil)
+		return
+	}
+
+	acquired, err := storage.Collab.AcquireSnapshotLock(s.branchId, s.service.snapshotInterval)
+	if err != nil {
+		log.Warn().Err(err).Msg("error acquiring collab snapshot lock")
+	}
+	// Последний снимок закрывающейся сессии сохраняем в любом случае, иначе правки могут потеряться
+	if !acquired && !final {
+		return
+	}
+
+	attempts := 1
+	if final {
+		attempts = collabSnapshotAttempts
+	}
+	vector := s.doc.Vector()
+	for attempt := 1; ; attempt++ {
+		head, err := storage.Version.Get(s.branchId, 0)
+		if err != nil {
+			log.Error().Err(err).Msg("error loading branch head")
+			return
+		}
+		document := s.doc.State()
+		headDocument := versionDocument(head)
+		if head.Version != s.baseVersion {
+			document, _ = jsonmerge.ThreeWay(s.baseDocument, document, headDocument)
+		}
+		if reflect.DeepEqual(document, headDocument) {
+			s.rebase(head, vector)
+			return
+		}
+
+		widgets, settings, ok := splitDocument(document)
+		if !ok {
+			log.Error().Msg("collab document has invalid structure")
+			return
+		}
+		version := entity.ScreenVersion{
+			BranchId: s.branchId,
+			Version:  head.Version + 1,
+			Widgets:  widgets,
+			Settings: settings,
+			AuthorId: s.lastAuthor,
+			Message:  collabSnapshotMessage,
+		}
+		err = createVersion(storage, s.projectId, &version)
+		var validationErr *ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			// Документ останется несохраненным до следующей правки
+			s.dirty = false
+			s.broadcast(entity.CollabMessage{Type: entity.CollabMessageError, Message: "screen document is invalid", Errors: validationErr.Errors}, nil)
+			return
+		case errors.Is(err, ErrConflict):
+			// Голову только что сдвинули. Обычный снимок повторится на следующем тике,
+			// последний снимок сразу сливается с новой головой
+			if attempt < attempts {
+				continue
+			}
+			if final {
+				log.Error().Err(err).Msg("collab snapshot conflicts with concurrent saves, session edits are not saved")
+			}
+			return
+		case err != nil:
+			log.Error().Err(err).Msg("error saving collab snapshot")
+			return
+		}
+		log.Info().Int("version", version.Version).Msg("collab snapshot saved")
+
+		s.rebase(version, vector)
+		message := entity.CollabMessage{Type: entity.CollabMessageSnapshot, Version: version.Version}
+		s.broadcast(message, nil)
+		s.publish(message, vector)
+		return
+	}
+}
+
+// rebase переносит документ сессии на сохраненную версию, включающую операции vector.
+// Если состояние при этом изменилось, клиенты получают документ заново.
+func (s *collabSession) rebase(version entity.ScreenVersion, vector crdt.Vector) {
+	before := s.doc.State()
+	base := versionDocument(version)
+	s.doc.Rebase(base, vector)
+	s.baseVersion = version.Version
+	s.baseDocument = base
+	s.dirty = s.doc.Pending() > 0
+
+	if !reflect.DeepEqual(before, s.doc.State()) {
+		s.broadcast(entity.CollabMessage{Type: entity.CollabMessageReset, Document: s.doc.State(), Version: version.Version}, nil)
+	}
+}
+
+// publish отправляет сообщение сессиям ветки на других экземплярах. Вызывается под s.mu.
+func (s *collabSession) publish(message entity.CollabMessage, vector crdt.Vector) {
+	data, err := json.Marshal(collabEnvelope{Replica: s.replica, Message: message, Vector: vector})
+	if err != nil {
+		s.service.log.Error().Err(err).Msg("error encoding collab message")
+		return
+	}
+	if err = s.service.storage.Collab.Publish(s.branchId, data); err != nil {
+		s.service.log.Error().Err(err).Str("branch_id", s.branchId).Msg("error publishing collab message")
+	}
+}
+
+// broadcast рассылает сообщение клиентам сессии, кроме except. Вызывается под s.mu.
+func (s *collabSession) broadcast(message entity.CollabMessage, except *CollabClient) {
+	for client := range s.clients {
+		if client != except {
+			s.deliver(client, message)
+		}
+	}
+}
+
+func (s *collabSession) reply(client *CollabClient, message entity.CollabMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliver(client, message)
+}
+
+// deliver ставит сообщение в очередь клиента; переполненная очередь отключает клиента.
+// Вызывается под s.mu.
+func (s *collabSession) deliver(client *CollabClient, message entity.CollabMessage) {
+	if client.closed {
+		return
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		s.service.log.Error().Err(err).Msg("error encoding collab message")
+		return
+	}
+	select {
+	case client.send <- data:
+	default:
+		s.service.log.Warn().Str("branch_id", s.branchId).Str("client_id", client.Id).Msg("collab client is too slow, disconnecting")
+		s.drop(client)
+	}
+}
+
+// drop убирает клиента из сессии и закрывает его очередь. Вызывается под s.mu.
+func (s *collabSession) drop(client *CollabClient) {
+	delete(s.clients, client)
+	if !client.closed {
+		client.closed = true
+		close(client.send)
+	}
+}
+
+func activePeers(peers []entity.CollabPresence) []entity.CollabPresence {
+	active := make([]entity.CollabPresence, 0, len(peers))
+	for _, peer := range peers {
+		if time.Since(peer.UpdatedAt) < collabPresenceTimeout {
+			active = append(active, peer)
+		}
+	}
+	return active
+}
+
+// checkCollabOp проверяет, что операция адресует допустимую часть документа экрана:
+// виджет целиком, его type, children или отдельное свойство props, либо отдельную настройку.
+func checkCollabOp(op crdt.Op) error {
+	if op.Type != crdt.OpSet && op.Type != crdt.OpRemove {
+		return fmt.Errorf("unknown op type %q", op.Type)
+	}
+	tokens, err := jsonpatch.ParsePointer(op.Path)
+	if err != nil {
+		return fmt.Errorf("invalid op path %q", op.Path)
+	}
+
+	allowed := false
+	switch {
+	case len(tokens) == 2 && tokens[0] == "settings":
+		allowed = true
+	case len(tokens) == 2 && tokens[0] == "widgets":
+		_, isObject := op.Value.(map[string]interface{})
+		allowed = op.Type == crdt.OpRemove || isObject
+	case len(tokens) == 3 && tokens[0] == "widgets" && tokens[2] == "type":
+		_, isString := op.Value.(string)
+		allowed = op.Type == crdt.OpSet && isString
+	case len(tokens) == 3 && tokens[0] == "widgets" && tokens[2] == "children":
+		_, isArray := op.Value.([]interface{})
+		allowed = op.Type == crdt.OpSet && isArray
+	case len(tokens) == 4 && tokens[0] == "widgets" && tokens[2] == "props":
+		allowed = true
+	}
+	if !allowed {
+		return fmt.Errorf("op %s is not allowed at %q", op.Type, op.Path)
+	}
+	return nil
+}
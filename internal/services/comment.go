@@ -0,0 +1,329 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/rs/zerolog"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/internal/storages"
+	"ui-platform-backend-service/pkg/rabbit_mq"
+)
+
+// commentMentionQueue - очередь уведомлений об упоминаниях в обсуждениях
+const commentMentionQueue = "comment_mention"
+
+type Comment interface {
+	GetThreads(projectId, screenId, userId string, filter entity.CommentThreadFilter) (threads []entity.CommentThread, err error)
+	GetThread(projectId, screenId, threadId, userId string, version int) (thread entity.CommentThread, err error)
+	CreateThread(projectId, screenId, userId string, params entity.CommentThreadCreate) (thread entity.CommentThread, err error)
+	Reply(projectId, screenId, threadId, userId string, params entity.CommentCreate) (comment entity.Comment, err error)
+	SetStatus(projectId, screenId, threadId, userId, status string) (thread entity.CommentThread, err error)
+}
+
+type commentService struct {
+	log      zerolog.Logger
+	producer *rabbit_mq.Producer
+	storage  *storages.Storage
+}
+
+func NewCommentService(log zerolog.Logger, producer *rabbit_mq.Producer, storage *storages.Storage) Comment {
+	return &commentService{
+		log:      log,
+		producer: producer,
+		storage:  storage,
+	}
+}
+
+// GetThreads возвращает обсуждения экрана с привязкой к виджетам. Для ветки из фильтра
+// привязка считается к ее версии filter.Version (или голове), иначе - к голове ветки обсуждения.
+func (s *commentService) GetThreads(projectId, screenId, userId string, filter entity.CommentThreadFilter) (threads []entity.CommentThread, err error) {
+	if err = authorizeScreen(s.storage, projectId, screenId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return nil, err
+	}
+	threads, err = s.storage.Comment.GetThreads(screenId, filter)
+	if err != nil {
+		return nil, err
+	}
+	if threads == nil {
+		return []entity.CommentThread{}, nil
+	}
+
+	// Версия привязки по ветке; nil - ветка удалена
+	versions := map[string]*entity.ScreenVersion{}
+	for i := range threads {
+		version, ok := versions[threads[i].BranchId]
+		if !ok {
+			requested := 0
+			if threads[i].BranchId == filter.BranchId {
+				requested = filter.Version
+			}
+			version, err = s.getAnchorVersion(threads[i].BranchId, requested)
+			if err != nil {
+				return nil, err
+			}
+			versions[threads[i].BranchId] = version
+		}
+		threads[i].Anchor = anchorThread(threads[i], version)
+	}
+	return threads, nil
+}
+
+// GetThread возвращает обсуждение с привязкой к версии version его ветки (нулевая - голова).
+func (s *commentService) GetThread(projectId, screenId, threadId, userId string, version int) (thread entity.CommentThread, err error) {
+	if err = authorizeScreen(s.storage, projectId, screenId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return entity.CommentThread{}, err
+	}
+	thread, err = s.getThread(screenId, threadId)
+	if err != nil {
+		return entity.CommentThread{}, err
+	}
+	screenVersion, err := s.getAnchorVersion(thread.BranchId, version)
+	if err != nil {
+		return entity.CommentThread{}, err
+	}
+	thread.Anchor = anchorThread(thread, screenVersion)
+	return thread, nil
+}
+
+// getAnchorVersion возвращает версию ветки для привязки обсуждений (нулевая - голова).
+// Если головы нет, ветка удалена: возвращается nil, и обсуждения считаются оторванными.
+func (s *commentService) getAnchorVersion(branchId string, version int) (*entity.ScreenVersion, error) {
+	screenVersion, err := s.storage.Version.Get(branchId, version)
+	if errors.Is(err, sql.ErrNoRows) {
+		if version == 0 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: version %d", ErrNotFound, version)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &screenVersion, nil
+}
+
+// CreateThread открывает обсуждение виджета версии ветки. Оставлять отзывы могут
+// все участники проекта, включая наблюдателей.
+func (s *commentService) CreateThread(projectId, screenId, userId string, params entity.CommentThreadCreate) (thread entity.CommentThread, err error) {
+	if err = authorizeScreen(s.storage, projectId, screenId, userId, entity.ProjectRoleViewer, true); err != nil {
+		return entity.CommentThread{}, err
+	}
+	var branch entity.ScreenBranch
+	if params.BranchId == "" {
+		branch, err = s.storage.Branch.GetByName(screenId, entity.ScreenMainBranch)
+	} else {
+		branch, err = s.storage.Branch.GetById(screenId, params.BranchId)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.CommentThread{}, fmt.Errorf("%w: branch", ErrNotFound)
+	}
+	if err != nil {
+		return entity.CommentThread{}, err
+	}
+	version, err := s.storage.Version.Get(branch.Id, params.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.CommentThread{}, fmt.Errorf("%w: version %d", ErrNotFound, params.Version)
+	}
+	if err != nil {
+		return entity.CommentThread{}, err
+	}
+
+	// Запоминаем виджет, чтобы находить его в следующих версиях
+	widgets := entity.ParseWidgets(version.Widgets)
+	widget, ok := widgets[params.WidgetId]
+	if !ok {
+		return entity.CommentThread{}, fmt.Errorf("%w: widget %q not found in version %d", ErrInvalid, params.WidgetId, version.Version)
+	}
+	thread = entity.CommentThread{
+		ScreenId:    screenId,
+		BranchId:    branch.Id,
+		Version:     version.Version,
+		WidgetId:    widget.Id,
+		WidgetType:  widget.Type,
+		WidgetProps: widget.Props,
+		Position:    entity.WidgetPositions(widgets)[widget.Id],
+		Status:      entity.CommentThreadOpen,
+		CreatedBy:   userId,
+	}
+	comment, mentioned, err := s.newComment(projectId, userId, params.Body)
+	if err != nil {
+		return entity.CommentThread{}, err
+	}
+	if err = s.storage.Comment.CreateThread(&thread, &comment); err != nil {
+		s.log.Error().Err(err).Str("screen_id", screenId).Msg("error creating comment thread")
+		return entity.CommentThread{}, err
+	}
+	s.notifyMentions(projectId, screenId, comment, mentioned)
+
+	thread.Comments = []entity.Comment{comment}
+	thread.Anchor = anchorThread(thread, &version)
+	return thread, nil
+}
+
+// Reply добавляет сообщение в обсуждение, в том числе закрытое.
+func (s *commentService) Reply(projectId, screenId, threadId, userId string, params entity.CommentCreate) (comment entity.Comment, err error) {
+	if err = authorizeScreen(s.storage, projectId, screenId, userId, entity.ProjectRoleViewer, true); err != nil {
+		return entity.Comment{}, err
+	}
+	thread, err := s.getThread(screenId, threadId)
+	if err != nil {
+		return entity.Comment{}, err
+	}
+	comment, mentioned, err := s.newComment(projectId, userId, params.Body)
+	if err != nil {
+		return entity.Comment{}, err
+	}
+	comment.ThreadId = thread.Id
+	if err = s.storage.Comment.AddComment(&comment); err != nil {
+		s.log.Error().Err(err).Str("thread_id", threadId).Msg("error adding comment")
+		return entity.Comment{}, err
+	}
+	s.notifyMentions(projectId, screenId, comment, mentioned)
+	return comment, nil
+}
+
+// SetStatus закрывает или заново открывает обсуждение. Это может автор обсуждения
+// и редакторы проекта.
+func (s *commentService) SetStatus(projectId, screenId, threadId, userId, status string) (thread entity.CommentThread, err error) {
+	role, err := authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleViewer, true)
+	if err != nil {
+		return entity.CommentThread{}, err
+	}
+	exists, err := s.storage.Screen.Exists(projectId, screenId)
+	if err != nil {
+		return entity.CommentThread{}, err
+	}
+	if !exists {
+		return entity.CommentThread{}, ErrNotFound
+	}
+	thread, err = s.getThread(screenId, threadId)
+	if err != nil {
+		return entity.CommentThread{}, err
+	}
+	if thread.CreatedBy != userId && !entity.RoleAtLeast(role, entity.ProjectRoleEditor) {
+		return entity.CommentThread{}, ErrForbidden
+	}
+	if thread.Status == status {
+		return entity.CommentThread{}, fmt.Errorf("%w: thread is already %s", ErrConflict, status)
+	}
+
+	err = s.storage.Comment.SetThreadStatus(screenId, threadId, status, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.CommentThread{}, ErrNotFound
+	}
+	if err != nil {
+		return entity.CommentThread{}, err
+	}
+	return s.getThread(screenId, threadId)
+}
+
+func (s *commentService) getThread(screenId, threadId string) (entity.CommentThread, error) {
+	thread, err := s.storage.Comment.GetThread(screenId, threadId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.CommentThread{}, ErrNotFound
+	}
+	return thread, err
+}
+
+// newComment готовит сообщение и находит упомянутых пользователей. Упоминания
+// незнакомых адресов и пользователей без доступа к проекту игнорируются.
+func (s *commentService) newComment(projectId, userId, body string) (entity.Comment, []entity.User, error) {
+	comment := entity.Comment{AuthorId: userId, Body: body, Mentions: []string{}}
+	var mentioned []entity.User
+	for _, email := range entity.ParseMentions(body) {
+		user, err := s.storage.User.GetByEmail(email)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return entity.Comment{}, nil, err
+		}
+		_, err = s.storage.Project.GetMemberRole(projectId, user.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return entity.Comment{}, nil, err
+		}
+		comment.Mentions = append(comment.Mentions, user.ID)
+		mentioned = append(mentioned, user)
+	}
+	return comment, mentioned, nil
+}
+
+// notifyMentions отправляет упомянутым пользователям уведомления. Ошибки отправки
+// только логируются: сообщение уже сохранено.
+func (s *commentService) notifyMentions(projectId, screenId string, comment entity.Comment, mentioned []entity.User) {
+	for _, user := range mentioned {
+		if user.ID == comment.AuthorId {
+			continue
+		}
+		err := s.producer.SendMessage(commentMentionQueue, map[string]string{
+			"email":      user.Email,
+			"user_id":    user.ID,
+			"project_id": projectId,
+			"screen_id":  screenId,
+			"thread_id":  comment.ThreadId,
+			"comment_id": comment.Id,
+			"author_id":  comment.AuthorId,
+			"body":       comment.Body,
+		})
+		if err != nil {
+			s.log.Error().Err(err).Str("comment_id", comment.Id).Msg("error sending mention notification")
+		}
+	}
+}
+
+// anchorThread находит виджет обсуждения в версии. Если виджета с прежним id нет
+// (например, его вырезали и вставили заново), обсуждение переносится на виджет того же типа
+// на прежнем месте в дереве, а затем - на виджет того же типа с теми же свойствами.
+// Без версии (ветка удалена) обсуждение оторвано.
+func anchorThread(thread entity.CommentThread, version *entity.ScreenVersion) *entity.CommentAnchor {
+	if version == nil {
+		return &entity.CommentAnchor{Status: entity.CommentAnchorOrphaned}
+	}
+	widgets := entity.ParseWidgets(version.Widgets)
+	positions := entity.WidgetPositions(widgets)
+	anchor := &entity.CommentAnchor{Version: version.Version, Status: entity.CommentAnchorOrphaned}
+
+	if _, ok := widgets[thread.WidgetId]; ok {
+		anchor.WidgetId = thread.WidgetId
+		anchor.Position = positions[thread.WidgetId]
+		anchor.Status = entity.CommentAnchorExact
+		if anchor.Position != thread.Position {
+			anchor.Status = entity.CommentAnchorMoved
+		}
+		return anchor
+	}
+
+	// Тот же тип на прежнем месте; у корневых виджетов порядка нет
+	if parent, ok := widgets[thread.Position.Parent]; ok && thread.Position.Index < len(parent.Children) {
+		candidate := parent.Children[thread.Position.Index]
+		if widgets[candidate].Type == thread.WidgetType {
+			anchor.WidgetId = candidate
+			anchor.Position = positions[candidate]
+			anchor.Status = entity.CommentAnchorReanchored
+			return anchor
+		}
+	}
+
+	// Тот же тип с теми же свойствами
+	ids := make([]string, 0, len(widgets))
+	for id := range widgets {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		widget := widgets[id]
+		if widget.Type == thread.WidgetType && reflect.DeepEqual(widget.Props, thread.WidgetProps) {
+			anchor.WidgetId = id
+			anchor.Position = positions[id]
+			anchor.Status = entity.CommentAnchorReanchored
+			return anchor
+		}
+	}
+	return anchor
+}
@@ -0,0 +1,461 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/internal/storages"
+	"ui-platform-backend-service/pkg/jsonpatch"
+	"ui-platform-backend-service/pkg/jsonschema"
+)
+
+type Component interface {
+	GetAll(scope entity.ComponentScope, userId string) (components []entity.Component, err error)
+	Get(scope entity.ComponentScope, componentId, userId string, version int) (component entity.Component, err error)
+	GetVersions(scope entity.ComponentScope, componentId, userId string) (versions []entity.ComponentVersion, err error)
+	Create(scope entity.ComponentScope, userId string, params entity.ComponentCreate) (component entity.Component, err error)
+	CreateVersion(scope entity.ComponentScope, componentId, userId string, params entity.ComponentVersionCreate) (version entity.ComponentVersion, err error)
+	Delete(scope entity.ComponentScope, componentId, userId string) (err error)
+	GetImpact(scope entity.ComponentScope, componentId, userId string) (impact entity.ComponentImpact, err error)
+	Resolve(projectId, userId string, widgets map[string]interface{}) (resolved map[string]interface{}, err error)
+}
+
+type componentService struct {
+	log     zerolog.Logger
+	storage *storages.Storage
+}
+
+func NewComponentService(log zerolog.Logger, storage *storages.Storage) Component {
+	return &componentService{
+		log:     log,
+		storage: storage,
+	}
+}
+
+// GetAll возвращает компоненты уровня: для проекта - его компоненты и компоненты его воркспейса.
+func (s *componentService) GetAll(scope entity.ComponentScope, userId string) (components []entity.Component, err error) {
+	workspaceId, err := s.authorize(scope, userId, false)
+	if err != nil {
+		return nil, err
+	}
+	components, err = s.storage.Component.GetAllByScope(scope.ProjectId, workspaceId)
+	if err != nil {
+		return nil, err
+	}
+	if components == nil {
+		return []entity.Component{}, nil
+	}
+	return components, nil
+}
+
+// Get возвращает компонент с виджетами версии; нулевая версия - последняя.
+func (s *componentService) Get(scope entity.ComponentScope, componentId, userId string, version int) (component entity.Component, err error) {
+	component, err = s.find(scope, componentId, userId, false)
+	if err != nil {
+		return entity.Component{}, err
+	}
+	componentVersion, err := s.storage.Component.GetVersion(componentId, version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Component{}, fmt.Errorf("%w: version %d", ErrNotFound, version)
+	}
+	if err != nil {
+		return entity.Component{}, err
+	}
+	component.Version, component.Root, component.Widgets = componentVersion.Version, componentVersion.Root, componentVersion.Widgets
+	return component, nil
+}
+
+func (s *componentService) GetVersions(scope entity.ComponentScope, componentId, userId string) (versions []entity.ComponentVersion, err error) {
+	if _, err = s.find(scope, componentId, userId, false); err != nil {
+		return nil, err
+	}
+	return s.storage.Component.GetVersions(componentId)
+}
+
+// Create создает компонент уровня scope. Компоненты проекта создают редакторы,
+// компоненты воркспейса - администраторы воркспейса.
+func (s *componentService) Create(scope entity.ComponentScope, userId string, params entity.ComponentCreate) (component entity.Component, err error) {
+	if _, err = s.authorize(scope, userId, true); err != nil {
+		return entity.Component{}, err
+	}
+	if err = s.validate(scope, "", params.Widgets); err != nil {
+		return entity.Component{}, err
+	}
+	component = entity.Component{
+		ProjectId:   scope.ProjectId,
+		WorkspaceId: scope.WorkspaceId,
+		Name:        params.Name,
+		Description: params.Description,
+		CreatedBy:   userId,
+	}
+	version := entity.ComponentVersion{Root: params.Root, Widgets: params.Widgets, Message: params.Message, CreatedBy: userId}
+	if err = s.storage.Component.Create(&component, &version); err != nil {
+		s.log.Error().Err(err).Msg("error creating component")
+		return entity.Component{}, err
+	}
+	component.Root, component.Widgets = version.Root, version.Widgets
+	return component, nil
+}
+
+// CreateVersion сохраняет новую версию компонента. Незакрепленные экземпляры
+// получают ее при следующем чтении с разворачиванием и публикации экранов.
+func (s *componentService) CreateVersion(scope entity.ComponentScope, componentId, userId string, params entity.ComponentVersionCreate) (version entity.ComponentVersion, err error) {
+	component, err := s.find(scope, componentId, userId, true)
+	if err != nil {
+		return entity.ComponentVersion{}, err
+	}
+	if params.BaseVersion != 0 && params.BaseVersion != component.Version {
+		return entity.ComponentVersion{}, fmt.Errorf("%w: component moved to version %d", ErrConflict, component.Version)
+	}
+	owner := entity.ComponentScope{ProjectId: component.ProjectId, WorkspaceId: component.WorkspaceId}
+	if err = s.validate(owner, componentId, params.Widgets); err != nil {
+		return entity.ComponentVersion{}, err
+	}
+
+	version = entity.ComponentVersion{
+		ComponentId: componentId,
+		Version:     component.Version + 1,
+		Root:        params.Root,
+		Widgets:     params.Widgets,
+		Message:     params.Message,
+		CreatedBy:   userId,
+	}
+	err = s.storage.Component.CreateVersion(&version)
+	if errors.Is(err, storages.ErrAlreadyExists) {
+		return entity.ComponentVersion{}, fmt.Errorf("%w: component was changed concurrently, retry", ErrConflict)
+	}
+	if err != nil {
+		return entity.ComponentVersion{}, err
+	}
+	s.log.Info().Str("component_id", componentId).Int("version", version.Version).Msg("component version saved")
+	return version, nil
+}
+
+// Delete удаляет компонент, если его экземпляров нет ни в одной ветке экранов и ни в одном компоненте.
+func (s *componentService) Delete(scope entity.ComponentScope, componentId, userId string) (err error) {
+	component, err := s.find(scope, componentId, userId, true)
+	if err != nil {
+		return err
+	}
+	usages, err := s.storage.Component.GetUsages(componentId, component.ProjectId, component.WorkspaceId)
+	if err != nil {
+		return err
+	}
+	if len(usages) > 0 {
+		return fmt.Errorf("%w: component is used by %d widgets", ErrConflict, len(usages))
+	}
+	err = s.storage.Component.DeleteById(componentId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// GetImpact показывает, какие экраны и компоненты затронет изменение компонента.
+func (s *componentService) GetImpact(scope entity.ComponentScope, componentId, userId string) (impact entity.ComponentImpact, err error) {
+	component, err := s.find(scope, componentId, userId, false)
+	if err != nil {
+		return entity.ComponentImpact{}, err
+	}
+	usages, err := s.storage.Component.GetUsages(componentId, component.ProjectId, component.WorkspaceId)
+	if err != nil {
+		return entity.ComponentImpact{}, err
+	}
+
+	impact = entity.ComponentImpact{Component: component, Usages: []entity.ComponentUsage{}}
+	screens, projects, components := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	for _, usage := range usages {
+		usage.Pinned = usage.Version != 0
+		if !usage.Pinned {
+			impact.Affected++
+		}
+		if usage.ScreenId != "" {
+			screens[usage.ScreenId], projects[usage.ProjectId] = true, true
+		} else {
+			components[usage.ComponentId] = true
+		}
+		impact.Usages = append(impact.Usages, usage)
+	}
+	impact.Screens, impact.Projects, impact.Components = len(screens), len(projects), len(components)
+	return impact, nil
+}
+
+// Resolve разворачивает экземпляры компонентов в виджетах экрана проекта.
+func (s *componentService) Resolve(projectId, userId string, widgets map[string]interface{}) (resolved map[string]interface{}, err error) {
+	if _, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return nil, err
+	}
+	return newComponentResolver(s.storage, entity.ComponentScope{ProjectId: projectId}).expand(widgets, nil)
+}
+
+// authorize проверяет доступ к уровню компонентов и возвращает воркспейс, компоненты
+// которого видны на этом уровне.
+func (s *componentService) authorize(scope entity.ComponentScope, userId string, write bool) (workspaceId string, err error) {
+	if scope.WorkspaceId != "" {
+		required := entity.ProjectRoleViewer
+		if write {
+			required = entity.ProjectRoleAdmin
+		}
+		if _, err = authorizeWorkspace(s.storage, scope.WorkspaceId, userId, required); err != nil {
+			return "", err
+		}
+		return scope.WorkspaceId, nil
+	}
+
+	required := entity.ProjectRoleViewer
+	if write {
+		required = entity.ProjectRoleEditor
+	}
+	if _, err = authorizeActiveProject(s.storage, scope.ProjectId, userId, required, write); err != nil {
+		return "", err
+	}
+	project, err := s.storage.Project.GetById(scope.ProjectId)
+	if err != nil {
+		return "", err
+	}
+	return project.WorkspaceId, nil
+}
+
+// find возвращает компонент, видимый на уровне scope. Компоненты воркспейса
+// видны в его проектах, но изменяются только на уровне воркспейса.
+func (s *componentService) find(scope entity.ComponentScope, componentId, userId string, write bool) (entity.Component, error) {
+	workspaceId, err := s.authorize(scope, userId, write)
+	if err != nil {
+		return entity.Component{}, err
+	}
+	if _, err = uuid.Parse(componentId); err != nil {
+		return entity.Component{}, ErrNotFound
+	}
+	component, err := s.storage.Component.GetById(componentId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Component{}, ErrNotFound
+	}
+	if err != nil {
+		return entity.Component{}, err
+	}
+	switch {
+	case scope.ProjectId != "" && component.ProjectId == scope.ProjectId:
+	case workspaceId != "" && component.WorkspaceId == workspaceId:
+		if write && scope.WorkspaceId == "" {
+			return entity.Component{}, fmt.Errorf("%w: workspace components are managed in the workspace", ErrForbidden)
+		}
+	default:
+		return entity.Component{}, ErrNotFound
+	}
+	return component, nil
+}
+
+// validate проверяет виджеты компонента по типам его уровня (у воркспейса - только встроенные),
+// ссылки на другие компоненты, дизайн-токены, выражения привязки и отсутствие циклов вложенности.
+func (s *componentService) validate(scope entity.ComponentScope, componentId string, widgets map[string]interface{}) error {
+	types, err := projectWidgetTypes(s.storage, scope.ProjectId)
+	if err != nil {
+		return err
+	}
+	resolver := newComponentResolver(s.storage, scope)
+	errs := validateWidgets(types, widgets)
+	refErrs, err := resolver.check(widgets)
+	if err != nil {
+		return err
+	}
+	tokenErrs, err := checkTokenRefs(s.storage, scope.ProjectId, widgets)
+	if err != nil {
+		return err
+	}
+	bindingErrs, err := checkBindings(s.storage, scope.ProjectId, widgets)
+	if err != nil {
+		return err
+	}
+	if errs = append(append(append(errs, refErrs...), tokenErrs...), bindingErrs...); len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	var stack []string
+	if componentId != "" {
+		stack = []string{componentId}
+	}
+	_, err = resolver.expand(widgets, stack)
+	return err
+}
+
+// componentResolver загружает версии компонентов, видимых проекту или воркспейсу,
+// и разворачивает их экземпляры. Загруженные версии кешируются на время одного разворачивания.
+type componentResolver struct {
+	storage     *storages.Storage
+	scope       entity.ComponentScope
+	workspaceId string
+	initialized bool
+	components  map[string]entity.Component
+	versions    map[entity.ComponentRef]entity.ComponentVersion
+}
+
+func newComponentResolver(storage *storages.Storage, scope entity.ComponentScope) *componentResolver {
+	return &componentResolver{
+		storage:    storage,
+		scope:      scope,
+		components: map[string]entity.Component{},
+		versions:   map[entity.ComponentRef]entity.ComponentVersion{},
+	}
+}
+
+func (r *componentResolver) component(componentId string) (entity.Component, error) {
+	if component, ok := r.components[componentId]; ok {
+		return component, nil
+	}
+	if !r.initialized {
+		r.workspaceId = r.scope.WorkspaceId
+		if r.scope.ProjectId != "" {
+			project, err := r.storage.Project.GetById(r.scope.ProjectId)
+			if err != nil {
+				return entity.Component{}, err
+			}
+			r.workspaceId = project.WorkspaceId
+		}
+		r.initialized = true
+	}
+	if _, err := uuid.Parse(componentId); err != nil {
+		return entity.Component{}, fmt.Errorf("%w: component %s", ErrNotFound, componentId)
+	}
+	component, err := r.storage.Component.GetById(componentId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Component{}, fmt.Errorf("%w: component %s", ErrNotFound, componentId)
+	}
+	if err != nil {
+		return entity.Component{}, err
+	}
+	visible := (r.scope.ProjectId != "" && component.ProjectId == r.scope.ProjectId) ||
+		(r.workspaceId != "" && component.WorkspaceId == r.workspaceId)
+	if !visible {
+		return entity.Component{}, fmt.Errorf("%w: component %s", ErrNotFound, componentId)
+	}
+	r.components[componentId] = component
+	return component, nil
+}
+
+func (r *componentResolver) version(ref entity.ComponentRef) (entity.ComponentVersion, error) {
+	if version, ok := r.versions[ref]; ok {
+		return version, nil
+	}
+	if _, err := r.component(ref.ComponentId); err != nil {
+		return entity.ComponentVersion{}, err
+	}
+	version, err := r.storage.Component.GetVersion(ref.ComponentId, ref.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ComponentVersion{}, fmt.Errorf("%w: component %s version %d", ErrNotFound, ref.ComponentId, ref.Version)
+	}
+	if err != nil {
+		return entity.ComponentVersion{}, err
+	}
+	r.versions[ref] = version
+	return version, nil
+}
+
+// check возвращает нарушения для экземпляров, ссылающихся на недоступные компоненты или версии.
+func (r *componentResolver) check(widgets map[string]interface{}) ([]jsonschema.Error, error) {
+	var errs []jsonschema.Error
+	for _, id := range sortedDocumentIds(widgets) {
+		ref, ok := entity.ParseComponentRef(entity.ParseWidget(id, widgets[id]))
+		if !ok {
+			continue
+		}
+		if _, err := r.component(ref.ComponentId); errors.Is(err, ErrNotFound) {
+			errs = append(errs, jsonschema.Error{Path: jsonpatch.FormatPointer([]string{"widgets", id, "props", "component_id"}), Message: "component not found"})
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if _, err := r.version(ref); errors.Is(err, ErrNotFound) {
+			errs = append(errs, jsonschema.Error{Path: jsonpatch.FormatPointer([]string{"widgets", id, "props", "version"}), Message: "component version not found"})
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return errs, nil
+}
+
+// expand возвращает копию виджетов, в которой экземпляры компонентов заменены их поддеревьями.
+// stack - компоненты, внутри которых идет разворачивание; повтор в нем означает цикл.
+func (r *componentResolver) expand(widgets map[string]interface{}, stack []string) (map[string]interface{}, error) {
+	resolved := make(map[string]interface{}, len(widgets))
+	for id, raw := range widgets {
+		resolved[id] = raw
+	}
+	for _, id := range sortedDocumentIds(widgets) {
+		ref, ok := entity.ParseComponentRef(entity.ParseWidget(id, widgets[id]))
+		if !ok {
+			continue
+		}
+		if err := r.expandInstance(resolved, id, ref, stack); err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+// expandInstance заменяет экземпляр instanceId корнем компонента и добавляет
+// остальные его виджеты с id "<instanceId>.<id>".
+func (r *componentResolver) expandInstance(resolved map[string]interface{}, instanceId string, ref entity.ComponentRef, stack []string) error {
+	for _, componentId := range stack {
+		if componentId == ref.ComponentId {
+			return fmt.Errorf("%w: component %s includes itself", ErrInvalid, ref.ComponentId)
+		}
+	}
+	if len(stack) >= entity.ComponentMaxDepth {
+		return fmt.Errorf("%w: components are nested deeper than %d levels", ErrInvalid, entity.ComponentMaxDepth)
+	}
+	version, err := r.version(ref)
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("%w: widget %s references unknown %v", ErrInvalid, instanceId, err)
+	}
+	if err != nil {
+		return err
+	}
+	stack = append(stack[:len(stack):len(stack)], ref.ComponentId)
+
+	rename := func(id string) string {
+		if id == version.Root {
+			return instanceId
+		}
+		return instanceId + "." + id
+	}
+	for _, id := range sortedDocumentIds(version.Widgets) {
+		newId := rename(id)
+		if _, exists := resolved[newId]; exists && newId != instanceId {
+			return fmt.Errorf("%w: widget %s of component %s collides with widget %s", ErrInvalid, id, ref.ComponentId, newId)
+		}
+		source, _ := version.Widgets[id].(map[string]interface{})
+		object := make(map[string]interface{}, len(source))
+		for key, value := range source {
+			object[key] = value
+		}
+		widget := entity.ParseWidget(id, source)
+		if len(widget.Children) > 0 {
+			children := make([]interface{}, len(widget.Children))
+			for i, child := range widget.Children {
+				children[i] = rename(child)
+			}
+			object["children"] = children
+		}
+		resolved[newId] = object
+
+		if nested, ok := entity.ParseComponentRef(widget); ok {
+			if err = r.expandInstance(resolved, newId, nested, stack); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func sortedDocumentIds(widgets map[string]interface{}) []string {
+	ids := make([]string, 0, len(widgets))
+	for id := range widgets {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,487 @@
+package services
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/internal/storages"
+	"ui-platform-backend-service/pkg/jsonpatch"
+	"ui-platform-backend-service/pkg/jsonschema"
+	"ui-platform-backend-service/pkg/safehttp"
+)
+
+type DataSource interface {
+	GetAll(projectId, userId string) (sources []entity.DataSource, err error)
+	Get(projectId, name, userId string) (source entity.DataSource, err error)
+	Create(source entity.DataSource, userId string) (created entity.DataSource, err error)
+	Update(source entity.DataSource, userId string) (updated entity.DataSource, err error)
+	Delete(projectId, name, userId string) (err error)
+	Record(projectId, name, userId string) (source entity.DataSource, err error)
+	Preview(projectId, screenId, userId string, request entity.BindingPreviewRequest) (preview entity.BindingPreview, err error)
+}
+
+type dataSourceService struct {
+	log     zerolog.Logger
+	storage *storages.Storage
+	client  *http.Client
+}
+
+func NewDataSourceService(log zerolog.Logger, storage *storages.Storage) DataSource {
+	return &dataSourceService{
+		log:     log,
+		storage: storage,
+		client:  safehttp.NewClient(dataSourceTimeout),
+	}
+}
+
+// dataSourceTimeout - сколько ждать ответа REST-источника при записи ответа.
+const dataSourceTimeout = 10 * time.Second
+
+// GetAll возвращает источники проекта без записанных ответов; секреты заменены маской.
+func (s *dataSourceService) GetAll(projectId, userId string) (sources []entity.DataSource, err error) {
+	if _, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return nil, err
+	}
+	sources, err = s.storage.DataSource.GetAllByProjectId(projectId)
+	if err != nil {
+		return nil, err
+	}
+	if sources == nil {
+		return []entity.DataSource{}, nil
+	}
+	for i := range sources {
+		sources[i].Redact()
+	}
+	return sources, nil
+}
+
+func (s *dataSourceService) Get(projectId, name, userId string) (source entity.DataSource, err error) {
+	if _, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return entity.DataSource{}, err
+	}
+	source, err = s.find(projectId, name)
+	if err != nil {
+		return entity.DataSource{}, err
+	}
+	source.Redact()
+	return source, nil
+}
+
+func (s *dataSourceService) find(projectId, name string) (entity.DataSource, error) {
+	source, err := s.storage.DataSource.GetByName(projectId, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.DataSource{}, fmt.Errorf("%w: data source %s", ErrNotFound, name)
+	}
+	return source, err
+}
+
+func (s *dataSourceService) Create(source entity.DataSource, userId string) (created entity.DataSource, err error) {
+	if _, err = authorizeActiveProject(s.storage, source.ProjectId, userId, entity.ProjectRoleEditor, true); err != nil {
+		return entity.DataSource{}, err
+	}
+	if err = checkSecrets(source); err != nil {
+		return entity.DataSource{}, err
+	}
+	source.CreatedBy = userId
+	err = s.storage.DataSource.Create(&source)
+	if errors.Is(err, storages.ErrAlreadyExists) {
+		return entity.DataSource{}, fmt.Errorf("%w: data source %s already exists", ErrConflict, source.Name)
+	}
+	if err != nil {
+		return entity.DataSource{}, err
+	}
+	source.Redact()
+	return source, nil
+}
+
+// Update заменяет настройки источника. Секреты, пришедшие маской, остаются прежними.
+func (s *dataSourceService) Update(source entity.DataSource, userId string) (updated entity.DataSource, err error) {
+	if _, err = authorizeActiveProject(s.storage, source.ProjectId, userId, entity.ProjectRoleEditor, true); err != nil {
+		return entity.DataSource{}, err
+	}
+	stored, err := s.find(source.ProjectId, source.Name)
+	if err != nil {
+		return entity.DataSource{}, err
+	}
+	source.KeepSecrets(stored)
+	if err = checkSecrets(source); err != nil {
+		return entity.DataSource{}, err
+	}
+	err = s.storage.DataSource.Update(&source)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.DataSource{}, fmt.Errorf("%w: data source %s", ErrNotFound, source.Name)
+	}
+	if err != nil {
+		return entity.DataSource{}, err
+	}
+	source.Redact()
+	return source, nil
+}
+
+// checkSecrets отклоняет маску вместо секрета, который не был сохранен для того же адреса
+// и способа авторизации, и пустые секреты.
+func checkSecrets(source entity.DataSource) error {
+	if source.REST == nil {
+		return nil
+	}
+	auth := source.REST.Auth
+	for _, secret := range []string{auth.Token, auth.Password, auth.Value} {
+		if secret == entity.DataSourceSecretMask {
+			return fmt.Errorf("%w: auth secret must be re-entered when url host or auth settings change", ErrInvalid)
+		}
+	}
+	if !auth.HasSecrets() {
+		return fmt.Errorf("%w: auth secret is required", ErrInvalid)
+	}
+	return nil
+}
+
+// Delete удаляет источник, на который не ссылаются экраны и компоненты проекта.
+func (s *dataSourceService) Delete(projectId, name, userId string) (err error) {
+	if _, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleEditor, true); err != nil {
+		return err
+	}
+	used, err := s.storage.DataSource.IsUsed(projectId, name)
+	if err != nil {
+		return err
+	}
+	if used {
+		return fmt.Errorf("%w: data source %s is used by screens or components", ErrConflict, name)
+	}
+	err = s.storage.DataSource.DeleteByName(projectId, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: data source %s", ErrNotFound, name)
+	}
+	return err
+}
+
+// Record выполняет запрос REST-источника и сохраняет ответ для предпросмотра.
+// Запросы идут только на публичные адреса, ответ ограничен по времени и размеру.
+func (s *dataSourceService) Record(projectId, name, userId string) (source entity.DataSource, err error) {
+	if _, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleEditor, true); err != nil {
+		return entity.DataSource{}, err
+	}
+	source, err = s.find(projectId, name)
+	if err != nil {
+		return entity.DataSource{}, err
+	}
+	if source.Kind != entity.DataSourceKindREST || source.REST == nil {
+		return entity.DataSource{}, fmt.Errorf("%w: only rest data sources can be recorded", ErrInvalid)
+	}
+	if !source.REST.Auth.HasSecrets() {
+		return entity.DataSource{}, fmt.Errorf("%w: auth secret of data source %s must be set before recording", ErrInvalid, name)
+	}
+	sample, err := s.fetch(*source.REST)
+	if err != nil {
+		s.log.Info().Err(err).Str("project_id", projectId).Str("data_source", name).Msg("data source request failed")
+		return entity.DataSource{}, fmt.Errorf("%w: data source request failed: %v", ErrInvalid, err)
+	}
+	recordedAt, err := s.storage.DataSource.SaveSample(projectId, name, sample)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.DataSource{}, fmt.Errorf("%w: data source %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return entity.DataSource{}, err
+	}
+	source.Sample, source.RecordedAt = sample, &recordedAt
+	source.Redact()
+	return source, nil
+}
+
+func (s *dataSourceService) fetch(rest entity.DataSourceREST) (sample interface{}, err error) {
+	u, err := url.Parse(rest.URL)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	for name, value := range rest.Query {
+		query.Set(name, value)
+	}
+	if rest.Auth.Type == entity.DataSourceAuthAPIKey && rest.Auth.In == entity.DataSourceKeyInQuery {
+		query.Set(rest.Auth.Name, rest.Auth.Value)
+	}
+	u.RawQuery = query.Encode()
+
+	var body io.Reader
+	if rest.Body != nil {
+		data, err := json.Marshal(rest.Body)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(rest.Method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range rest.Headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	switch rest.Auth.Type {
+	case entity.DataSourceAuthBearer:
+		req.Header.Set("Authorization", "Bearer "+rest.Auth.Token)
+	case entity.DataSourceAuthBasic:
+		req.SetBasicAuth(rest.Auth.Username, rest.Auth.Password)
+	case entity.DataSourceAuthAPIKey:
+		if rest.Auth.In == entity.DataSourceKeyInHeader {
+			req.Header.Set(rest.Auth.Name, rest.Auth.Value)
+		}
+	}
+
+	resp, err := s.client.Do(req)
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		// В URL может быть API-ключ, в ошибку он попасть не должен
+		return nil, urlErr.Err
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("responded with status %d", resp.StatusCode)
+	}
+	data, err := safehttp.ReadAll(resp.Body, entity.DataSourceDataMaxSize)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &sample); err != nil {
+		return nil, errors.New("response is not JSON")
+	}
+	return sample, nil
+}
+
+// Preview подставляет в документ экрана данные источников. Запросы к REST-источникам
+// не выполняются: данные берутся из mocks запроса, статических данных или записанного ответа.
+// Компоненты разворачиваются и токены подставляются так же, как при публикации.
+func (s *dataSourceService) Preview(projectId, screenId, userId string, request entity.BindingPreviewRequest) (preview entity.BindingPreview, err error) {
+	if err = authorizeScreen(s.storage, projectId, screenId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return entity.BindingPreview{}, err
+	}
+	widgets, settings := request.Widgets, request.Settings
+	if widgets == nil {
+		version, err := s.version(screenId, request.BranchId, request.Version)
+		if err != nil {
+			return entity.BindingPreview{}, err
+		}
+		widgets, settings = version.Widgets, version.Settings
+	} else if err = validateScreenDocument(s.storage, projectId, widgets); err != nil {
+		return entity.BindingPreview{}, err
+	}
+
+	widgets, err = newComponentResolver(s.storage, entity.ComponentScope{ProjectId: projectId}).expand(widgets, nil)
+	if err != nil {
+		return entity.BindingPreview{}, err
+	}
+	tokens, err := s.storage.DesignToken.GetByProjectId(projectId)
+	if err != nil {
+		return entity.BindingPreview{}, err
+	}
+	widgets, settings, _, tokenErrs := resolveTokens(tokens, widgets, settings)
+	if len(tokenErrs) > 0 {
+		return entity.BindingPreview{}, &ValidationError{Errors: tokenErrs}
+	}
+
+	// Данные нужны только источникам, на которые ссылается документ
+	r := &bindingRenderer{data: map[string]interface{}{}, unavailable: map[string]string{}}
+	preview.Sources = map[string]string{}
+	for _, name := range referencedSources(widgets, settings) {
+		if mock, ok := request.Mocks[name]; ok {
+			r.data[name], preview.Sources[name] = mock, entity.BindingDataMock
+			continue
+		}
+		source, err := s.storage.DataSource.GetByName(projectId, name)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			r.unavailable[name] = fmt.Sprintf("unknown data source %q", name)
+		case err != nil:
+			return entity.BindingPreview{}, err
+		case source.Kind == entity.DataSourceKindStatic:
+			r.data[name], preview.Sources[name] = source.Data, entity.BindingDataStatic
+		case source.Sample != nil:
+			r.data[name], preview.Sources[name] = source.Sample, entity.BindingDataRecorded
+		default:
+			r.unavailable[name] = fmt.Sprintf("data source %q has no mock or recorded response", name)
+		}
+	}
+
+	preview.Widgets = make(map[string]interface{}, len(widgets))
+	for _, id := range sortedDocumentIds(widgets) {
+		object, ok := widgets[id].(map[string]interface{})
+		if !ok {
+			preview.Widgets[id] = widgets[id]
+			continue
+		}
+		rendered := make(map[string]interface{}, len(object))
+		for key, value := range object {
+			rendered[key] = value
+		}
+		if props, ok := object["props"]; ok {
+			rendered["props"] = r.render(props, []string{"widgets", id, "props"})
+		}
+		preview.Widgets[id] = rendered
+	}
+	if settings != nil {
+		preview.Settings, _ = r.render(settings, []string{"settings"}).(map[string]interface{})
+	}
+	preview.Issues = r.issues
+	if preview.Issues == nil {
+		preview.Issues = []entity.BindingIssue{}
+	}
+	return preview, nil
+}
+
+func (s *dataSourceService) version(screenId, branchId string, number int) (entity.ScreenVersion, error) {
+	var branch entity.ScreenBranch
+	var err error
+	if branchId == "" {
+		branch, err = s.storage.Branch.GetByName(screenId, entity.ScreenMainBranch)
+	} else {
+		branch, err = s.storage.Branch.GetById(screenId, branchId)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ScreenVersion{}, fmt.Errorf("%w: branch", ErrNotFound)
+	}
+	if err != nil {
+		return entity.ScreenVersion{}, err
+	}
+	version, err := s.storage.Version.Get(branch.Id, number)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ScreenVersion{}, fmt.Errorf("%w: version %d", ErrNotFound, number)
+	}
+	return version, err
+}
+
+// bindingRenderer подставляет данные в выражения и собирает неразрешенные выражения.
+// unavailable - источники без данных и причина.
+type bindingRenderer struct {
+	data        map[string]interface{}
+	unavailable map[string]string
+	issues      []entity.BindingIssue
+}
+
+func (r *bindingRenderer) render(value interface{}, path []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		rendered := make(map[string]interface{}, len(v))
+		for _, key := range keys {
+			rendered[key] = r.render(v[key], append(path[:len(path):len(path)], key))
+		}
+		return rendered
+	case []interface{}:
+		rendered := make([]interface{}, len(v))
+		for i, item := range v {
+			rendered[i] = r.render(item, append(path[:len(path):len(path)], strconv.Itoa(i)))
+		}
+		return rendered
+	case string:
+		result, missing, err := entity.RenderBindingTemplate(v, r.data)
+		if err != nil {
+			r.issues = append(r.issues, entity.BindingIssue{Path: jsonpatch.FormatPointer(path), Expression: v, Message: err.Error()})
+			return v
+		}
+		for _, binding := range missing {
+			message, ok := r.unavailable[binding.Source]
+			if !ok {
+				message = "path is not found in data"
+			}
+			r.issues = append(r.issues, entity.BindingIssue{Path: jsonpatch.FormatPointer(path), Expression: binding.Expression, Message: message})
+		}
+		return result
+	}
+	return value
+}
+
+// referencedSources возвращает имена источников из корректных выражений документа.
+func referencedSources(widgets, settings map[string]interface{}) []string {
+	seen := map[string]bool{}
+	collect := func(_ []string, value string) {
+		bindings, _, _ := entity.ParseBindingTemplate(value)
+		for _, binding := range bindings {
+			seen[binding.Source] = true
+		}
+	}
+	for id, raw := range widgets {
+		collectBindingStrings(entity.ParseWidget(id, raw).Props, nil, collect)
+	}
+	collectBindingStrings(settings, nil, collect)
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// collectBindingStrings вызывает found для каждой строки внутри значения, в которой есть "{{".
+func collectBindingStrings(value interface{}, path []string, found func(path []string, value string)) {
+	walkStrings(value, path, func(path []string, value string) {
+		if strings.Contains(value, "{{") {
+			found(path, value)
+		}
+	})
+}
+
+// checkBindings проверяет выражения привязки в props виджетов: синтаксис и существование
+// источников проекта. Без проекта (компоненты воркспейса) проверяется только синтаксис.
+func checkBindings(storage *storages.Storage, projectId string, widgets map[string]interface{}) ([]jsonschema.Error, error) {
+	type reference struct {
+		pointer string
+		source  string
+	}
+	var errs []jsonschema.Error
+	var references []reference
+	for _, id := range sortedDocumentIds(widgets) {
+		collectBindingStrings(entity.ParseWidget(id, widgets[id]).Props, []string{"widgets", id, "props"}, func(path []string, value string) {
+			pointer := jsonpatch.FormatPointer(path)
+			bindings, _, err := entity.ParseBindingTemplate(value)
+			if err != nil {
+				errs = append(errs, jsonschema.Error{Path: pointer, Message: err.Error()})
+				return
+			}
+			for _, binding := range bindings {
+				references = append(references, reference{pointer: pointer, source: binding.Source})
+			}
+		})
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+	if projectId == "" || len(references) == 0 {
+		return errs, nil
+	}
+
+	names, err := storage.DataSource.GetNames(projectId)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(names))
+	for _, name := range names {
+		known[name] = true
+	}
+	for _, ref := range references {
+		if !known[ref.source] {
+			errs = append(errs, jsonschema.Error{Path: ref.pointer, Message: fmt.Sprintf("unknown data source %q", ref.source)})
+		}
+	}
+	return errs, nil
+}
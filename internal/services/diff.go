@@ -0,0 +1,145 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/rs/zerolog"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/internal/storages"
+	"ui-platform-backend-service/pkg/jsonpatch"
+)
+
+type Diff interface {
+	Diff(projectId, screenId, userId string, from, to entity.VersionRef) (diff entity.ScreenDiff, err error)
+}
+
+type diffService struct {
+	log     zerolog.Logger
+	storage *storages.Storage
+}
+
+func NewDiffService(log zerolog.Logger, storage *storages.Storage) Diff {
+	return &diffService{
+		log:     log,
+		storage: storage,
+	}
+}
+
+// Diff сравнивает две версии экрана. Пустая ветка означает Main,
+// нулевая версия - головную версию ветки.
+func (s *diffService) Diff(projectId, screenId, userId string, from, to entity.VersionRef) (diff entity.ScreenDiff, err error) {
+	if err = authorizeScreen(s.storage, projectId, screenId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return entity.ScreenDiff{}, err
+	}
+	fromVersion, err := s.resolve(screenId, from)
+	if err != nil {
+		return entity.ScreenDiff{}, err
+	}
+	toVersion, err := s.resolve(screenId, to)
+	if err != nil {
+		return entity.ScreenDiff{}, err
+	}
+	return entity.ScreenDiff{
+		From:    entity.VersionRef{BranchId: fromVersion.BranchId, Version: fromVersion.Version},
+		To:      entity.VersionRef{BranchId: toVersion.BranchId, Version: toVersion.Version},
+		Patch:   jsonpatch.Diff(versionDocument(fromVersion), versionDocument(toVersion)),
+		Summary: summarizeDiff(fromVersion, toVersion),
+	}, nil
+}
+
+func (s *diffService) resolve(screenId string, ref entity.VersionRef) (entity.ScreenVersion, error) {
+	var branch entity.ScreenBranch
+	var err error
+	if ref.BranchId == "" {
+		branch, err = s.storage.Branch.GetByName(screenId, entity.ScreenMainBranch)
+	} else {
+		branch, err = s.storage.Branch.GetById(screenId, ref.BranchId)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ScreenVersion{}, fmt.Errorf("%w: branch", ErrNotFound)
+	}
+	if err != nil {
+		return entity.ScreenVersion{}, err
+	}
+	version, err := s.storage.Version.Get(branch.Id, ref.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ScreenVersion{}, fmt.Errorf("%w: version %d", ErrNotFound, ref.Version)
+	}
+	if err != nil {
+		return entity.ScreenVersion{}, err
+	}
+	return version, nil
+}
+
+// summarizeDiff строит сводку изменений дерева виджетов между двумя версиями.
+func summarizeDiff(from, to entity.ScreenVersion) entity.DiffSummary {
+	summary := entity.DiffSummary{
+		Added:           []entity.WidgetRef{},
+		Removed:         []entity.WidgetRef{},
+		Moved:           []entity.WidgetMove{},
+		Changed:         []entity.PropertyChange{},
+		SettingsChanged: []string{},
+	}
+	fromWidgets, toWidgets := entity.ParseWidgets(from.Widgets), entity.ParseWidgets(to.Widgets)
+	fromPositions, toPositions := entity.WidgetPositions(fromWidgets), entity.WidgetPositions(toWidgets)
+
+	for _, id := range sortedWidgetIds(fromWidgets) {
+		if _, ok := toWidgets[id]; !ok {
+			summary.Removed = append(summary.Removed, entity.WidgetRef{Id: id, Type: fromWidgets[id].Type})
+		}
+	}
+	for _, id := range sortedWidgetIds(toWidgets) {
+		after := toWidgets[id]
+		before, ok := fromWidgets[id]
+		if !ok {
+			summary.Added = append(summary.Added, entity.WidgetRef{Id: id, Type: after.Type})
+			continue
+		}
+		if fromPositions[id] != toPositions[id] {
+			summary.Moved = append(summary.Moved, entity.WidgetMove{Id: id, From: fromPositions[id], To: toPositions[id]})
+		}
+		if before.Type != after.Type {
+			summary.Changed = append(summary.Changed, entity.PropertyChange{Id: id, Property: "type", From: before.Type, To: after.Type})
+		}
+		for _, key := range changedKeys(before.Props, after.Props) {
+			summary.Changed = append(summary.Changed, entity.PropertyChange{
+				Id:       id,
+				Property: key,
+				From:     before.Props[key],
+				To:       after.Props[key],
+			})
+		}
+	}
+	summary.SettingsChanged = append(summary.SettingsChanged, changedKeys(from.Settings, to.Settings)...)
+	return summary
+}
+
+func sortedWidgetIds(widgets map[string]entity.Widget) []string {
+	ids := make([]string, 0, len(widgets))
+	for id := range widgets {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// changedKeys возвращает отсортированные ключи, значения которых различаются.
+func changedKeys(from, to map[string]interface{}) []string {
+	keys := []string{}
+	for key, value := range from {
+		if other, ok := to[key]; !ok || !reflect.DeepEqual(value, other) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range to {
+		if _, ok := from[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
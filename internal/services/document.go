@@ -0,0 +1,40 @@
+package services
+
+import (
+	"ui-platform-backend-service/internal/entity"
+)
+
+// Документ экрана - JSON-объект {"widgets": ..., "settings": ...}. Над ним
+// выполняются слияние, сравнение и патчи, поэтому пути в ответах начинаются
+// с /widgets или /settings.
+
+func screenDocument(widgets, settings map[string]interface{}) map[string]interface{} {
+	if widgets == nil {
+		widgets = map[string]interface{}{}
+	}
+	if settings == nil {
+		settings = map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"widgets":  widgets,
+		"settings": settings,
+	}
+}
+
+func versionDocument(version entity.ScreenVersion) map[string]interface{} {
+	return screenDocument(version.Widgets, version.Settings)
+}
+
+// splitDocument разбирает документ экрана обратно на виджеты и настройки.
+func splitDocument(document interface{}) (widgets, settings map[string]interface{}, ok bool) {
+	root, ok := document.(map[string]interface{})
+	if !ok {
+		return nil, nil, false
+	}
+	widgets, wOk := root["widgets"].(map[string]interface{})
+	settings, sOk := root["settings"].(map[string]interface{})
+	if !wOk || !sOk || len(root) != 2 {
+		return nil, nil, false
+	}
+	return widgets, settings, true
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/pkg/jsonschema"
+)
+
+var (
+	// ErrInvalid - запрос некорректен относительно текущих данных.
+	ErrInvalid = errors.New("invalid request")
+	// ErrNotFound - запрашиваемая сущность не существует или недоступна пользователю.
+	ErrNotFound = errors.New("not found")
+	// ErrForbidden - у пользователя недостаточно прав для действия.
+	ErrForbidden = errors.New("forbidden")
+	// ErrConflict - действие противоречит текущему состоянию сущности.
+	ErrConflict = errors.New("conflict")
+	// ErrReadOnly - проект находится в архиве и доступен только для чтения.
+	ErrReadOnly = errors.New("project is archived and read-only")
+)
+
+// StaleVersionError - сохранение основано на устаревшей версии ветки: за это время
+// в ветку сохранили другую версию. Head - текущая головная версия для повторной попытки.
+type StaleVersionError struct {
+	Head entity.ScreenVersion
+}
+
+func (e *StaleVersionError) Error() string {
+	return fmt.Sprintf("%v: branch head moved to version %d", ErrConflict, e.Head.Version)
+}
+
+func (e *StaleVersionError) Unwrap() error {
+	return ErrConflict
+}
+
+// ValidationError - документ экрана не прошел проверку по схемам виджетов.
+// Errors содержит нарушения с JSON Pointer от корня документа экрана.
+type ValidationError struct {
+	Errors []jsonschema.Error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%v: screen document has %d validation errors", ErrInvalid, len(e.Errors))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalid
+}
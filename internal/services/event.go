@@ -0,0 +1,296 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/internal/storages"
+)
+
+const (
+	// eventBacklogLimit - сколько пропущенных событий досылается при продолжении чтения
+	eventBacklogLimit = 1000
+	// eventSubscriptionBuffer - очередь живых событий подписчика; не успевающий подписчик отключается
+	eventSubscriptionBuffer = 64
+	// eventAccessCheckInterval - как часто лента проекта перепроверяет доступ подписчика
+	eventAccessCheckInterval = time.Minute
+)
+
+type Event interface {
+	StreamUser(userId, lastEventId string) (subscription *EventSubscription, err error)
+	StreamProject(projectId, userId, lastEventId string) (subscription *EventSubscription, err error)
+}
+
+// eventService раздает ленты событий подписчикам этого экземпляра. События пишутся
+// в потоки Redis (publishEvent), а живые оповещения приходят через одну подписку
+// на экземпляр и расходятся по подписчикам потоков.
+type eventService struct {
+	log     zerolog.Logger
+	storage *storages.Storage
+
+	mu          sync.Mutex
+	listening   bool
+	subscribers map[string]map[*EventSubscription]bool
+}
+
+func NewEventService(log zerolog.Logger, storage *storages.Storage) Event {
+	return &eventService{
+		log:         log,
+		storage:     storage,
+		subscribers: map[string]map[*EventSubscription]bool{},
+	}
+}
+
+// StreamUser открывает ленту событий всех проектов пользователя.
+func (s *eventService) StreamUser(userId, lastEventId string) (subscription *EventSubscription, err error) {
+	return s.subscribe(entity.EventUserStream(userId), lastEventId, nil)
+}
+
+// StreamProject открывает ленту событий проекта. Доступ перепроверяется периодически
+// и после событий, которые могут его отнять; без доступа лента закрывается.
+func (s *eventService) StreamProject(projectId, userId, lastEventId string) (subscription *EventSubscription, err error) {
+	authorize := func() error {
+		_, err := authorizeProject(s.storage, projectId, userId, entity.ProjectRoleViewer)
+		return err
+	}
+	if err = authorize(); err != nil {
+		return nil, err
+	}
+	return s.subscribe(entity.EventProjectStream(projectId), lastEventId, authorize)
+}
+
+// subscribe регистрирует подписчика потока и досылает события после lastEventId.
+// Подписчик регистрируется до чтения пропущенных событий, поэтому на стыке
+// ничего не теряется, а повторы отбрасываются по id. authorize, если задан,
+// перепроверяет доступ к потоку.
+func (s *eventService) subscribe(stream, lastEventId string, authorize func() error) (*EventSubscription, error) {
+	subscription := &EventSubscription{
+		service:   s,
+		stream:    stream,
+		authorize: authorize,
+		live:      make(chan entity.Event, eventSubscriptionBuffer),
+		out:       make(chan entity.Event),
+		done:      make(chan struct{}),
+	}
+	if err := s.register(subscription); err != nil {
+		return nil, err
+	}
+
+	var backlog []entity.Event
+	if lastEventId != "" {
+		events, trimmed, err := s.storage.Event.GetAfter(stream, lastEventId, eventBacklogLimit)
+		if err != nil {
+			subscription.Close()
+			return nil, err
+		}
+		backlog = events
+		if trimmed {
+			backlog = []entity.Event{{Type: entity.EventStreamReset, CreatedAt: time.Now().UTC()}}
+		}
+	}
+	go subscription.pump(backlog)
+	return subscription, nil
+}
+
+func (s *eventService) register(subscription *EventSubscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.listening {
+		events, unsubscribe, err := s.storage.Event.Subscribe()
+		if err != nil {
+			return err
+		}
+		s.listening = true
+		go s.listen(events, unsubscribe)
+	}
+	if s.subscribers[subscription.stream] == nil {
+		s.subscribers[subscription.stream] = map[*EventSubscription]bool{}
+	}
+	s.subscribers[subscription.stream][subscription] = true
+	return nil
+}
+
+func (s *eventService) unregister(subscription *EventSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drop(subscription)
+}
+
+// drop убирает подписчика и закрывает его очередь. Вызывается под s.mu.
+func (s *eventService) drop(subscription *EventSubscription) {
+	subscribers := s.subscribers[subscription.stream]
+	if !subscribers[subscription] {
+		return
+	}
+	delete(subscribers, subscription)
+	if len(subscribers) == 0 {
+		delete(s.subscribers, subscription.stream)
+	}
+	close(subscription.live)
+}
+
+// listen раздает оповещения подписчикам потоков. Если подписка Redis оборвалась,
+// подписчики отключаются и переподключатся с Last-Event-ID.
+func (s *eventService) listen(events <-chan entity.Event, unsubscribe func() error) {
+	for event := range events {
+		s.mu.Lock()
+		for subscription := range s.subscribers[event.Stream] {
+			select {
+			case subscription.live <- event:
+			default:
+				s.log.Warn().Str("stream", event.Stream).Msg("event subscriber is too slow, disconnecting")
+				s.drop(subscription)
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	s.log.Error().Msg("event subscription closed")
+	_ = unsubscribe()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listening = false
+	for _, subscribers := range s.subscribers {
+		for subscription := range subscribers {
+			s.drop(subscription)
+		}
+	}
+}
+
+// EventSubscription - подписка на ленту событий. События читаются из Events;
+// канал закрывается, если подписчик отключен сервисом. После чтения нужно вызвать Close.
+type EventSubscription struct {
+	service   *eventService
+	stream    string
+	authorize func() error
+	live      chan entity.Event
+	out       chan entity.Event
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *EventSubscription) Events() <-chan entity.Event {
+	return s.out
+}
+
+func (s *EventSubscription) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.service.unregister(s)
+	})
+}
+
+// pump отдает сначала пропущенные события, затем живые, пропуская уже отданные.
+// Если подписчик потерял доступ к потоку, лента закрывается.
+func (s *EventSubscription) pump(backlog []entity.Event) {
+	defer close(s.out)
+
+	var check <-chan time.Time
+	if s.authorize != nil {
+		ticker := time.NewTicker(eventAccessCheckInterval)
+		defer ticker.Stop()
+		check = ticker.C
+	}
+
+	lastId := ""
+	for _, event := range backlog {
+		select {
+		case s.out <- event:
+		case <-s.done:
+			return
+		}
+		if event.Id != "" {
+			lastId = event.Id
+		}
+	}
+	for {
+		select {
+		case event, ok := <-s.live:
+			if !ok {
+				return
+			}
+			if lastId != "" && !entity.EventIdLess(lastId, event.Id) {
+				continue
+			}
+			select {
+			case s.out <- event:
+			case <-s.done:
+				return
+			}
+			lastId = event.Id
+			if revokesAccess(event.Type) && !s.allowed() {
+				return
+			}
+		case <-check:
+			if !s.allowed() {
+				return
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// allowed перепроверяет доступ. Если проверить не удалось, лента остается открытой
+// до следующей проверки.
+func (s *EventSubscription) allowed() bool {
+	if s.authorize == nil {
+		return true
+	}
+	err := s.authorize()
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrForbidden) {
+		return false
+	}
+	if err != nil {
+		s.service.log.Warn().Err(err).Str("stream", s.stream).Msg("error checking event stream access")
+	}
+	return true
+}
+
+// revokesAccess - события, после которых подписчик мог потерять доступ к ленте проекта.
+func revokesAccess(eventType string) bool {
+	return eventType == entity.EventMemberRemoved || eventType == entity.EventProjectDeleted
+}
+
+// publishEvent записывает событие проекта в ленту проекта и в ленты всех пользователей
+// с доступом к нему. Ошибки только логируются: лента не должна ломать основное действие.
+func publishEvent(log zerolog.Logger, storage *storages.Storage, event entity.Event) {
+	userIds, err := storage.Event.GetProjectAudience(event.ProjectId)
+	if err != nil {
+		log.Warn().Err(err).Str("project_id", event.ProjectId).Msg("error loading event audience")
+		return
+	}
+	streams := []string{entity.EventProjectStream(event.ProjectId)}
+	for _, userId := range userIds {
+		streams = append(streams, entity.EventUserStream(userId))
+	}
+	appendEvent(log, storage, streams, event)
+}
+
+// publishWorkspaceEvent записывает событие воркспейса в ленты его проектов и их пользователей.
+func publishWorkspaceEvent(log zerolog.Logger, storage *storages.Storage, event entity.Event) {
+	projectIds, userIds, err := storage.Event.GetWorkspaceAudience(event.WorkspaceId)
+	if err != nil {
+		log.Warn().Err(err).Str("workspace_id", event.WorkspaceId).Msg("error loading event audience")
+		return
+	}
+	streams := make([]string, 0, len(projectIds)+len(userIds))
+	for _, projectId := range projectIds {
+		streams = append(streams, entity.EventProjectStream(projectId))
+	}
+	for _, userId := range userIds {
+		streams = append(streams, entity.EventUserStream(userId))
+	}
+	appendEvent(log, storage, streams, event)
+}
+
+func appendEvent(log zerolog.Logger, storage *storages.Storage, streams []string, event entity.Event) {
+	event.CreatedAt = time.Now().UTC()
+	if err := storage.Event.Append(streams, event); err != nil {
+		log.Warn().Err(err).Str("type", event.Type).Msg("error publishing event")
+	}
+}
@@ -0,0 +1,477 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/internal/storages"
+)
+
+type Localization interface {
+	GetLocales(projectId, userId string) (locales []entity.Locale, err error)
+	CreateLocale(locale entity.Locale, userId string) (created entity.Locale, err error)
+	SetDefaultLocale(projectId, code, userId string) (err error)
+	DeleteLocale(projectId, code, userId string) (err error)
+	GetTranslations(projectId, userId string) (translations entity.Translations, err error)
+	UpdateTranslations(projectId, userId string, update entity.TranslationsUpdate) (translations entity.Translations, err error)
+	GetReport(projectId, userId string) (report entity.TranslationReport, err error)
+	Export(projectId, userId, format, locale string) (document []byte, err error)
+	Import(projectId, userId, format, locale string, document []byte) (imported int, err error)
+}
+
+type localizationService struct {
+	log     zerolog.Logger
+	storage *storages.Storage
+}
+
+func NewLocalizationService(log zerolog.Logger, storage *storages.Storage) Localization {
+	return &localizationService{
+		log:     log,
+		storage: storage,
+	}
+}
+
+func (s *localizationService) GetLocales(projectId, userId string) (locales []entity.Locale, err error) {
+	if _, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return nil, err
+	}
+	locales, err = s.storage.Localization.GetLocales(projectId)
+	if err != nil {
+		return nil, err
+	}
+	if locales == nil {
+		return []entity.Locale{}, nil
+	}
+	return locales, nil
+}
+
+// CreateLocale добавляет локаль проекта. Первая локаль становится локалью по умолчанию.
+func (s *localizationService) CreateLocale(locale entity.Locale, userId string) (created entity.Locale, err error) {
+	if _, err = authorizeActiveProject(s.storage, locale.ProjectId, userId, entity.ProjectRoleAdmin, true); err != nil {
+		return entity.Locale{}, err
+	}
+	err = s.storage.Localization.CreateLocale(&locale)
+	if errors.Is(err, storages.ErrAlreadyExists) {
+		return entity.Locale{}, fmt.Errorf("%w: locale %s already exists", ErrConflict, locale.Code)
+	}
+	if err != nil {
+		return entity.Locale{}, err
+	}
+	invalidateRuntime(s.log, s.storage, locale.ProjectId)
+	return locale, nil
+}
+
+func (s *localizationService) SetDefaultLocale(projectId, code, userId string) (err error) {
+	if _, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleAdmin, true); err != nil {
+		return err
+	}
+	err = s.storage.Localization.SetDefaultLocale(projectId, entity.NormalizeLocale(code))
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: locale %s", ErrNotFound, code)
+	}
+	if err != nil {
+		return err
+	}
+	invalidateRuntime(s.log, s.storage, projectId)
+	return nil
+}
+
+// DeleteLocale удаляет локаль и ее переводы. Локаль по умолчанию удаляется только последней.
+func (s *localizationService) DeleteLocale(projectId, code, userId string) (err error) {
+	if _, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleAdmin, true); err != nil {
+		return err
+	}
+	code = entity.NormalizeLocale(code)
+	locales, err := s.storage.Localization.GetLocales(projectId)
+	if err != nil {
+		return err
+	}
+	for _, locale := range locales {
+		if locale.Code == code && locale.IsDefault && len(locales) > 1 {
+			return fmt.Errorf("%w: choose another default locale before deleting %s", ErrConflict, code)
+		}
+	}
+	err = s.storage.Localization.DeleteLocale(projectId, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: locale %s", ErrNotFound, code)
+	}
+	if err != nil {
+		return err
+	}
+	invalidateRuntime(s.log, s.storage, projectId)
+	return nil
+}
+
+func (s *localizationService) GetTranslations(projectId, userId string) (translations entity.Translations, err error) {
+	if _, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return nil, err
+	}
+	return s.storage.Localization.GetTranslations(projectId)
+}
+
+// UpdateTranslations записывает и удаляет переводы; локали должны быть добавлены в проект.
+func (s *localizationService) UpdateTranslations(projectId, userId string, update entity.TranslationsUpdate) (translations entity.Translations, err error) {
+	if _, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleEditor, true); err != nil {
+		return nil, err
+	}
+	if err = s.save(projectId, userId, update.Translations); err != nil {
+		return nil, err
+	}
+	return s.storage.Localization.GetTranslations(projectId)
+}
+
+func (s *localizationService) save(projectId, userId string, values map[string]map[string]*string) error {
+	locales, err := s.locales(projectId)
+	if err != nil {
+		return err
+	}
+	for key, byLocale := range values {
+		for locale := range byLocale {
+			if !locales[locale] {
+				return fmt.Errorf("%w: key %s: locale %s is not added to the project", ErrInvalid, key, locale)
+			}
+		}
+	}
+	if err = s.storage.Localization.SaveTranslations(projectId, userId, values); err != nil {
+		return err
+	}
+	invalidateRuntime(s.log, s.storage, projectId)
+	return nil
+}
+
+func (s *localizationService) locales(projectId string) (map[string]bool, error) {
+	locales, err := s.storage.Localization.GetLocales(projectId)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(locales))
+	for _, locale := range locales {
+		known[locale.Code] = true
+	}
+	return known, nil
+}
+
+// GetReport сравнивает ключи, на которые ссылаются головные версии Main экранов и последние
+// версии компонентов проекта, с переводами каждой локали.
+func (s *localizationService) GetReport(projectId, userId string) (report entity.TranslationReport, err error) {
+	translations, err := s.GetTranslations(projectId, userId)
+	if err != nil {
+		return entity.TranslationReport{}, err
+	}
+	locales, err := s.storage.Localization.GetLocales(projectId)
+	if err != nil {
+		return entity.TranslationReport{}, err
+	}
+	usages, err := s.usages(projectId)
+	if err != nil {
+		return entity.TranslationReport{}, err
+	}
+	keys := make([]string, 0, len(usages))
+	for key := range usages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	report = entity.TranslationReport{Locales: make([]entity.LocaleCoverage, 0, len(locales)), Unused: []string{}}
+	for _, locale := range locales {
+		coverage := entity.LocaleCoverage{Locale: locale.Code, IsDefault: locale.IsDefault, Total: len(keys), Missing: []entity.MissingTranslation{}}
+		for _, key := range keys {
+			if _, ok := translations[key][locale.Code]; ok {
+				coverage.Translated++
+			} else {
+				coverage.Missing = append(coverage.Missing, entity.MissingTranslation{Key: key, Usages: usages[key]})
+			}
+		}
+		report.Locales = append(report.Locales, coverage)
+	}
+	for key := range translations {
+		if _, used := usages[key]; !used {
+			report.Unused = append(report.Unused, key)
+		}
+	}
+	sort.Strings(report.Unused)
+	return report, nil
+}
+
+// usages собирает ссылки на ключи по виджетам экранов и компонентов проекта.
+func (s *localizationService) usages(projectId string) (map[string][]entity.TranslationUsage, error) {
+	usages := map[string][]entity.TranslationUsage{}
+	screens, err := s.storage.Screen.GetWidgetsByProjectId(projectId)
+	if err != nil {
+		return nil, err
+	}
+	for _, screen := range screens {
+		for _, id := range sortedDocumentIds(screen.Widgets) {
+			collectTranslationRefs(screen.Widgets[id], func(key string) {
+				usages[key] = append(usages[key], entity.TranslationUsage{ScreenId: screen.Id, ScreenName: screen.Name, WidgetId: id})
+			})
+		}
+	}
+
+	components, err := s.storage.Component.GetAllByScope(projectId, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, component := range components {
+		version, err := s.storage.Component.GetVersion(component.Id, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range sortedDocumentIds(version.Widgets) {
+			collectTranslationRefs(version.Widgets[id], func(key string) {
+				usages[key] = append(usages[key], entity.TranslationUsage{ComponentId: component.Id, ComponentName: component.Name, WidgetId: id})
+			})
+		}
+	}
+	return usages, nil
+}
+
+// collectTranslationRefs вызывает found для каждой ссылки на ключ внутри значения.
+func collectTranslationRefs(value interface{}, found func(key string)) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, item := range v {
+			collectTranslationRefs(item, found)
+		}
+	case []interface{}:
+		for _, item := range v {
+			collectTranslationRefs(item, found)
+		}
+	default:
+		if key, ok := entity.ParseTranslationRef(value); ok {
+			found(key)
+		}
+	}
+}
+
+// Export выгружает переводы локали. JSON - плоский объект "ключ": "текст" с имеющимися
+// переводами; XLIFF - все известные ключи с текстом локали по умолчанию в source.
+func (s *localizationService) Export(projectId, userId, format, locale string) (document []byte, err error) {
+	translations, err := s.GetTranslations(projectId, userId)
+	if err != nil {
+		return nil, err
+	}
+	locales, err := s.storage.Localization.GetLocales(projectId)
+	if err != nil {
+		return nil, err
+	}
+	locale = entity.NormalizeLocale(locale)
+	var defaultLocale string
+	found := false
+	for _, l := range locales {
+		found = found || l.Code == locale
+		if l.IsDefault {
+			defaultLocale = l.Code
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: locale %s is not added to the project", ErrInvalid, locale)
+	}
+
+	switch format {
+	case entity.TranslationsFormatJSON:
+		values := map[string]string{}
+		for key, byLocale := range translations {
+			if value, ok := byLocale[locale]; ok {
+				values[key] = value
+			}
+		}
+		return json.MarshalIndent(values, "", "  ")
+	case entity.TranslationsFormatXLIFF:
+		usages, err := s.usages(projectId)
+		if err != nil {
+			return nil, err
+		}
+		return exportXLIFF(projectId, translations, usages, defaultLocale, locale)
+	}
+	return nil, fmt.Errorf("%w: format must be %s or %s", ErrInvalid, entity.TranslationsFormatJSON, entity.TranslationsFormatXLIFF)
+}
+
+// Import записывает переводы из файла. Для JSON локаль задается параметром, вложенные
+// объекты превращаются в ключи через точку; для XLIFF по умолчанию берется target-language.
+// Пустые тексты пропускаются.
+func (s *localizationService) Import(projectId, userId, format, locale string, document []byte) (imported int, err error) {
+	if _, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleEditor, true); err != nil {
+		return 0, err
+	}
+	var values map[string]map[string]*string
+	switch format {
+	case entity.TranslationsFormatJSON:
+		if locale == "" {
+			return 0, fmt.Errorf("%w: locale is required for json import", ErrInvalid)
+		}
+		values, err = importJSON(document, entity.NormalizeLocale(locale))
+	case entity.TranslationsFormatXLIFF:
+		values, err = importXLIFF(document, entity.NormalizeLocale(locale))
+	default:
+		return 0, fmt.Errorf("%w: format must be %s or %s", ErrInvalid, entity.TranslationsFormatJSON, entity.TranslationsFormatXLIFF)
+	}
+	if err != nil {
+		return 0, err
+	}
+	for key, byLocale := range values {
+		if err = entity.ValidateTranslationKey(key); err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		for _, value := range byLocale {
+			if len(*value) > entity.TranslationMaxLength {
+				return 0, fmt.Errorf("%w: key %s: translation must be less than %d characters", ErrInvalid, key, entity.TranslationMaxLength)
+			}
+			imported++
+		}
+	}
+	if imported == 0 {
+		return 0, nil
+	}
+	if err = s.save(projectId, userId, values); err != nil {
+		return 0, err
+	}
+	s.log.Info().Str("project_id", projectId).Str("format", format).Int("count", imported).Msg("translations imported")
+	return imported, nil
+}
+
+func importJSON(document []byte, locale string) (map[string]map[string]*string, error) {
+	var root map[string]interface{}
+	if err := json.Unmarshal(document, &root); err != nil {
+		return nil, fmt.Errorf("%w: invalid json: %v", ErrInvalid, err)
+	}
+	values := map[string]map[string]*string{}
+	var walk func(prefix string, object map[string]interface{}) error
+	walk = func(prefix string, object map[string]interface{}) error {
+		for name, value := range object {
+			key := prefix + name
+			switch v := value.(type) {
+			case string:
+				if v != "" {
+					text := v
+					values[key] = map[string]*string{locale: &text}
+				}
+			case map[string]interface{}:
+				if err := walk(key+".", v); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("%w: key %s: translation must be a string", ErrInvalid, key)
+			}
+		}
+		return nil
+	}
+	if err := walk("", root); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+const xliffNamespace = "urn:oasis:names:tc:xliff:document:1.2"
+
+type xliffDocument struct {
+	XMLName xml.Name    `xml:"xliff"`
+	Xmlns   string      `xml:"xmlns,attr,omitempty"`
+	Version string      `xml:"version,attr"`
+	Files   []xliffFile `xml:"file"`
+}
+
+type xliffFile struct {
+	Original       string      `xml:"original,attr"`
+	SourceLanguage string      `xml:"source-language,attr"`
+	TargetLanguage string      `xml:"target-language,attr,omitempty"`
+	Datatype       string      `xml:"datatype,attr"`
+	Units          []xliffUnit `xml:"body>trans-unit"`
+}
+
+type xliffUnit struct {
+	Id     string  `xml:"id,attr"`
+	Source string  `xml:"source"`
+	Target *string `xml:"target"`
+	Note   string  `xml:"note,omitempty"`
+}
+
+// exportXLIFF строит XLIFF 1.2 для перевода с локали по умолчанию на locale. В note
+// перечисляются экраны и компоненты, где используется ключ.
+func exportXLIFF(projectId string, translations entity.Translations, usages map[string][]entity.TranslationUsage, defaultLocale, locale string) ([]byte, error) {
+	keySet := map[string]bool{}
+	for key := range translations {
+		keySet[key] = true
+	}
+	for key := range usages {
+		keySet[key] = true
+	}
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	file := xliffFile{Original: projectId, SourceLanguage: defaultLocale, TargetLanguage: locale, Datatype: "plaintext", Units: []xliffUnit{}}
+	for _, key := range keys {
+		unit := xliffUnit{Id: key, Source: key}
+		if source, ok := translations[key][defaultLocale]; ok {
+			unit.Source = source
+		}
+		if target, ok := translations[key][locale]; ok {
+			unit.Target = &target
+		}
+		var places []string
+		for _, usage := range usages[key] {
+			if usage.ScreenId != "" {
+				places = append(places, "screen "+usage.ScreenName+" / "+usage.WidgetId)
+			} else {
+				places = append(places, "component "+usage.ComponentName+" / "+usage.WidgetId)
+			}
+		}
+		if len(places) > 0 {
+			unit.Note = fmt.Sprintf("Used in: %s", joinLimited(places, 10))
+		}
+		file.Units = append(file.Units, unit)
+	}
+
+	body, err := xml.MarshalIndent(xliffDocument{Xmlns: xliffNamespace, Version: "1.2", Files: []xliffFile{file}}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+func importXLIFF(document []byte, locale string) (map[string]map[string]*string, error) {
+	var doc xliffDocument
+	if err := xml.Unmarshal(document, &doc); err != nil {
+		return nil, fmt.Errorf("%w: invalid xliff: %v", ErrInvalid, err)
+	}
+	if doc.Version != "1.2" {
+		return nil, fmt.Errorf("%w: only xliff 1.2 is supported", ErrInvalid)
+	}
+	values := map[string]map[string]*string{}
+	for _, file := range doc.Files {
+		target := locale
+		if target == "" {
+			target = entity.NormalizeLocale(file.TargetLanguage)
+		}
+		if target == "" {
+			return nil, fmt.Errorf("%w: xliff file %s has no target-language", ErrInvalid, file.Original)
+		}
+		for _, unit := range file.Units {
+			if unit.Target == nil || *unit.Target == "" {
+				continue
+			}
+			if values[unit.Id] == nil {
+				values[unit.Id] = map[string]*string{}
+			}
+			values[unit.Id][target] = unit.Target
+		}
+	}
+	return values, nil
+}
+
+func joinLimited(items []string, limit int) string {
+	if len(items) <= limit {
+		return strings.Join(items, "; ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(items[:limit], "; "), len(items)-limit)
+}
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/rs/zerolog"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/internal/storages"
+	"ui-platform-backend-service/pkg/jsonmerge"
+	"ui-platform-backend-service/pkg/jsonpatch"
+)
+
+type Merge interface {
+	Merge(projectId, screenId, targetBranchId, userId string, request entity.MergeRequest) (result entity.MergeResult, err error)
+}
+
+type mergeService struct {
+	log     zerolog.Logger
+	storage *storages.Storage
+}
+
+func NewMergeService(log zerolog.Logger, storage *storages.Storage) Merge {
+	return &mergeService{
+		log:     log,
+		storage: storage,
+	}
+}
+
+// Merge выполняет трехстороннее слияние ветки-источника в целевую ветку.
+// Если после применения решений клиента конфликтов не осталось (и это не dry run),
+// результат сохраняется новой версией целевой ветки. Сливать в Main напрямую могут
+// только администраторы, остальные открывают запрос на изменение.
+func (s *mergeService) Merge(projectId, screenId, targetBranchId, userId string, request entity.MergeRequest) (result entity.MergeResult, err error) {
+	target, err := authorizeBranch(s.storage, projectId, screenId, targetBranchId, userId, entity.ProjectRoleEditor, true)
+	if err != nil {
+		return entity.MergeResult{}, err
+	}
+	if target.Name == entity.ScreenMainBranch && !request.DryRun {
+		_, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleAdmin, true)
+		if errors.Is(err, ErrForbidden) {
+			return entity.MergeResult{}, fmt.Errorf("%w: merging into %s requires admin role, open a change request", ErrForbidden, entity.ScreenMainBranch)
+		}
+		if err != nil {
+			return entity.MergeResult{}, err
+		}
+	}
+	return s.merge(projectId, target, userId, request)
+}
+
+// merge сливает источник в целевую ветку без проверки прав.
+func (s *mergeService) merge(projectId string, target entity.ScreenBranch, userId string, request entity.MergeRequest) (result entity.MergeResult, err error) {
+	screenId := target.ScreenId
+	source, err := s.storage.Branch.GetById(screenId, request.SourceBranchId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.MergeResult{}, fmt.Errorf("%w: source branch", ErrNotFound)
+	}
+	if err != nil {
+		return entity.MergeResult{}, err
+	}
+	if source.Id == target.Id {
+		return entity.MergeResult{}, fmt.Errorf("%w: cannot merge a branch into itself", ErrInvalid)
+	}
+
+	// Загружаем версии сторон
+	targetHead, err := s.storage.Version.Get(target.Id, 0)
+	if err != nil {
+		return entity.MergeResult{}, err
+	}
+	if request.TargetVersion != 0 && request.TargetVersion != targetHead.Version {
+		return entity.MergeResult{}, fmt.Errorf("%w: target branch moved to version %d", ErrConflict, targetHead.Version)
+	}
+	sourceVersion, err := s.storage.Version.Get(source.Id, request.SourceVersion)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.MergeResult{}, fmt.Errorf("%w: source version %d", ErrNotFound, request.SourceVersion)
+	}
+	if err != nil {
+		return entity.MergeResult{}, err
+	}
+	base, baseRef, err := s.findBase(target, source, targetHead.Version, sourceVersion.Version)
+	if err != nil {
+		return entity.MergeResult{}, err
+	}
+
+	// Сливаем и применяем решения конфликтов
+	merged, conflicts := jsonmerge.ThreeWay(base, versionDocument(targetHead), versionDocument(sourceVersion))
+	merged, conflicts, err = applyResolutions(merged, conflicts, request.Resolutions)
+	if err != nil {
+		return entity.MergeResult{}, err
+	}
+	widgets, settings, ok := splitDocument(merged)
+	if !ok {
+		return entity.MergeResult{}, fmt.Errorf("%w: resolutions must keep widgets and settings objects", ErrInvalid)
+	}
+
+	result = entity.MergeResult{
+		Base:      baseRef,
+		Source:    entity.VersionRef{BranchId: source.Id, Version: sourceVersion.Version},
+		Target:    entity.VersionRef{BranchId: target.Id, Version: targetHead.Version},
+		Conflicts: conflicts,
+		Widgets:   widgets,
+		Settings:  settings,
+	}
+	if result.Conflicts == nil {
+		result.Conflicts = []jsonmerge.Conflict{}
+	}
+	if len(conflicts) > 0 || request.DryRun {
+		return result, nil
+	}
+
+	// Сохраняем результат слияния новой версией целевой ветки
+	message := request.Message
+	if message == "" {
+		message = fmt.Sprintf("Merge branch %s into %s", source.Name, target.Name)
+	}
+	version := entity.ScreenVersion{
+		BranchId:            target.Id,
+		Widgets:             widgets,
+		Settings:            settings,
+		AuthorId:            userId,
+		Message:             message,
+		MergeSourceBranchId: source.Id,
+		MergeSourceVersion:  sourceVersion.Version,
+		// Результат слияния посчитан от головы целевой ветки и ложится только поверх нее
+		Version: targetHead.Version + 1,
+	}
+	if err = createVersion(s.storage, projectId, &version); err != nil {
+		return entity.MergeResult{}, err
+	}
+	s.log.Info().Str("source", source.Id).Str("target", target.Id).Int("version", version.Version).Msg("branches merged")
+	publishEvent(s.log, s.storage, entity.Event{
+		Type: entity.EventBranchMerged, ProjectId: projectId, ActorId: userId,
+		Data: map[string]interface{}{
+			"screen_id":        screenId,
+			"source_branch_id": source.Id,
+			"target_branch_id": target.Id,
+			"version":          version.Version,
+		},
+	})
+
+	result.Merged = true
+	result.Version = &version
+	result.Widgets, result.Settings = nil, nil
+	return result, nil
+}
+
+// findBase находит общего предка версий веток. Приоритет: последнее слияние между ветками
+// (в любую сторону), затем ближайшая общая точка цепочек ответвления (findForkBase).
+// Если общий предок не найден, предком считается пустой документ.
+func (s *mergeService) findBase(target, source entity.ScreenBranch, targetVersion, sourceVersion int) (interface{}, entity.VersionRef, error) {
+	var ref entity.VersionRef
+
+	intoTarget, err := s.storage.Version.GetLastMerge(target.Id, source.Id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, ref, err
+	}
+	intoSource, err := s.storage.Version.GetLastMerge(source.Id, target.Id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, ref, err
+	}
+
+	switch {
+	case intoTarget.Id != "" && (intoSource.Id == "" || intoTarget.CreatedAt.After(intoSource.CreatedAt)):
+		// Цель уже содержит источник на момент прошлого слияния
+		ref = entity.VersionRef{BranchId: source.Id, Version: intoTarget.MergeSourceVersion}
+	case intoSource.Id != "":
+		// Источник уже содержит цель на момент прошлого слияния
+		ref = entity.VersionRef{BranchId: target.Id, Version: intoSource.MergeSourceVersion}
+	default:
+		return s.findForkBase(target.Id, source.Id, targetVersion, sourceVersion)
+	}
+
+	base, err := s.storage.Version.Get(ref.BranchId, ref.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return screenDocument(nil, nil), entity.VersionRef{}, nil
+	}
+	if err != nil {
+		return nil, ref, err
+	}
+	return versionDocument(base), ref, nil
+}
+
+// findForkBase находит ближайшую ветку, общую для цепочек ответвления обеих сторон,
+// и берет ее в более ранней из двух точек. Если эта ветка удалена, ее версия
+// в точке ответвления берется из первой версии ветки, которая ответвилась от нее в этой точке.
+func (s *mergeService) findForkBase(targetId, sourceId string, targetVersion, sourceVersion int) (interface{}, entity.VersionRef, error) {
+	targetChain, err := s.forkChain(targetId, targetVersion)
+	if err != nil {
+		return nil, entity.VersionRef{}, err
+	}
+	sourceChain, err := s.forkChain(sourceId, sourceVersion)
+	if err != nil {
+		return nil, entity.VersionRef{}, err
+	}
+
+	for i, ours := range targetChain {
+		for j, theirs := range sourceChain {
+			if ours.BranchId != theirs.BranchId {
+				continue
+			}
+			ref := entity.VersionRef{BranchId: ours.BranchId, Version: min(ours.Version, theirs.Version)}
+			candidates := []entity.VersionRef{ref}
+			if i > 0 && ours.Version == ref.Version {
+				candidates = append(candidates, entity.VersionRef{BranchId: targetChain[i-1].BranchId, Version: 1})
+			}
+			if j > 0 && theirs.Version == ref.Version {
+				candidates = append(candidates, entity.VersionRef{BranchId: sourceChain[j-1].BranchId, Version: 1})
+			}
+			for _, candidate := range candidates {
+				base, err := s.storage.Version.Get(candidate.BranchId, candidate.Version)
+				if errors.Is(err, sql.ErrNoRows) {
+					continue
+				}
+				if err != nil {
+					return nil, ref, err
+				}
+				return versionDocument(base), ref, nil
+			}
+			// Предок и ответвившиеся от него в этой точке ветки удалены
+			return screenDocument(nil, nil), entity.VersionRef{}, nil
+		}
+	}
+	return screenDocument(nil, nil), entity.VersionRef{}, nil
+}
+
+// forkChain возвращает версию ветки и точки ответвления от ее родителя до корня.
+func (s *mergeService) forkChain(branchId string, version int) ([]entity.VersionRef, error) {
+	ancestry, err := s.storage.Branch.GetAncestry(branchId)
+	if err != nil {
+		return nil, err
+	}
+	return append([]entity.VersionRef{{BranchId: branchId, Version: version}}, ancestry...), nil
+}
+
+// applyResolutions применяет решения клиента к результату слияния и возвращает
+// конфликты, оставшиеся без решения. Решение для пути без конфликта - ошибка запроса.
+func applyResolutions(merged interface{}, conflicts []jsonmerge.Conflict, resolutions []entity.MergeResolution) (interface{}, []jsonmerge.Conflict, error) {
+	byPath := make(map[string]jsonmerge.Conflict, len(conflicts))
+	for _, conflict := range conflicts {
+		byPath[conflict.Path] = conflict
+	}
+
+	// Применяем от коротких путей к длинным, чтобы порядок решений не влиял на результат
+	sorted := append([]entity.MergeResolution(nil), resolutions...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+
+	var err error
+	for _, resolution := range sorted {
+		conflict, ok := byPath[resolution.Path]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: no conflict at path %s", ErrInvalid, resolution.Path)
+		}
+		switch {
+		case resolution.Take == entity.MergeTakeTarget:
+			// В результате уже значение целевой ветки
+		case resolution.Take == entity.MergeTakeDelete,
+			resolution.Take == entity.MergeTakeSource && conflict.TheirsDeleted:
+			merged, err = jsonpatch.Remove(merged, conflict.Path)
+			if errors.Is(err, jsonpatch.ErrPathNotFound) {
+				err = nil
+			}
+		case resolution.Take == entity.MergeTakeSource:
+			merged, err = jsonpatch.Set(merged, conflict.Path, conflict.Theirs)
+		default:
+			merged, err = jsonpatch.Set(merged, conflict.Path, resolution.Value)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: cannot apply resolution at %s: %v", ErrInvalid, resolution.Path, err)
+		}
+		delete(byPath, resolution.Path)
+	}
+
+	remaining := make([]jsonmerge.Conflict, 0, len(byPath))
+	for _, conflict := range conflicts {
+		if _, ok := byPath[conflict.Path]; ok {
+			remaining = append(remaining, conflict)
+		}
+	}
+	return merged, remaining, nil
+}
@@ -1,6 +1,12 @@
 package services
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 	"ui-platform-backend-service/internal/entity"
 	"ui-platform-backend-service/internal/storages"
@@ -9,46 +15,310 @@ import (
 
 type Project interface {
 	Create(project entity.Project, ownerId string) (projectId string, err error)
-	GetAllByUserId(userId string) (projects []entity.Project, err error)
+	GetAllByUserId(userId string, filter entity.ProjectFilter) (list entity.ProjectList, err error)
 	UpdateById(project entity.Project) (err error)
-	DeleteById(projectId string) (err error)
+	DeleteById(projectId, userId string) (err error)
+	GetTrash(userId string) (projects []entity.Project, err error)
+	Restore(projectId, userId string) (err error)
+	Purge(projectId, userId string) (err error)
+	PurgeExpired() (purged int, err error)
+	ChangeStatus(projectId, userId, action string) (project entity.Project, err error)
+	Duplicate(projectId, userId, name string) (newProjectId string, err error)
+	SetTemplate(projectId, userId string, isTemplate bool) (err error)
+	GetTemplates() (templates []entity.Project, err error)
+	Instantiate(templateId, userId, name string) (newProjectId string, err error)
+	MoveToWorkspace(projectId, userId, workspaceId string) (err error)
 }
 
 type ProjectService struct {
-	log      zerolog.Logger
-	producer *rabbit_mq.Producer
-	storage  *storages.Storage
+	log            zerolog.Logger
+	producer       *rabbit_mq.Producer
+	storage        *storages.Storage
+	trashRetention time.Duration
 }
 
-func NewProjectService(log zerolog.Logger, producer *rabbit_mq.Producer, storage *storages.Storage) *ProjectService {
+func NewProjectService(log zerolog.Logger, producer *rabbit_mq.Producer, storage *storages.Storage, trashRetention time.Duration) *ProjectService {
 	return &ProjectService{
-		log:      log,
-		producer: producer,
-		storage:  storage,
+		log:            log,
+		producer:       producer,
+		storage:        storage,
+		trashRetention: trashRetention,
 	}
 }
 
 func (s *ProjectService) Create(project entity.Project, ownerId string) (projectId string, err error) {
 	s.log.Debug().Msgf("creating project: %v", project)
 	s.log.Debug().Msgf("ownerId: %v", ownerId)
+	// Создавать проекты в воркспейсе могут редакторы и выше
+	if project.WorkspaceId != "" {
+		if _, err = authorizeWorkspace(s.storage, project.WorkspaceId, ownerId, entity.ProjectRoleEditor); err != nil {
+			return "", err
+		}
+	}
 	return s.storage.Project.Create(project, ownerId)
 }
 
-func (s *ProjectService) GetAllByUserId(userId string) (projects []entity.Project, err error) {
-	projects, err = s.storage.Project.GetAllByUserId(userId)
+func (s *ProjectService) GetAllByUserId(userId string, filter entity.ProjectFilter) (list entity.ProjectList, err error) {
+	list, err = s.storage.Project.GetAllByUserId(userId, filter)
+	if err != nil {
+		return entity.ProjectList{}, err
+	}
+	if list.Projects == nil {
+		list.Projects = []entity.Project{}
+	}
+	return list, nil
+}
+
+func (s *ProjectService) UpdateById(project entity.Project) (err error) {
+	return s.storage.Project.UpdateById(project)
+}
+
+func (s *ProjectService) DeleteById(projectId, userId string) (err error) {
+	if _, err = authorizeProject(s.storage, projectId, userId, entity.ProjectRoleAdmin); err != nil {
+		return err
+	}
+	err = s.storage.Project.DeleteById(projectId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	invalidateRuntime(s.log, s.storage, projectId)
+	publishEvent(s.log, s.storage, entity.Event{Type: entity.EventProjectDeleted, ProjectId: projectId, ActorId: userId})
+	return nil
+}
+
+func (s *ProjectService) GetTrash(userId string) (projects []entity.Project, err error) {
+	projects, err = s.storage.Project.GetTrashByUserId(userId)
 	if err != nil {
 		return nil, err
 	}
 	if projects == nil {
 		return []entity.Project{}, nil
 	}
+	// Показываем, когда проект будет удален окончательно
+	for i := range projects {
+		purgeAt := projects[i].DeletedAt.Add(s.trashRetention)
+		projects[i].PurgeAt = &purgeAt
+	}
 	return projects, nil
 }
 
-func (s *ProjectService) UpdateById(project entity.Project) (err error) {
-	return s.storage.Project.UpdateById(project)
+func (s *ProjectService) Restore(projectId, userId string) (err error) {
+	if _, err = authorizeProject(s.storage, projectId, userId, entity.ProjectRoleAdmin); err != nil {
+		return err
+	}
+	err = s.storage.Project.RestoreById(projectId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	publishEvent(s.log, s.storage, entity.Event{
+		Type: entity.EventProjectUpdated, ProjectId: projectId, ActorId: userId,
+		Data: map[string]interface{}{"action": "restore"},
+	})
+	return nil
+}
+
+func (s *ProjectService) Purge(projectId, userId string) (err error) {
+	if _, err = authorizeProject(s.storage, projectId, userId, entity.ProjectRoleOwner); err != nil {
+		return err
+	}
+	err = s.storage.Project.PurgeById(projectId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// trashPurgeLockTTL - страховочный срок блокировки очистки корзины на случай, если
+// экземпляр упадет, не сняв ее. Обычно блокировка снимается по окончании очистки.
+const trashPurgeLockTTL = 30 * time.Minute
+
+// PurgeExpired окончательно удаляет проекты, пролежавшие в корзине дольше срока хранения.
+// Одновременно очистку выполняет только один экземпляр. Проекты, которые тем временем
+// восстановили или удалили, не считаются.
+func (s *ProjectService) PurgeExpired() (purged int, err error) {
+	token := uuid.NewString()
+	acquired, err := s.storage.Project.AcquirePurgeLock(token, trashPurgeLockTTL)
+	if err != nil || !acquired {
+		return 0, err
+	}
+	defer func() {
+		if releaseErr := s.storage.Project.ReleasePurgeLock(token); releaseErr != nil {
+			s.log.Warn().Err(releaseErr).Msg("error releasing trash purge lock")
+		}
+	}()
+	projectIds, err := s.storage.Project.GetDeletedBefore(time.Now().Add(-s.trashRetention))
+	if err != nil {
+		return 0, err
+	}
+	for _, projectId := range projectIds {
+		err = s.storage.Project.PurgeById(projectId)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			s.log.Error().Err(err).Str("projectId", projectId).Msg("error purging expired project")
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// ChangeStatus выполняет переход жизненного цикла проекта (publish/unpublish/archive)
+// с проверкой допустимости перехода, роли пользователя и предусловий.
+func (s *ProjectService) ChangeStatus(projectId, userId, action string) (project entity.Project, err error) {
+	role, err := authorizeProject(s.storage, projectId, userId, entity.ProjectRoleViewer)
+	if err != nil {
+		return entity.Project{}, err
+	}
+	project, err = s.storage.Project.GetById(projectId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Project{}, ErrNotFound
+	}
+	if err != nil {
+		return entity.Project{}, err
+	}
+	// Проверяем, что переход допустим
+	transition, ok := entity.FindProjectTransition(action, project.Status)
+	if !ok {
+		return entity.Project{}, fmt.Errorf("%w: cannot %s project with status %s", ErrConflict, action, project.Status)
+	}
+	if !entity.RoleAtLeast(role, transition.Role) {
+		return entity.Project{}, ErrForbidden
+	}
+	// Проверяем предусловия
+	if transition.To == entity.ProjectStatusPublished {
+		published, err := s.storage.Screen.CountByStatus(projectId, entity.ScreenStatusPublished)
+		if err != nil {
+			return entity.Project{}, err
+		}
+		if published == 0 {
+			return entity.Project{}, fmt.Errorf("%w: project must have at least one published screen", ErrConflict)
+		}
+	}
+	// Меняем статус
+	err = s.storage.Project.UpdateStatus(projectId, transition.From, transition.To)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Project{}, fmt.Errorf("%w: project status was changed concurrently", ErrConflict)
+	}
+	if err != nil {
+		return entity.Project{}, err
+	}
+	s.log.Info().Str("projectId", projectId).Str("from", transition.From).Str("to", transition.To).Msg("project status changed")
+	invalidateRuntime(s.log, s.storage, projectId)
+	publishEvent(s.log, s.storage, entity.Event{
+		Type: entity.EventProjectUpdated, ProjectId: projectId, ActorId: userId,
+		Data: map[string]interface{}{"action": action, "status": transition.To},
+	})
+
+	project.Status = transition.To
+	project.Role = role
+	return project, nil
+}
+
+// Duplicate создает копию проекта, доступного пользователю. Пользователь становится владельцем копии.
+func (s *ProjectService) Duplicate(projectId, userId, name string) (newProjectId string, err error) {
+	if _, err = authorizeProject(s.storage, projectId, userId, entity.ProjectRoleViewer); err != nil {
+		return "", err
+	}
+	if name == "" {
+		project, err := s.storage.Project.GetById(projectId)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrNotFound
+		}
+		if err != nil {
+			return "", err
+		}
+		name = copyName(project.Name)
+	}
+	newProjectId, err = s.storage.Project.Copy(projectId, userId, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return newProjectId, err
+}
+
+func (s *ProjectService) SetTemplate(projectId, userId string, isTemplate bool) (err error) {
+	if _, err = authorizeProject(s.storage, projectId, userId, entity.ProjectRoleAdmin); err != nil {
+		return err
+	}
+	err = s.storage.Project.SetTemplate(projectId, isTemplate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *ProjectService) GetTemplates() (templates []entity.Project, err error) {
+	templates, err = s.storage.Project.GetTemplates()
+	if err != nil {
+		return nil, err
+	}
+	if templates == nil {
+		return []entity.Project{}, nil
+	}
+	return templates, nil
+}
+
+// Instantiate создает новый проект пользователя из шаблона каталога.
+// Членство в проекте-шаблоне не требуется.
+func (s *ProjectService) Instantiate(templateId, userId, name string) (newProjectId string, err error) {
+	isTemplate, err := s.storage.Project.IsTemplate(templateId)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !isTemplate) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		template, err := s.storage.Project.GetById(templateId)
+		if err != nil {
+			return "", err
+		}
+		name = template.Name
+	}
+	newProjectId, err = s.storage.Project.Copy(templateId, userId, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return newProjectId, err
+}
+
+// MoveToWorkspace передает проект во владение воркспейса. Требуются права владельца проекта
+// и администратора воркспейса.
+func (s *ProjectService) MoveToWorkspace(projectId, userId, workspaceId string) (err error) {
+	if _, err = authorizeProject(s.storage, projectId, userId, entity.ProjectRoleOwner); err != nil {
+		return err
+	}
+	if _, err = authorizeWorkspace(s.storage, workspaceId, userId, entity.ProjectRoleAdmin); err != nil {
+		return err
+	}
+	err = s.storage.Project.SetWorkspace(projectId, workspaceId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	publishEvent(s.log, s.storage, entity.Event{
+		Type: entity.EventProjectUpdated, ProjectId: projectId, WorkspaceId: workspaceId, ActorId: userId,
+		Data: map[string]interface{}{"action": "move"},
+	})
+	return nil
 }
 
-func (s *ProjectService) DeleteById(projectId string) (err error) {
-	return s.storage.Project.DeleteById(projectId)
+// copyName формирует имя копии, укладываясь в ограничение длины имени проекта.
+func copyName(name string) string {
+	const suffix = " (copy)"
+	runes := []rune(name)
+	for len(runes) > 0 && len(string(runes))+len(suffix) > 100 {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + suffix
 }
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/internal/storages"
+)
+
+type Release interface {
+	Publish(projectId, screenId, userId string, params entity.ScreenReleaseCreate) (release entity.ScreenRelease, err error)
+	GetAll(projectId, screenId, userId string) (releases []entity.ScreenRelease, err error)
+	Get(projectId, screenId, userId string, releaseNumber int) (release entity.ScreenRelease, err error)
+	Rollback(projectId, screenId, userId string, releaseNumber int) (release entity.ScreenRelease, err error)
+}
+
+type releaseService struct {
+	log     zerolog.Logger
+	storage *storages.Storage
+}
+
+func NewReleaseService(log zerolog.Logger, storage *storages.Storage) Release {
+	return &releaseService{
+		log:     log,
+		storage: storage,
+	}
+}
+
+// Publish замораживает версию ветки в новом релизе и делает его живым.
+// Публиковать могут администраторы проекта, как и сам проект.
+func (s *releaseService) Publish(projectId, screenId, userId string, params entity.ScreenReleaseCreate) (release entity.ScreenRelease, err error) {
+	if err = authorizeScreen(s.storage, projectId, screenId, userId, entity.ProjectRoleAdmin, true); err != nil {
+		return entity.ScreenRelease{}, err
+	}
+	var branch entity.ScreenBranch
+	if params.BranchId == "" {
+		branch, err = s.storage.Branch.GetByName(screenId, entity.ScreenMainBranch)
+	} else {
+		branch, err = s.storage.Branch.GetById(screenId, params.BranchId)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ScreenRelease{}, fmt.Errorf("%w: branch", ErrNotFound)
+	}
+	if err != nil {
+		return entity.ScreenRelease{}, err
+	}
+	version, err := s.storage.Version.Get(branch.Id, params.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ScreenRelease{}, fmt.Errorf("%w: version %d", ErrNotFound, params.Version)
+	}
+	if err != nil {
+		return entity.ScreenRelease{}, err
+	}
+
+	// Компоненты разворачиваются при публикации: релиз не меняется от последующих правок компонентов
+	widgets, err := newComponentResolver(s.storage, entity.ComponentScope{ProjectId: projectId}).expand(version.Widgets, nil)
+	if err != nil {
+		return entity.ScreenRelease{}, err
+	}
+	// Дизайн-токены тоже фиксируются в релизе; значения других тем отдаются переопределениями
+	tokens, err := s.storage.DesignToken.GetByProjectId(projectId)
+	if err != nil {
+		return entity.ScreenRelease{}, err
+	}
+	widgets, settings, themes, tokenErrs := resolveTokens(tokens, widgets, version.Settings)
+	if len(tokenErrs) > 0 {
+		return entity.ScreenRelease{}, &ValidationError{Errors: tokenErrs}
+	}
+
+	release = entity.ScreenRelease{
+		ScreenId:  screenId,
+		BranchId:  branch.Id,
+		Version:   version.Version,
+		Widgets:   widgets,
+		Settings:  settings,
+		Themes:    themes,
+		Notes:     params.Notes,
+		CreatedBy: userId,
+	}
+	err = s.storage.Release.Create(&release)
+	if errors.Is(err, storages.ErrAlreadyExists) {
+		return entity.ScreenRelease{}, fmt.Errorf("%w: screen was published concurrently, retry", ErrConflict)
+	}
+	if err != nil {
+		s.log.Error().Err(err).Str("screen_id", screenId).Msg("error publishing screen")
+		return entity.ScreenRelease{}, err
+	}
+	s.log.Info().Str("screen_id", screenId).Int("release", release.ReleaseNumber).Msg("screen published")
+	invalidateRuntime(s.log, s.storage, projectId)
+	publishEvent(s.log, s.storage, entity.Event{
+		Type: entity.EventReleasePublished, ProjectId: projectId, ActorId: userId,
+		Data: map[string]interface{}{
+			"screen_id":      screenId,
+			"release_number": release.ReleaseNumber,
+			"branch_id":      release.BranchId,
+			"version":        release.Version,
+		},
+	})
+	return release, nil
+}
+
+func (s *releaseService) GetAll(projectId, screenId, userId string) (releases []entity.ScreenRelease, err error) {
+	if err = authorizeScreen(s.storage, projectId, screenId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return nil, err
+	}
+	releases, err = s.storage.Release.GetAllByScreenId(screenId)
+	if err != nil {
+		return nil, err
+	}
+	if releases == nil {
+		return []entity.ScreenRelease{}, nil
+	}
+	return releases, nil
+}
+
+// Get возвращает релиз с документами; нулевой номер означает живой релиз.
+func (s *releaseService) Get(projectId, screenId, userId string, releaseNumber int) (release entity.ScreenRelease, err error) {
+	if err = authorizeScreen(s.storage, projectId, screenId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return entity.ScreenRelease{}, err
+	}
+	release, err = s.storage.Release.Get(screenId, releaseNumber)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ScreenRelease{}, fmt.Errorf("%w: release %d", ErrNotFound, releaseNumber)
+	}
+	return release, err
+}
+
+// Rollback делает живым ранее созданный релиз. Новых релизов не создается.
+func (s *releaseService) Rollback(projectId, screenId, userId string, releaseNumber int) (release entity.ScreenRelease, err error) {
+	if err = authorizeScreen(s.storage, projectId, screenId, userId, entity.ProjectRoleAdmin, true); err != nil {
+		return entity.ScreenRelease{}, err
+	}
+	err = s.storage.Release.SetLive(screenId, releaseNumber)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ScreenRelease{}, fmt.Errorf("%w: release %d", ErrNotFound, releaseNumber)
+	}
+	if errors.Is(err, storages.ErrAlreadyExists) {
+		return entity.ScreenRelease{}, fmt.Errorf("%w: live release was changed concurrently, retry", ErrConflict)
+	}
+	if err != nil {
+		return entity.ScreenRelease{}, err
+	}
+	s.log.Info().Str("screen_id", screenId).Int("release", releaseNumber).Msg("screen rolled back")
+	invalidateRuntime(s.log, s.storage, projectId)
+	return s.storage.Release.Get(screenId, releaseNumber)
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/internal/storages"
+)
+
+type Route interface {
+	GetAll(projectId, userId string) (routes []entity.Route, err error)
+	Create(route entity.Route, userId string) (created entity.Route, err error)
+	Update(route entity.Route, userId string) (updated entity.Route, err error)
+	Delete(projectId, routeId, userId string) (err error)
+	Resolve(projectId, userId, link string) (resolution entity.RouteResolution, err error)
+	GetNavigationGraph(projectId, userId string) (graph entity.NavigationGraph, err error)
+}
+
+type routeService struct {
+	log     zerolog.Logger
+	storage *storages.Storage
+}
+
+func NewRouteService(log zerolog.Logger, storage *storages.Storage) Route {
+	return &routeService{
+		log:     log,
+		storage: storage,
+	}
+}
+
+func (s *routeService) GetAll(projectId, userId string) (routes []entity.Route, err error) {
+	if _, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return nil, err
+	}
+	routes, err = s.storage.Route.GetAllByProjectId(projectId)
+	if err != nil {
+		return nil, err
+	}
+	if routes == nil {
+		return []entity.Route{}, nil
+	}
+	return routes, nil
+}
+
+func (s *routeService) Create(route entity.Route, userId string) (created entity.Route, err error) {
+	if _, err = authorizeActiveProject(s.storage, route.ProjectId, userId, entity.ProjectRoleEditor, true); err != nil {
+		return entity.Route{}, err
+	}
+	if err = s.check(route); err != nil {
+		return entity.Route{}, err
+	}
+	route.CreatedBy = userId
+	err = s.storage.Route.Create(&route)
+	if errors.Is(err, storages.ErrAlreadyExists) {
+		return entity.Route{}, fmt.Errorf("%w: route %s already exists", ErrConflict, route.Path)
+	}
+	if err != nil {
+		return entity.Route{}, err
+	}
+	invalidateRuntime(s.log, s.storage, route.ProjectId)
+	return route, nil
+}
+
+// Update меняет путь или экран маршрута.
+func (s *routeService) Update(route entity.Route, userId string) (updated entity.Route, err error) {
+	if _, err = authorizeActiveProject(s.storage, route.ProjectId, userId, entity.ProjectRoleEditor, true); err != nil {
+		return entity.Route{}, err
+	}
+	if err = s.check(route); err != nil {
+		return entity.Route{}, err
+	}
+	err = s.storage.Route.UpdateById(&route)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Route{}, ErrNotFound
+	}
+	if errors.Is(err, storages.ErrAlreadyExists) {
+		return entity.Route{}, fmt.Errorf("%w: route %s already exists", ErrConflict, route.Path)
+	}
+	if err != nil {
+		return entity.Route{}, err
+	}
+	invalidateRuntime(s.log, s.storage, route.ProjectId)
+	return route, nil
+}
+
+func (s *routeService) Delete(projectId, routeId, userId string) (err error) {
+	if _, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleEditor, true); err != nil {
+		return err
+	}
+	err = s.storage.Route.DeleteById(projectId, routeId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	invalidateRuntime(s.log, s.storage, projectId)
+	return nil
+}
+
+// check проверяет, что экран маршрута существует и путь не пересекается
+// с маршрутом той же формы (кроме самого обновляемого маршрута).
+func (s *routeService) check(route entity.Route) error {
+	exists, err := s.storage.Screen.Exists(route.ProjectId, route.ScreenId)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%w: screen %s not found", ErrInvalid, route.ScreenId)
+	}
+	routes, err := s.storage.Route.GetAllByProjectId(route.ProjectId)
+	if err != nil {
+		return err
+	}
+	for _, existing := range routes {
+		if existing.Id != route.Id && existing.Shape() == route.Shape() {
+			return fmt.Errorf("%w: route %s matches the same paths as %s", ErrConflict, route.Path, existing.Path)
+		}
+	}
+	return nil
+}
+
+// Resolve находит экран для deep link среди всех маршрутов проекта, включая неопубликованные экраны.
+func (s *routeService) Resolve(projectId, userId, link string) (resolution entity.RouteResolution, err error) {
+	routes, err := s.GetAll(projectId, userId)
+	if err != nil {
+		return entity.RouteResolution{}, err
+	}
+	route, params, ok := entity.ResolveRoute(routes, link)
+	if !ok {
+		return entity.RouteResolution{}, fmt.Errorf("%w: no route matches %s", ErrNotFound, link)
+	}
+	exists, err := s.storage.Screen.Exists(projectId, route.ScreenId)
+	if err != nil {
+		return entity.RouteResolution{}, err
+	}
+	if !exists {
+		return entity.RouteResolution{}, fmt.Errorf("%w: route %s leads to a deleted screen", ErrNotFound, route.Path)
+	}
+	return entity.RouteResolution{Route: route, ScreenId: route.ScreenId, Params: params}, nil
+}
+
+// GetNavigationGraph строит граф переходов по головным версиям Main всех экранов проекта
+// и отмечает переходы и маршруты, которые ведут к удаленным экранам или несуществующим путям.
+func (s *routeService) GetNavigationGraph(projectId, userId string) (graph entity.NavigationGraph, err error) {
+	routes, err := s.GetAll(projectId, userId)
+	if err != nil {
+		return entity.NavigationGraph{}, err
+	}
+	screens, err := s.storage.Screen.GetWidgetsByProjectId(projectId)
+	if err != nil {
+		return entity.NavigationGraph{}, err
+	}
+
+	graph = entity.NavigationGraph{
+		Nodes:          make([]entity.NavigationNode, 0, len(screens)),
+		Edges:          []entity.NavigationEdge{},
+		DanglingRoutes: []entity.Route{},
+	}
+	nodes := make(map[string]int, len(screens))
+	for _, screen := range screens {
+		nodes[screen.Id] = len(graph.Nodes)
+		graph.Nodes = append(graph.Nodes, entity.NavigationNode{
+			ScreenId: screen.Id, Name: screen.Name, Status: screen.Status, Routes: []string{},
+		})
+	}
+	for _, route := range routes {
+		index, ok := nodes[route.ScreenId]
+		if !ok {
+			graph.DanglingRoutes = append(graph.DanglingRoutes, route)
+			continue
+		}
+		graph.Nodes[index].Routes = append(graph.Nodes[index].Routes, route.Path)
+		if route.Path == "/" {
+			graph.Nodes[index].Entry = true
+		}
+	}
+
+	for _, screen := range screens {
+		widgets := entity.ParseWidgets(screen.Widgets)
+		ids := make([]string, 0, len(widgets))
+		for id := range widgets {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			target, ok := entity.NavigationTarget(widgets[id])
+			if !ok {
+				continue
+			}
+			edge := entity.NavigationEdge{From: screen.Id, WidgetId: id, Target: target}
+			if strings.HasPrefix(target, "/") {
+				route, _, matched := entity.ResolveRoute(routes, target)
+				if _, exists := nodes[route.ScreenId]; matched && exists {
+					edge.To, edge.Route = route.ScreenId, route.Path
+				} else if matched {
+					edge.Route = route.Path
+					edge.Dangling, edge.Reason = true, entity.NavigationDanglingRouteScreen
+				} else {
+					edge.Dangling, edge.Reason = true, entity.NavigationDanglingRoute
+				}
+			} else if _, exists := nodes[target]; exists {
+				edge.To = target
+			} else {
+				edge.Dangling, edge.Reason = true, entity.NavigationDanglingScreen
+			}
+			graph.Edges = append(graph.Edges, edge)
+		}
+	}
+	return graph, nil
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/internal/storages"
+)
+
+type Runtime interface {
+	GetProject(projectId string, locales []string) (project entity.RuntimeProject, err error)
+	GetScreen(projectId, screenId string, locales []string) (screen entity.RuntimeScreen, locale string, updatedAt time.Time, err error)
+	ResolveLink(projectId, link string) (resolution entity.RouteResolution, updatedAt time.Time, err error)
+}
+
+type runtimeService struct {
+	log      zerolog.Logger
+	storage  *storages.Storage
+	cacheTTL time.Duration
+}
+
+func NewRuntimeService(log zerolog.Logger, storage *storages.Storage, cacheTTL time.Duration) Runtime {
+	return &runtimeService{
+		log:      log,
+		storage:  storage,
+		cacheTTL: cacheTTL,
+	}
+}
+
+// GetProject возвращает снимок опубликованного проекта с текстами локали,
+// выбранной по списку запрошенных локалей.
+func (s *runtimeService) GetProject(projectId string, locales []string) (project entity.RuntimeProject, err error) {
+	project, err = s.snapshot(projectId)
+	if err != nil {
+		return entity.RuntimeProject{}, err
+	}
+	return project.Localize(locales), nil
+}
+
+// snapshot возвращает снимок опубликованного проекта. Снимок кешируется в Redis
+// и сбрасывается при публикациях; недоступность Redis не мешает отдавать данные.
+func (s *runtimeService) snapshot(projectId string) (project entity.RuntimeProject, err error) {
+	if _, err = uuid.Parse(projectId); err != nil {
+		return entity.RuntimeProject{}, ErrNotFound
+	}
+	project, ok, err := s.storage.Runtime.GetCache(projectId)
+	if err != nil {
+		s.log.Warn().Err(err).Str("project_id", projectId).Msg("error reading runtime cache")
+	}
+	if ok {
+		return project, nil
+	}
+
+	project, err = s.storage.Runtime.GetProject(projectId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.RuntimeProject{}, ErrNotFound
+	}
+	if err != nil {
+		return entity.RuntimeProject{}, err
+	}
+	if err = s.storage.Runtime.SetCache(project, s.cacheTTL); err != nil {
+		s.log.Warn().Err(err).Str("project_id", projectId).Msg("error writing runtime cache")
+	}
+	return project, nil
+}
+
+// GetScreen возвращает живой релиз экрана опубликованного проекта с текстами выбранной локали
+// и время последнего изменения снимка.
+func (s *runtimeService) GetScreen(projectId, screenId string, locales []string) (screen entity.RuntimeScreen, locale string, updatedAt time.Time, err error) {
+	project, err := s.snapshot(projectId)
+	if err != nil {
+		return entity.RuntimeScreen{}, "", time.Time{}, err
+	}
+	screen, ok := project.FindScreen(screenId)
+	if !ok {
+		return entity.RuntimeScreen{}, "", time.Time{}, ErrNotFound
+	}
+	project.Screens = []entity.RuntimeScreen{screen}
+	project = project.Localize(locales)
+	return project.Screens[0], project.Locale, project.UpdatedAt, nil
+}
+
+// ResolveLink находит экран опубликованного проекта для deep link.
+func (s *runtimeService) ResolveLink(projectId, link string) (resolution entity.RouteResolution, updatedAt time.Time, err error) {
+	project, err := s.snapshot(projectId)
+	if err != nil {
+		return entity.RouteResolution{}, time.Time{}, err
+	}
+	resolution, ok := project.ResolveLink(link)
+	if !ok {
+		return entity.RouteResolution{}, time.Time{}, ErrNotFound
+	}
+	return resolution, project.UpdatedAt, nil
+}
+
+// invalidateRuntime сбрасывает кеш runtime проекта после изменения того, что видят приложения.
+// Ошибка только логируется: устаревший снимок истечет по TTL.
+func invalidateRuntime(log zerolog.Logger, storage *storages.Storage, projectId string) {
+	if err := storage.Runtime.DeleteCache(projectId); err != nil {
+		log.Warn().Err(err).Str("project_id", projectId).Msg("error invalidating runtime cache")
+	}
+}
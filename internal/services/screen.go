@@ -1,13 +1,20 @@
 package services
 
 import (
+	"database/sql"
+	"errors"
+
 	"github.com/rs/zerolog"
 	"ui-platform-backend-service/internal/entity"
 	"ui-platform-backend-service/internal/storages"
 )
 
 type Screen interface {
-	Create(screen *entity.Screen) (screenId string, err error)
+	Create(screen *entity.Screen, userId string) (screenId string, err error)
+	GetAllByProjectId(projectId, userId string) (screens []entity.Screen, err error)
+	GetById(projectId, screenId, userId string) (screen entity.Screen, err error)
+	UpdateById(screen *entity.Screen, userId string) (err error)
+	DeleteById(projectId, screenId, userId string) (err error)
 }
 
 type screenService struct {
@@ -22,8 +29,131 @@ func NewScreenService(log zerolog.Logger, storage *storages.Storage) Screen {
 	}
 }
 
-func (s *screenService) Create(screen *entity.Screen) (screenId string, err error) {
-	s.log.Info().Str("screen_id", screen.Id).Msg("Creating screen")
-	//TODO: implement
-	return "", err
+func (s *screenService) Create(screen *entity.Screen, userId string) (screenId string, err error) {
+	s.log.Info().Str("project_id", screen.ProjectId).Msg("Creating screen")
+	if err = s.authorizeWrite(screen.ProjectId, userId); err != nil {
+		return "", err
+	}
+	if err = validateScreenDocument(s.storage, screen.ProjectId, screen.Widgets); err != nil {
+		return "", err
+	}
+	screenId, err = s.storage.Screen.Create(screen)
+	if err != nil {
+		s.log.Error().Err(err).Msg("error creating screen")
+		return "", err
+	}
+	publishEvent(s.log, s.storage, entity.Event{
+		Type: entity.EventScreenCreated, ProjectId: screen.ProjectId, ActorId: userId,
+		Data: map[string]interface{}{"screen_id": screenId, "name": screen.Name},
+	})
+	return screenId, nil
+}
+
+func (s *screenService) GetAllByProjectId(projectId, userId string) (screens []entity.Screen, err error) {
+	if err = s.authorizeRead(projectId, userId); err != nil {
+		return nil, err
+	}
+	screens, err = s.storage.Screen.GetAllByProjectId(projectId)
+	if err != nil {
+		return nil, err
+	}
+	if screens == nil {
+		return []entity.Screen{}, nil
+	}
+	return screens, nil
+}
+
+func (s *screenService) GetById(projectId, screenId, userId string) (screen entity.Screen, err error) {
+	if err = s.authorizeRead(projectId, userId); err != nil {
+		return entity.Screen{}, err
+	}
+	screen, err = s.storage.Screen.GetById(projectId, screenId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Screen{}, ErrNotFound
+	}
+	return screen, err
+}
+
+// UpdateById обновляет описание экрана. Переданные виджеты или настройки
+// сохраняются новой версией ветки Main; при заданной базовой версии устаревшее
+// сохранение отклоняется с StaleVersionError. Версия создается до изменения описания,
+// чтобы отклоненное сохранение не меняло экран частично.
+func (s *screenService) UpdateById(screen *entity.Screen, userId string) (err error) {
+	if err = s.authorizeWrite(screen.ProjectId, userId); err != nil {
+		return err
+	}
+	exists, err := s.storage.Screen.Exists(screen.ProjectId, screen.Id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	if screen.Widgets != nil || screen.Settings != nil {
+		if err = s.saveMainVersion(screen, userId); err != nil {
+			return err
+		}
+	}
+	err = s.storage.Screen.UpdateById(screen)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// saveMainVersion сохраняет виджеты и настройки экрана новой версией ветки Main;
+// непереданная часть документа берется из головы.
+func (s *screenService) saveMainVersion(screen *entity.Screen, userId string) error {
+	main, err := s.storage.Branch.GetByName(screen.Id, entity.ScreenMainBranch)
+	if err != nil {
+		return err
+	}
+	head, err := s.storage.Version.Get(main.Id, 0)
+	if err != nil {
+		return err
+	}
+	version := entity.ScreenVersion{
+		BranchId: main.Id,
+		Widgets:  head.Widgets,
+		Settings: head.Settings,
+		AuthorId: userId,
+		Message:  "Update screen",
+	}
+	if screen.Widgets != nil {
+		version.Widgets = screen.Widgets
+	}
+	if screen.Settings != nil {
+		version.Settings = screen.Settings
+	}
+	if screen.BaseVersion > 0 {
+		version.Version = screen.BaseVersion + 1
+	}
+	return createVersion(s.storage, screen.ProjectId, &version)
+}
+
+func (s *screenService) DeleteById(projectId, screenId, userId string) (err error) {
+	if err = s.authorizeWrite(projectId, userId); err != nil {
+		return err
+	}
+	err = s.storage.Screen.DeleteById(projectId, screenId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	invalidateRuntime(s.log, s.storage, projectId)
+	return nil
+}
+
+// authorizeRead проверяет, что пользователь состоит в проекте.
+func (s *screenService) authorizeRead(projectId, userId string) error {
+	_, err := authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleViewer, false)
+	return err
+}
+
+// authorizeWrite проверяет права редактора; архивные проекты доступны только для чтения.
+func (s *screenService) authorizeWrite(projectId, userId string) error {
+	_, err := authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleEditor, true)
+	return err
 }
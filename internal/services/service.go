@@ -2,26 +2,69 @@ package services
 
 import (
 	"github.com/rs/zerolog"
+	"time"
 	"ui-platform-backend-service/internal/storages"
 	"ui-platform-backend-service/pkg/rabbit_mq"
 )
 
 type Service struct {
-	User    User
-	Project Project
-	Screen  Screen
+	User          User
+	Project       Project
+	Screen        Screen
+	Workspace     Workspace
+	Branch        Branch
+	Version       Version
+	Merge         Merge
+	Diff          Diff
+	WidgetType    WidgetType
+	Release       Release
+	Runtime       Runtime
+	Collab        Collab
+	Event         Event
+	Comment       Comment
+	ChangeRequest ChangeRequest
+	Route         Route
+	Component     Component
+	DesignToken   DesignToken
+	Asset         Asset
+	Localization  Localization
+	DataSource    DataSource
 }
 
 type ServiceDeps struct {
 	Log      zerolog.Logger
 	Storage  *storages.Storage
 	Producer *rabbit_mq.Producer
+	// TrashRetention - срок хранения удаленных проектов в корзине
+	TrashRetention time.Duration
+	// RuntimeCacheTTL - время жизни снимка опубликованного проекта в Redis
+	RuntimeCacheTTL time.Duration
+	// CollabSnapshotInterval - как часто сессия совместного редактирования сохраняет версию ветки
+	CollabSnapshotInterval time.Duration
 }
 
 func NewService(deps ServiceDeps) *Service {
 	return &Service{
-		User:    NewUserService(deps.Log, deps.Producer, deps.Storage),
-		Project: NewProjectService(deps.Log, deps.Producer, deps.Storage),
-		Screen:  NewScreenService(deps.Log, deps.Storage),
+		User:          NewUserService(deps.Log, deps.Producer, deps.Storage),
+		Project:       NewProjectService(deps.Log, deps.Producer, deps.Storage, deps.TrashRetention),
+		Screen:        NewScreenService(deps.Log, deps.Storage),
+		Workspace:     NewWorkspaceService(deps.Log, deps.Storage),
+		Branch:        NewBranchService(deps.Log, deps.Storage),
+		Version:       NewVersionService(deps.Log, deps.Storage),
+		Merge:         NewMergeService(deps.Log, deps.Storage),
+		Diff:          NewDiffService(deps.Log, deps.Storage),
+		WidgetType:    NewWidgetTypeService(deps.Log, deps.Storage),
+		Release:       NewReleaseService(deps.Log, deps.Storage),
+		Runtime:       NewRuntimeService(deps.Log, deps.Storage, deps.RuntimeCacheTTL),
+		Collab:        NewCollabService(deps.Log, deps.Storage, deps.CollabSnapshotInterval),
+		Event:         NewEventService(deps.Log, deps.Storage),
+		Comment:       NewCommentService(deps.Log, deps.Producer, deps.Storage),
+		ChangeRequest: NewChangeRequestService(deps.Log, deps.Storage),
+		Route:         NewRouteService(deps.Log, deps.Storage),
+		Component:     NewComponentService(deps.Log, deps.Storage),
+		DesignToken:   NewDesignTokenService(deps.Log, deps.Storage),
+		Asset:         NewAssetService(deps.Log, deps.Storage),
+		Localization:  NewLocalizationService(deps.Log, deps.Storage),
+		DataSource:    NewDataSourceService(deps.Log, deps.Storage),
 	}
 }
@@ -0,0 +1,316 @@
+package services
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/internal/storages"
+	"ui-platform-backend-service/pkg/jsonpatch"
+	"ui-platform-backend-service/pkg/jsonschema"
+)
+
+type DesignToken interface {
+	Get(projectId, userId string) (tokens entity.DesignTokens, err error)
+	Update(projectId, userId string, update entity.DesignTokensUpdate) (tokens entity.DesignTokens, err error)
+	Export(projectId, userId, format string) (document []byte, err error)
+}
+
+type designTokenService struct {
+	log     zerolog.Logger
+	storage *storages.Storage
+}
+
+func NewDesignTokenService(log zerolog.Logger, storage *storages.Storage) DesignToken {
+	return &designTokenService{
+		log:     log,
+		storage: storage,
+	}
+}
+
+func (s *designTokenService) Get(projectId, userId string) (tokens entity.DesignTokens, err error) {
+	if _, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return entity.DesignTokens{}, err
+	}
+	return s.storage.DesignToken.GetByProjectId(projectId)
+}
+
+// Update заменяет токены проекта целиком. Опубликованные экраны не меняются
+// до следующей публикации.
+func (s *designTokenService) Update(projectId, userId string, update entity.DesignTokensUpdate) (tokens entity.DesignTokens, err error) {
+	if _, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleEditor, true); err != nil {
+		return entity.DesignTokens{}, err
+	}
+	tokens = entity.DesignTokens{ProjectId: projectId, Tokens: update.Tokens, UpdatedBy: userId}
+	if tokens.Tokens == nil {
+		tokens.Tokens = map[string]entity.DesignToken{}
+	}
+	err = s.storage.DesignToken.Save(&tokens, update.BaseVersion)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.DesignTokens{}, fmt.Errorf("%w: tokens were changed since version %d", ErrConflict, update.BaseVersion)
+	}
+	if err != nil {
+		return entity.DesignTokens{}, err
+	}
+	s.log.Info().Str("project_id", projectId).Int("version", tokens.Version).Msg("design tokens updated")
+	invalidateRuntime(s.log, s.storage, projectId)
+	return tokens, nil
+}
+
+// Export выгружает токены в CSS-переменные или JSON для фронтенда.
+func (s *designTokenService) Export(projectId, userId, format string) (document []byte, err error) {
+	if format != entity.TokensExportCSS && format != entity.TokensExportJSON {
+		return nil, fmt.Errorf("%w: format must be %s or %s", ErrInvalid, entity.TokensExportCSS, entity.TokensExportJSON)
+	}
+	tokens, err := s.Get(projectId, userId)
+	if err != nil {
+		return nil, err
+	}
+	if format == entity.TokensExportCSS {
+		return exportTokensCSS(tokens), nil
+	}
+	return exportTokensJSON(tokens)
+}
+
+// exportTokensCSS строит переменные темы по умолчанию в :root и переопределения
+// остальных тем в [data-theme="..."]. Типографика раскладывается на переменные по полям.
+func exportTokensCSS(tokens entity.DesignTokens) []byte {
+	var buf bytes.Buffer
+	for i, theme := range entity.Themes {
+		var lines []string
+		for _, name := range tokens.Names() {
+			token := tokens.Tokens[name]
+			if _, ok := token.Themes[theme]; i > 0 && !ok {
+				continue
+			}
+			lines = append(lines, cssVariables(name, token.ThemeValue(theme))...)
+		}
+		if i > 0 && len(lines) == 0 {
+			continue
+		}
+		if i == 0 {
+			buf.WriteString(":root {\n")
+		} else {
+			buf.WriteString("\n[data-theme=\"" + theme + "\"] {\n")
+		}
+		for _, line := range lines {
+			buf.WriteString("  " + line + "\n")
+		}
+		buf.WriteString("}\n")
+	}
+	return buf.Bytes()
+}
+
+func cssVariables(name string, value interface{}) []string {
+	variable := "--" + strings.ReplaceAll(name, ".", "-")
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return []string{variable + ": " + cssValue(value, true) + ";"}
+	}
+	var lines []string
+	for _, field := range entity.TypographyFields {
+		fieldValue, ok := fields[field]
+		if !ok {
+			continue
+		}
+		// Значения, сохраненные до проверки font_family, могли бы закрыть блок :root
+		if family, isString := fieldValue.(string); field == "font_family" && (!isString || !entity.IsFontFamily(family)) {
+			continue
+		}
+		// font_weight и line_height без единиц допустимы в CSS как есть
+		pixels := field == "font_size" || field == "letter_spacing"
+		lines = append(lines, variable+"-"+strings.ReplaceAll(field, "_", "-")+": "+cssValue(fieldValue, pixels)+";")
+	}
+	return lines
+}
+
+// cssValue переводит значение токена в CSS; числа-размеры получают единицу px.
+func cssValue(value interface{}, pixels bool) string {
+	switch v := value.(type) {
+	case float64:
+		number := strconv.FormatFloat(v, 'f', -1, 64)
+		if pixels && v != 0 {
+			return number + "px"
+		}
+		return number
+	case string:
+		return v
+	}
+	return fmt.Sprint(value)
+}
+
+var tokenTypes = map[string]string{
+	entity.TokenCategoryColor:      "color",
+	entity.TokenCategoryTypography: "typography",
+	entity.TokenCategorySpacing:    "dimension",
+	entity.TokenCategoryRadius:     "dimension",
+}
+
+// exportTokensJSON строит документ в формате Design Tokens Community Group: имя токена
+// разбивается на вложенные группы, значения остальных тем - в $extensions.themes.
+func exportTokensJSON(tokens entity.DesignTokens) ([]byte, error) {
+	root := map[string]interface{}{}
+	for _, name := range tokens.Names() {
+		token := tokens.Tokens[name]
+		category := entity.TokenCategory(name)
+		node := map[string]interface{}{
+			"$type":  tokenTypes[category],
+			"$value": dtcgValue(category, token.Value),
+		}
+		if token.Description != "" {
+			node["$description"] = token.Description
+		}
+		if len(token.Themes) > 0 {
+			themes := make(map[string]interface{}, len(token.Themes))
+			for theme, value := range token.Themes {
+				themes[theme] = dtcgValue(category, value)
+			}
+			node["$extensions"] = map[string]interface{}{"themes": themes}
+		}
+
+		group := root
+		segments := strings.Split(name, ".")
+		for _, segment := range segments[:len(segments)-1] {
+			next, ok := group[segment].(map[string]interface{})
+			if !ok {
+				next = map[string]interface{}{}
+				group[segment] = next
+			}
+			group = next
+		}
+		group[segments[len(segments)-1]] = node
+	}
+	return json.MarshalIndent(root, "", "  ")
+}
+
+var dtcgTypographyFields = map[string]string{
+	"font_family":    "fontFamily",
+	"font_size":      "fontSize",
+	"font_weight":    "fontWeight",
+	"line_height":    "lineHeight",
+	"letter_spacing": "letterSpacing",
+}
+
+func dtcgValue(category string, value interface{}) interface{} {
+	switch category {
+	case entity.TokenCategorySpacing, entity.TokenCategoryRadius:
+		return cssValue(value, true)
+	case entity.TokenCategoryTypography:
+		fields, _ := value.(map[string]interface{})
+		result := make(map[string]interface{}, len(fields))
+		for field, fieldValue := range fields {
+			if field == "font_size" || field == "letter_spacing" {
+				fieldValue = cssValue(fieldValue, true)
+			}
+			result[dtcgTypographyFields[field]] = fieldValue
+		}
+		return result
+	}
+	return value
+}
+
+// tokenResolver подставляет значения токенов вместо ссылок "{name}" в документе экрана.
+// Документ получает значения темы по умолчанию, а отличающиеся значения остальных тем
+// собираются в themes по JSON Pointer ссылки.
+type tokenResolver struct {
+	tokens entity.DesignTokens
+	themes map[string]map[string]interface{}
+	errs   []jsonschema.Error
+}
+
+func newTokenResolver(tokens entity.DesignTokens) *tokenResolver {
+	return &tokenResolver{tokens: tokens, themes: map[string]map[string]interface{}{}}
+}
+
+// resolve возвращает копию value с подставленными токенами; path - путь value в документе.
+func (r *tokenResolver) resolve(value interface{}, path []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		resolved := make(map[string]interface{}, len(v))
+		for _, key := range keys {
+			resolved[key] = r.resolve(v[key], append(path[:len(path):len(path)], key))
+		}
+		return resolved
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved[i] = r.resolve(item, append(path[:len(path):len(path)], strconv.Itoa(i)))
+		}
+		return resolved
+	}
+
+	name, ok := entity.ParseTokenRef(value)
+	if !ok {
+		return value
+	}
+	token, ok := r.tokens.Tokens[name]
+	if !ok {
+		r.errs = append(r.errs, jsonschema.Error{Path: jsonpatch.FormatPointer(path), Message: fmt.Sprintf("unknown design token %q", name)})
+		return value
+	}
+	for _, theme := range entity.Themes[1:] {
+		if themed, ok := token.Themes[theme]; ok {
+			if r.themes[theme] == nil {
+				r.themes[theme] = map[string]interface{}{}
+			}
+			r.themes[theme][jsonpatch.FormatPointer(path)] = themed
+		}
+	}
+	return token.Value
+}
+
+// resolveTokens подставляет токены в виджеты и настройки экрана.
+// Ссылки на несуществующие токены возвращаются ошибками валидации.
+func resolveTokens(tokens entity.DesignTokens, widgets, settings map[string]interface{}) (resolvedWidgets, resolvedSettings map[string]interface{}, themes map[string]map[string]interface{}, errs []jsonschema.Error) {
+	r := newTokenResolver(tokens)
+	resolvedWidgets, _ = r.resolve(widgets, []string{"widgets"}).(map[string]interface{})
+	if settings != nil {
+		resolvedSettings, _ = r.resolve(settings, []string{"settings"}).(map[string]interface{})
+	}
+	return resolvedWidgets, resolvedSettings, r.themes, r.errs
+}
+
+// checkTokenRefs проверяет, что ссылки в props виджетов указывают на токены проекта.
+// У компонентов воркспейса токенов нет: их ссылки проверяются при публикации экрана.
+func checkTokenRefs(storage *storages.Storage, projectId string, widgets map[string]interface{}) ([]jsonschema.Error, error) {
+	type reference struct {
+		pointer string
+		name    string
+	}
+	var references []reference
+	for _, id := range sortedDocumentIds(widgets) {
+		walkStrings(entity.ParseWidget(id, widgets[id]).Props, []string{"widgets", id, "props"}, func(path []string, value string) {
+			if name, ok := entity.ParseTokenRef(value); ok {
+				references = append(references, reference{pointer: jsonpatch.FormatPointer(path), name: name})
+			}
+		})
+	}
+	if projectId == "" || len(references) == 0 {
+		return nil, nil
+	}
+
+	tokens, err := storage.DesignToken.GetByProjectId(projectId)
+	if err != nil {
+		return nil, err
+	}
+	var errs []jsonschema.Error
+	for _, ref := range references {
+		if _, ok := tokens.Tokens[ref.name]; !ok {
+			errs = append(errs, jsonschema.Error{Path: ref.pointer, Message: fmt.Sprintf("unknown design token %q", ref.name)})
+		}
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+	return errs, nil
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/internal/storages"
+	"ui-platform-backend-service/pkg/jsonpatch"
+)
+
+const (
+	versionHistoryDefaultLimit = 50
+	versionHistoryMaxLimit     = 200
+)
+
+type Version interface {
+	Save(projectId, screenId, branchId, userId string, params entity.ScreenVersionCreate) (version entity.ScreenVersion, err error)
+	GetHistory(projectId, screenId, branchId, userId string, limit, before int) (versions []entity.ScreenVersion, err error)
+	Get(projectId, screenId, branchId, userId string, version int) (screenVersion entity.ScreenVersion, err error)
+	Restore(projectId, screenId, branchId, userId string, version int, message string) (screenVersion entity.ScreenVersion, err error)
+	Patch(projectId, screenId, branchId, userId string, patch entity.ScreenPatch) (screenVersion entity.ScreenVersion, err error)
+}
+
+type versionService struct {
+	log     zerolog.Logger
+	storage *storages.Storage
+}
+
+func NewVersionService(log zerolog.Logger, storage *storages.Storage) Version {
+	return &versionService{
+		log:     log,
+		storage: storage,
+	}
+}
+
+// Save добавляет в ветку новую головную версию. Если задана базовая версия,
+// а головная версия ветки уже другая, возвращается StaleVersionError.
+func (s *versionService) Save(projectId, screenId, branchId, userId string, params entity.ScreenVersionCreate) (version entity.ScreenVersion, err error) {
+	if _, err = authorizeBranch(s.storage, projectId, screenId, branchId, userId, entity.ProjectRoleEditor, true); err != nil {
+		return entity.ScreenVersion{}, err
+	}
+	version = entity.ScreenVersion{
+		BranchId: branchId,
+		Widgets:  params.Widgets,
+		Settings: params.Settings,
+		AuthorId: userId,
+		Message:  params.Message,
+	}
+	// Сохранение поверх известной клиенту версии отклоняется, если голова ушла вперед
+	if params.BaseVersion > 0 {
+		version.Version = params.BaseVersion + 1
+	}
+	if err = s.create(projectId, &version); err != nil {
+		return entity.ScreenVersion{}, err
+	}
+	return version, nil
+}
+
+func (s *versionService) GetHistory(projectId, screenId, branchId, userId string, limit, before int) (versions []entity.ScreenVersion, err error) {
+	if _, err = authorizeBranch(s.storage, projectId, screenId, branchId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = versionHistoryDefaultLimit
+	}
+	if limit > versionHistoryMaxLimit {
+		limit = versionHistoryMaxLimit
+	}
+	versions, err = s.storage.Version.GetAllByBranchId(branchId, limit, before)
+	if err != nil {
+		return nil, err
+	}
+	if versions == nil {
+		return []entity.ScreenVersion{}, nil
+	}
+	return versions, nil
+}
+
+// Get возвращает версию ветки; нулевая версия означает головную.
+func (s *versionService) Get(projectId, screenId, branchId, userId string, version int) (screenVersion entity.ScreenVersion, err error) {
+	if _, err = authorizeBranch(s.storage, projectId, screenId, branchId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return entity.ScreenVersion{}, err
+	}
+	screenVersion, err = s.storage.Version.Get(branchId, version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ScreenVersion{}, fmt.Errorf("%w: version %d", ErrNotFound, version)
+	}
+	return screenVersion, err
+}
+
+// Restore создает новую головную версию с содержимым версии version.
+// История не переписывается: восстановление само является версией.
+func (s *versionService) Restore(projectId, screenId, branchId, userId string, version int, message string) (screenVersion entity.ScreenVersion, err error) {
+	if _, err = authorizeBranch(s.storage, projectId, screenId, branchId, userId, entity.ProjectRoleEditor, true); err != nil {
+		return entity.ScreenVersion{}, err
+	}
+	source, err := s.storage.Version.Get(branchId, version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ScreenVersion{}, fmt.Errorf("%w: version %d", ErrNotFound, version)
+	}
+	if err != nil {
+		return entity.ScreenVersion{}, err
+	}
+	if message == "" {
+		message = fmt.Sprintf("Restore version %d", version)
+	}
+	screenVersion = entity.ScreenVersion{
+		BranchId:     branchId,
+		Widgets:      source.Widgets,
+		Settings:     source.Settings,
+		AuthorId:     userId,
+		Message:      message,
+		RestoredFrom: source.Version,
+	}
+	if err = s.create(projectId, &screenVersion); err != nil {
+		return entity.ScreenVersion{}, err
+	}
+	return screenVersion, nil
+}
+
+// Patch применяет патч к головной версии ветки и сохраняет результат новой версией.
+// Пустая ветка означает Main. Новая версия ложится только поверх той головы, к которой
+// применялся патч, поэтому параллельное сохранение не теряется.
+func (s *versionService) Patch(projectId, screenId, branchId, userId string, patch entity.ScreenPatch) (screenVersion entity.ScreenVersion, err error) {
+	if branchId == "" {
+		if err = authorizeScreen(s.storage, projectId, screenId, userId, entity.ProjectRoleEditor, true); err != nil {
+			return entity.ScreenVersion{}, err
+		}
+		main, err := s.storage.Branch.GetByName(screenId, entity.ScreenMainBranch)
+		if err != nil {
+			return entity.ScreenVersion{}, err
+		}
+		branchId = main.Id
+	} else if _, err = authorizeBranch(s.storage, projectId, screenId, branchId, userId, entity.ProjectRoleEditor, true); err != nil {
+		return entity.ScreenVersion{}, err
+	}
+	head, err := s.storage.Version.Get(branchId, 0)
+	if err != nil {
+		return entity.ScreenVersion{}, err
+	}
+	if patch.BaseVersion > 0 && patch.BaseVersion != head.Version {
+		return entity.ScreenVersion{}, &StaleVersionError{Head: head}
+	}
+
+	// Применяем патч к документу головной версии
+	var document interface{}
+	if patch.Operations != nil {
+		document, err = jsonpatch.Apply(versionDocument(head), patch.Operations)
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			return entity.ScreenVersion{}, fmt.Errorf("%w: %v", ErrConflict, err)
+		}
+		if err != nil {
+			return entity.ScreenVersion{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+	} else {
+		document = jsonpatch.MergePatch(versionDocument(head), patch.MergePatch)
+	}
+	widgets, settings, ok := splitDocument(document)
+	if !ok {
+		return entity.ScreenVersion{}, fmt.Errorf("%w: patch must keep widgets and settings objects", ErrInvalid)
+	}
+
+	message := patch.Message
+	if message == "" {
+		message = "Patch screen"
+	}
+	screenVersion = entity.ScreenVersion{
+		BranchId: branchId,
+		Version:  head.Version + 1,
+		Widgets:  widgets,
+		Settings: settings,
+		AuthorId: userId,
+		Message:  message,
+	}
+	if err = s.create(projectId, &screenVersion); err != nil {
+		return entity.ScreenVersion{}, err
+	}
+	return screenVersion, nil
+}
+
+func (s *versionService) create(projectId string, version *entity.ScreenVersion) error {
+	err := createVersion(s.storage, projectId, version)
+	if errors.Is(err, ErrConflict) || errors.Is(err, ErrInvalid) {
+		return err
+	}
+	if err != nil {
+		s.log.Error().Err(err).Str("branch_id", version.BranchId).Msg("error saving version")
+		return err
+	}
+	s.log.Info().Str("branch_id", version.BranchId).Int("version", version.Version).Msg("version saved")
+	return nil
+}
+
+// createVersion проверяет документ по типам виджетов проекта и сохраняет версию ветки.
+// Если номер версии задан, она должна лечь сразу поверх головной; иначе возвращается
+// StaleVersionError с текущей головой.
+func createVersion(storage *storages.Storage, projectId string, version *entity.ScreenVersion) error {
+	if err := validateScreenDocument(storage, projectId, version.Widgets); err != nil {
+		return err
+	}
+	err := storage.Version.Create(version)
+	if !errors.Is(err, storages.ErrAlreadyExists) {
+		return err
+	}
+	head, err := storage.Version.Get(version.BranchId, 0)
+	if err != nil {
+		return err
+	}
+	return &StaleVersionError{Head: head}
+}
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/rs/zerolog"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/internal/storages"
+	"ui-platform-backend-service/pkg/jsonpatch"
+	"ui-platform-backend-service/pkg/jsonschema"
+)
+
+type WidgetType interface {
+	GetAll(projectId, userId string) (widgetTypes []entity.WidgetType, err error)
+	Create(widgetType entity.WidgetType, userId string) (err error)
+	Update(widgetType entity.WidgetType, userId string) (err error)
+	Delete(projectId, name, userId string) (err error)
+}
+
+type widgetTypeService struct {
+	log     zerolog.Logger
+	storage *storages.Storage
+}
+
+func NewWidgetTypeService(log zerolog.Logger, storage *storages.Storage) WidgetType {
+	return &widgetTypeService{
+		log:     log,
+		storage: storage,
+	}
+}
+
+// GetAll возвращает встроенные типы виджетов и пользовательские типы проекта.
+func (s *widgetTypeService) GetAll(projectId, userId string) (widgetTypes []entity.WidgetType, err error) {
+	if _, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleViewer, false); err != nil {
+		return nil, err
+	}
+	custom, err := s.storage.WidgetType.GetAllByProjectId(projectId)
+	if err != nil {
+		return nil, err
+	}
+	return append(entity.BuiltinWidgetTypes(), custom...), nil
+}
+
+func (s *widgetTypeService) Create(widgetType entity.WidgetType, userId string) (err error) {
+	if _, err = authorizeActiveProject(s.storage, widgetType.ProjectId, userId, entity.ProjectRoleAdmin, true); err != nil {
+		return err
+	}
+	widgetType.CreatedBy = userId
+	err = s.storage.WidgetType.Create(widgetType)
+	if errors.Is(err, storages.ErrAlreadyExists) {
+		return fmt.Errorf("%w: widget type %q already exists", ErrConflict, widgetType.Name)
+	}
+	return err
+}
+
+// Update заменяет схему пользовательского типа. Уже сохраненные версии не перепроверяются:
+// несовместимые виджеты будут отклонены при следующем сохранении экрана.
+func (s *widgetTypeService) Update(widgetType entity.WidgetType, userId string) (err error) {
+	if _, err = authorizeActiveProject(s.storage, widgetType.ProjectId, userId, entity.ProjectRoleAdmin, true); err != nil {
+		return err
+	}
+	err = s.storage.WidgetType.UpdateByName(widgetType)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Delete удаляет пользовательский тип, если он не используется в головных версиях экранов.
+func (s *widgetTypeService) Delete(projectId, name, userId string) (err error) {
+	if _, err = authorizeActiveProject(s.storage, projectId, userId, entity.ProjectRoleAdmin, true); err != nil {
+		return err
+	}
+	used, err := s.storage.WidgetType.IsUsed(projectId, name)
+	if err != nil {
+		return err
+	}
+	if used {
+		return fmt.Errorf("%w: widget type %q is used by screens", ErrConflict, name)
+	}
+	err = s.storage.WidgetType.DeleteByName(projectId, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// validateScreenDocument проверяет виджеты экрана по типам проекта, ссылки экземпляров
+// на компоненты, ссылки на дизайн-токены и выражения привязки данных. При нарушениях
+// возвращается ValidationError с путями от корня документа экрана.
+func validateScreenDocument(storage *storages.Storage, projectId string, widgets map[string]interface{}) error {
+	types, err := projectWidgetTypes(storage, projectId)
+	if err != nil {
+		return err
+	}
+	errs := validateWidgets(types, widgets)
+	refErrs, err := newComponentResolver(storage, entity.ComponentScope{ProjectId: projectId}).check(widgets)
+	if err != nil {
+		return err
+	}
+	tokenErrs, err := checkTokenRefs(storage, projectId, widgets)
+	if err != nil {
+		return err
+	}
+	bindingErrs, err := checkBindings(storage, projectId, widgets)
+	if err != nil {
+		return err
+	}
+	if errs = append(append(append(errs, refErrs...), tokenErrs...), bindingErrs...); len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// projectWidgetTypes возвращает встроенные типы и пользовательские типы проекта.
+// Без проекта (компоненты воркспейса) доступны только встроенные типы.
+func projectWidgetTypes(storage *storages.Storage, projectId string) (map[string]entity.WidgetType, error) {
+	var custom []entity.WidgetType
+	if projectId != "" {
+		var err error
+		if custom, err = storage.WidgetType.GetAllByProjectId(projectId); err != nil {
+			return nil, err
+		}
+	}
+	types := make(map[string]entity.WidgetType, len(custom))
+	for _, widgetType := range append(entity.BuiltinWidgetTypes(), custom...) {
+		types[widgetType.Name] = widgetType
+	}
+	return types, nil
+}
+
+// validateWidgets проверяет карту виджетов: структуру каждого виджета, его props по схеме типа
+// и дерево children - ссылки на существующие виджеты, не более одного родителя, отсутствие циклов.
+func validateWidgets(types map[string]entity.WidgetType, widgets map[string]interface{}) []jsonschema.Error {
+	var errs []jsonschema.Error
+	parents := make(map[string]string, len(widgets))
+
+	ids := make([]string, 0, len(widgets))
+	for id := range widgets {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		path := jsonpatch.FormatPointer([]string{"widgets", id})
+		object, ok := widgets[id].(map[string]interface{})
+		if !ok {
+			errs = append(errs, jsonschema.Error{Path: path, Message: "must be object"})
+			continue
+		}
+		for key := range object {
+			switch key {
+			case "type", "props", "children":
+			default:
+				errs = append(errs, jsonschema.Error{Path: jsonpatch.FormatPointer([]string{"widgets", id, key}), Message: "is not allowed"})
+			}
+		}
+
+		// Тип и свойства
+		typeName, ok := object["type"].(string)
+		if !ok {
+			errs = append(errs, jsonschema.Error{Path: path + "/type", Message: "is required"})
+			continue
+		}
+		widgetType, ok := types[typeName]
+		if !ok {
+			errs = append(errs, jsonschema.Error{Path: path + "/type", Message: fmt.Sprintf("unknown widget type %q", typeName)})
+			continue
+		}
+		props, ok := object["props"]
+		if !ok {
+			props = map[string]interface{}{}
+		}
+		errs = append(errs, withoutReferenceValues(widgetType.Schema.Validate(props, path+"/props"), props, []string{"widgets", id, "props"})...)
+
+		// Дочерние виджеты
+		rawChildren, ok := object["children"]
+		if !ok {
+			continue
+		}
+		children, ok := rawChildren.([]interface{})
+		if !ok {
+			errs = append(errs, jsonschema.Error{Path: path + "/children", Message: "must be array"})
+			continue
+		}
+		if len(children) > 0 && !widgetType.AllowChildren {
+			errs = append(errs, jsonschema.Error{Path: path + "/children", Message: fmt.Sprintf("widget type %q cannot have children", typeName)})
+			continue
+		}
+		for i, rawChild := range children {
+			childPath := path + "/children/" + strconv.Itoa(i)
+			child, ok := rawChild.(string)
+			_, exists := widgets[child]
+			switch {
+			case !ok:
+				errs = append(errs, jsonschema.Error{Path: childPath, Message: "must be string"})
+			case !exists:
+				errs = append(errs, jsonschema.Error{Path: childPath, Message: fmt.Sprintf("unknown widget %q", child)})
+			case child == id:
+				errs = append(errs, jsonschema.Error{Path: childPath, Message: "widget cannot contain itself"})
+			case parents[child] != "":
+				errs = append(errs, jsonschema.Error{Path: childPath, Message: fmt.Sprintf("widget %q already has parent %q", child, parents[child])})
+			default:
+				parents[child] = id
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	// Цикл: поднимаясь по родителям, виджет возвращается к самому себе
+	for _, id := range ids {
+		seen := map[string]bool{}
+		for current := parents[id]; current != "" && !seen[current]; current = parents[current] {
+			if current == id {
+				errs = append(errs, jsonschema.Error{Path: jsonpatch.FormatPointer([]string{"widgets", id, "children"}), Message: "widget tree contains a cycle"})
+				break
+			}
+			seen[current] = true
+		}
+	}
+	return errs
+}
+
+// withoutReferenceValues убирает ошибки схемы для значений, которые целиком заданы ссылкой
+// на дизайн-токен или выражением привязки: значение и его тип известны только после подстановки.
+// Ключ перевода подставляется строкой, поэтому для него остаются ошибки типа нестрокового свойства.
+func withoutReferenceValues(errs []jsonschema.Error, props interface{}, path []string) []jsonschema.Error {
+	if len(errs) == 0 {
+		return errs
+	}
+	// Указатель -> значение любого типа (true) или только строка (false)
+	references := map[string]bool{}
+	walkStrings(props, path, func(path []string, value string) {
+		if _, ok := entity.ParseTokenRef(value); ok {
+			references[jsonpatch.FormatPointer(path)] = true
+		} else if _, whole, err := entity.ParseBindingTemplate(value); err == nil && whole {
+			references[jsonpatch.FormatPointer(path)] = true
+		} else if _, ok := entity.ParseTranslationRef(value); ok {
+			references[jsonpatch.FormatPointer(path)] = false
+		}
+	})
+	kept := errs[:0]
+	for _, err := range errs {
+		anyType, ok := references[err.Path]
+		if !ok || (!anyType && isTypeError(err)) {
+			kept = append(kept, err)
+		}
+	}
+	return kept
+}
+
+func isTypeError(err jsonschema.Error) bool {
+	for _, typ := range []string{jsonschema.TypeObject, jsonschema.TypeArray, jsonschema.TypeNumber,
+		jsonschema.TypeInteger, jsonschema.TypeBoolean, jsonschema.TypeNull} {
+		if err.Message == "must be "+typ {
+			return true
+		}
+	}
+	return false
+}
+
+// walkStrings вызывает found для каждой строки внутри значения с ее путем.
+func walkStrings(value interface{}, path []string, found func(path []string, value string)) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			walkStrings(item, append(path[:len(path):len(path)], key), found)
+		}
+	case []interface{}:
+		for i, item := range v {
+			walkStrings(item, append(path[:len(path):len(path)], strconv.Itoa(i)), found)
+		}
+	case string:
+		found(path, v)
+	}
+}
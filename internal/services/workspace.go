@@ -0,0 +1,221 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/internal/storages"
+)
+
+type Workspace interface {
+	Create(workspace entity.Workspace, ownerId string) (workspaceId string, err error)
+	GetAllByUserId(userId string) (workspaces []entity.Workspace, err error)
+	GetById(workspaceId, userId string) (workspace entity.Workspace, err error)
+	UpdateById(workspace entity.Workspace, userId string) (err error)
+	DeleteById(workspaceId, userId string) (err error)
+	GetMembers(workspaceId, userId string) (members []entity.WorkspaceMember, err error)
+	SetMember(workspaceId, userId string, member entity.WorkspaceMember) (err error)
+	RemoveMember(workspaceId, userId, memberId string) (err error)
+	GetProjects(workspaceId, userId string, filter entity.ProjectFilter) (list entity.ProjectList, err error)
+}
+
+type WorkspaceService struct {
+	log     zerolog.Logger
+	storage *storages.Storage
+}
+
+func NewWorkspaceService(log zerolog.Logger, storage *storages.Storage) *WorkspaceService {
+	return &WorkspaceService{
+		log:     log,
+		storage: storage,
+	}
+}
+
+func (s *WorkspaceService) Create(workspace entity.Workspace, ownerId string) (workspaceId string, err error) {
+	return s.storage.Workspace.Create(workspace, ownerId)
+}
+
+func (s *WorkspaceService) GetAllByUserId(userId string) (workspaces []entity.Workspace, err error) {
+	workspaces, err = s.storage.Workspace.GetAllByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+	if workspaces == nil {
+		return []entity.Workspace{}, nil
+	}
+	return workspaces, nil
+}
+
+func (s *WorkspaceService) GetById(workspaceId, userId string) (workspace entity.Workspace, err error) {
+	role, err := authorizeWorkspace(s.storage, workspaceId, userId, entity.ProjectRoleViewer)
+	if err != nil {
+		return entity.Workspace{}, err
+	}
+	workspace, err = s.storage.Workspace.GetById(workspaceId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Workspace{}, ErrNotFound
+	}
+	if err != nil {
+		return entity.Workspace{}, err
+	}
+	workspace.Role = role
+	return workspace, nil
+}
+
+func (s *WorkspaceService) UpdateById(workspace entity.Workspace, userId string) (err error) {
+	if _, err = authorizeWorkspace(s.storage, workspace.ID, userId, entity.ProjectRoleAdmin); err != nil {
+		return err
+	}
+	err = s.storage.Workspace.UpdateById(workspace)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// DeleteById удаляет воркспейс. Воркспейс с проектами удалить нельзя,
+// чтобы проекты не остались без владельца.
+func (s *WorkspaceService) DeleteById(workspaceId, userId string) (err error) {
+	if _, err = authorizeWorkspace(s.storage, workspaceId, userId, entity.ProjectRoleOwner); err != nil {
+		return err
+	}
+	projects, err := s.storage.Workspace.CountProjects(workspaceId)
+	if err != nil {
+		return err
+	}
+	if projects > 0 {
+		return fmt.Errorf("%w: workspace still has %d projects", ErrConflict, projects)
+	}
+	err = s.storage.Workspace.DeleteById(workspaceId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *WorkspaceService) GetMembers(workspaceId, userId string) (members []entity.WorkspaceMember, err error) {
+	if _, err = authorizeWorkspace(s.storage, workspaceId, userId, entity.ProjectRoleViewer); err != nil {
+		return nil, err
+	}
+	members, err = s.storage.Workspace.GetMembers(workspaceId)
+	if err != nil {
+		return nil, err
+	}
+	if members == nil {
+		return []entity.WorkspaceMember{}, nil
+	}
+	return members, nil
+}
+
+// SetMember добавляет участника (по user_id или email) или меняет его роль.
+// Назначать и менять владельцев может только владелец.
+func (s *WorkspaceService) SetMember(workspaceId, userId string, member entity.WorkspaceMember) (err error) {
+	role, err := authorizeWorkspace(s.storage, workspaceId, userId, entity.ProjectRoleAdmin)
+	if err != nil {
+		return err
+	}
+	if member.UserId == "" {
+		user, err := s.storage.User.GetByEmail(member.Email)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: user %s", ErrNotFound, member.Email)
+		}
+		if err != nil {
+			return err
+		}
+		member.UserId = user.ID
+	}
+	currentRole, err := s.storage.Workspace.GetMemberRole(workspaceId, member.UserId)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if (member.Role == entity.ProjectRoleOwner || currentRole == entity.ProjectRoleOwner) && role != entity.ProjectRoleOwner {
+		return ErrForbidden
+	}
+	if currentRole == entity.ProjectRoleOwner && member.Role != entity.ProjectRoleOwner {
+		if err = s.ensureAnotherOwner(workspaceId); err != nil {
+			return err
+		}
+	}
+	if err = s.storage.Workspace.SetMember(workspaceId, member.UserId, member.Role); err != nil {
+		return err
+	}
+	if currentRole == "" {
+		publishWorkspaceEvent(s.log, s.storage, entity.Event{
+			Type: entity.EventMemberAdded, WorkspaceId: workspaceId, ActorId: userId,
+			Data: map[string]interface{}{"user_id": member.UserId, "role": member.Role},
+		})
+	}
+	return nil
+}
+
+// RemoveMember исключает участника. Любой участник может покинуть воркспейс сам,
+// исключать других могут администраторы, владельцев - только владельцы.
+func (s *WorkspaceService) RemoveMember(workspaceId, userId, memberId string) (err error) {
+	required := entity.ProjectRoleAdmin
+	if memberId == userId {
+		required = entity.ProjectRoleViewer
+	}
+	role, err := authorizeWorkspace(s.storage, workspaceId, userId, required)
+	if err != nil {
+		return err
+	}
+	memberRole, err := s.storage.Workspace.GetMemberRole(workspaceId, memberId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if memberRole == entity.ProjectRoleOwner {
+		if role != entity.ProjectRoleOwner {
+			return ErrForbidden
+		}
+		if err = s.ensureAnotherOwner(workspaceId); err != nil {
+			return err
+		}
+	}
+	err = s.storage.Workspace.RemoveMember(workspaceId, memberId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	// Ленты проектов исключенного участника закрываются по этому событию
+	publishWorkspaceEvent(s.log, s.storage, entity.Event{
+		Type: entity.EventMemberRemoved, WorkspaceId: workspaceId, ActorId: userId,
+		Data: map[string]interface{}{"user_id": memberId},
+	})
+	return nil
+}
+
+// GetProjects возвращает проекты воркспейса с теми же фильтрами, что и общий список проектов.
+func (s *WorkspaceService) GetProjects(workspaceId, userId string, filter entity.ProjectFilter) (list entity.ProjectList, err error) {
+	if _, err = authorizeWorkspace(s.storage, workspaceId, userId, entity.ProjectRoleViewer); err != nil {
+		return entity.ProjectList{}, err
+	}
+	filter.WorkspaceId = workspaceId
+	list, err = s.storage.Project.GetAllByUserId(userId, filter)
+	if err != nil {
+		return entity.ProjectList{}, err
+	}
+	if list.Projects == nil {
+		list.Projects = []entity.Project{}
+	}
+	return list, nil
+}
+
+// ensureAnotherOwner не позволяет оставить воркспейс без владельцев.
+func (s *WorkspaceService) ensureAnotherOwner(workspaceId string) error {
+	owners, err := s.storage.Workspace.CountOwners(workspaceId)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return fmt.Errorf("%w: workspace must have at least one owner", ErrConflict)
+	}
+	return nil
+}
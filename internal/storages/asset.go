@@ -0,0 +1,261 @@
+package storages
+
+import (
+	"errors"
+	"time"
+
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/pkg/blobstore"
+	"ui-platform-backend-service/pkg/database"
+)
+
+type Asset interface {
+	GetAllByProjectId(projectId string) (assets []entity.Asset, err error)
+	GetById(projectId, assetId string) (asset entity.Asset, err error)
+	GetByHash(projectId, hash string) (asset entity.Asset, err error)
+	Create(asset *entity.Asset) (err error)
+	DeleteById(projectId, assetId string) (err error)
+	GetUsages(projectId, hash string) (usages []entity.AssetUsage, err error)
+	GetBlob(hash string) (blob entity.AssetBlob, err error)
+	SaveBlob(blob entity.AssetBlob, data, thumbnail []byte) (err error)
+	ReadBlob(hash string, thumbnail bool) (data []byte, err error)
+	DeleteOrphanBlobs(touchedBefore time.Time) (deleted int, err error)
+}
+
+// ErrBlobNotFound - файла ассета нет в хранилище.
+var ErrBlobNotFound = errors.New("asset blob not found")
+
+type AssetStorage struct {
+	postgres *database.PostgresDB
+	redis    *database.Redis
+	blobs    blobstore.Store
+}
+
+func NewAssetStorage(pg *database.PostgresDB, redis *database.Redis, blobs blobstore.Store) *AssetStorage {
+	return &AssetStorage{
+		postgres: pg,
+		redis:    redis,
+		blobs:    blobs,
+	}
+}
+
+// assetDocuments - документы проекта, в которых ищутся ссылки на ассеты: виджеты и настройки
+// головных версий веток экранов, живых релизов и последних версий компонентов проекта.
+const assetDocuments = `
+	WITH documents AS (
+		SELECT sc.id::text AS screen_id, sc.name AS screen_name, b.name AS branch_name, 0 AS release_number,
+			'' AS component_id, '' AS component_name, widget.id AS widget_id, widget.value::text AS body
+		FROM screens sc
+		JOIN screens_branches b ON b.screen_id = sc.id
+		JOIN LATERAL (
+			SELECT w.widgets, w.settings FROM screens_widgets w WHERE w.branch_id = b.id ORDER BY w.version DESC LIMIT 1
+		) head ON TRUE
+		CROSS JOIN LATERAL jsonb_each(head.widgets) AS widget(id, value)
+		WHERE sc.project_id = $1 AND sc.deleted_at IS NULL
+		UNION ALL
+		SELECT sc.id::text, sc.name, b.name, 0, '', '', '', head.settings::text
+		FROM screens sc
+		JOIN screens_branches b ON b.screen_id = sc.id
+		JOIN LATERAL (
+			SELECT w.settings FROM screens_widgets w WHERE w.branch_id = b.id ORDER BY w.version DESC LIMIT 1
+		) head ON TRUE
+		WHERE sc.project_id = $1 AND sc.deleted_at IS NULL
+		UNION ALL
+		SELECT sc.id::text, sc.name, '', r.release_number, '', '', widget.id, widget.value::text
+		FROM screens sc
+		JOIN screens_releases r ON r.screen_id = sc.id AND r.is_live
+		CROSS JOIN LATERAL jsonb_each(r.widgets) AS widget(id, value)
+		WHERE sc.project_id = $1 AND sc.deleted_at IS NULL
+		UNION ALL
+		SELECT sc.id::text, sc.name, '', r.release_number, '', '', '', r.settings::text
+		FROM screens sc
+		JOIN screens_releases r ON r.screen_id = sc.id AND r.is_live
+		WHERE sc.project_id = $1 AND sc.deleted_at IS NULL
+		UNION ALL
+		SELECT '', '', '', 0, c.id::text, c.name, widget.id, widget.value::text
+		FROM projects_components c
+		JOIN projects_component_versions v ON v.component_id = c.id AND v.version = c.version
+		CROSS JOIN LATERAL jsonb_each(v.widgets) AS widget(id, value)
+		WHERE c.project_id = $1
+	)
+`
+
+const assetSelect = `
+	SELECT a.id, a.project_id, a.name, a.hash, bl.kind, bl.mime_type, bl.size, bl.width, bl.height, bl.has_thumbnail,
+		COALESCE(a.created_by::text, '') AS created_by, a.created_at
+	FROM projects_assets a
+	JOIN assets_blobs bl ON bl.hash = a.hash
+`
+
+// GetAllByProjectId возвращает ассеты проекта с числом ссылок на каждый.
+func (s *AssetStorage) GetAllByProjectId(projectId string) (assets []entity.Asset, err error) {
+	query := assetDocuments + `
+		SELECT a.id, a.project_id, a.name, a.hash, bl.kind, bl.mime_type, bl.size, bl.width, bl.height, bl.has_thumbnail,
+			COALESCE(a.created_by::text, '') AS created_by, a.created_at,
+			(SELECT COUNT(*) FROM documents d WHERE strpos(d.body, a.hash) > 0) AS usages
+		FROM projects_assets a
+		JOIN assets_blobs bl ON bl.hash = a.hash
+		WHERE a.project_id = $1
+		ORDER BY a.name, a.id
+	`
+	err = s.postgres.DB.Select(&assets, query, projectId)
+	return assets, err
+}
+
+func (s *AssetStorage) GetById(projectId, assetId string) (asset entity.Asset, err error) {
+	err = s.postgres.DB.Get(&asset, assetSelect+` WHERE a.project_id = $1 AND a.id = $2`, projectId, assetId)
+	return asset, err
+}
+
+func (s *AssetStorage) GetByHash(projectId, hash string) (asset entity.Asset, err error) {
+	err = s.postgres.DB.Get(&asset, assetSelect+` WHERE a.project_id = $1 AND a.hash = $2`, projectId, hash)
+	return asset, err
+}
+
+// Create добавляет ассет в проект; содержимое должно быть сохранено SaveBlob.
+// Если файл уже загружен в проект, возвращается ErrAlreadyExists.
+func (s *AssetStorage) Create(asset *entity.Asset) (err error) {
+	query := `
+		INSERT INTO projects_assets (project_id, hash, name, created_by)
+		VALUES ($1, $2, $3, NULLIF($4, '')::uuid)
+		RETURNING id, created_at
+	`
+	err = s.postgres.DB.QueryRow(query, asset.ProjectId, asset.Hash, asset.Name, asset.CreatedBy).Scan(&asset.Id, &asset.CreatedAt)
+	if isUniqueViolation(err) {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+// DeleteById удаляет ассет из проекта. Содержимое удаляет DeleteOrphanBlobs,
+// когда на него не остается ссылок.
+func (s *AssetStorage) DeleteById(projectId, assetId string) (err error) {
+	res, err := s.postgres.DB.Exec(`DELETE FROM projects_assets WHERE project_id = $1 AND id = $2`, projectId, assetId)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+// GetUsages находит документы проекта, в которых встречается хеш ассета.
+func (s *AssetStorage) GetUsages(projectId, hash string) (usages []entity.AssetUsage, err error) {
+	query := assetDocuments + `
+		SELECT screen_id, screen_name, branch_name, release_number, component_id, component_name, widget_id
+		FROM documents
+		WHERE strpos(body, $2) > 0
+		ORDER BY screen_name, screen_id, release_number, branch_name, component_name, widget_id
+	`
+	err = s.postgres.DB.Select(&usages, query, projectId, hash)
+	return usages, err
+}
+
+// GetBlob возвращает описание содержимого, на которое ссылается хотя бы один ассет.
+func (s *AssetStorage) GetBlob(hash string) (blob entity.AssetBlob, err error) {
+	query := `
+		SELECT hash, kind, mime_type, size, width, height, has_thumbnail
+		FROM assets_blobs bl
+		WHERE hash = $1 AND EXISTS (SELECT 1 FROM projects_assets a WHERE a.hash = bl.hash)
+	`
+	err = s.postgres.DB.Get(&blob, query, hash)
+	return blob, err
+}
+
+// SaveBlob сохраняет содержимое и превью. Запись о содержимом обновляется до загрузки
+// файлов: так DeleteOrphanBlobs не удалит файлы, которые загружаются повторно.
+func (s *AssetStorage) SaveBlob(blob entity.AssetBlob, data, thumbnail []byte) (err error) {
+	query := `
+		INSERT INTO assets_blobs (hash, kind, mime_type, size, width, height, has_thumbnail)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (hash) DO UPDATE SET touched_at = NOW()
+	`
+	_, err = s.postgres.DB.Exec(query, blob.Hash, blob.Kind, blob.MimeType, blob.Size, blob.Width, blob.Height, blob.HasThumbnail)
+	if err != nil {
+		return err
+	}
+	if err = s.blobs.Put(assetKey(blob.Hash), data, blob.MimeType); err != nil {
+		return err
+	}
+	if thumbnail != nil {
+		return s.blobs.Put(assetThumbnailKey(blob.Hash), thumbnail, "image/png")
+	}
+	return nil
+}
+
+func (s *AssetStorage) ReadBlob(hash string, thumbnail bool) (data []byte, err error) {
+	key := assetKey(hash)
+	if thumbnail {
+		key = assetThumbnailKey(hash)
+	}
+	data, err = s.blobs.Get(key)
+	if errors.Is(err, blobstore.ErrNotFound) {
+		return nil, ErrBlobNotFound
+	}
+	return data, err
+}
+
+// DeleteOrphanBlobs удаляет содержимое, на которое не ссылается ни один ассет
+// и которое не загружалось с touchedBefore. Запись удаляется в транзакции вместе с файлами:
+// параллельная загрузка того же файла ждет блокировку строки и загружает файлы заново.
+func (s *AssetStorage) DeleteOrphanBlobs(touchedBefore time.Time) (deleted int, err error) {
+	var hashes []string
+	query := `
+		SELECT hash FROM assets_blobs bl
+		WHERE touched_at < $1 AND NOT EXISTS (SELECT 1 FROM projects_assets a WHERE a.hash = bl.hash)
+	`
+	if err = s.postgres.DB.Select(&hashes, query, touchedBefore); err != nil {
+		return 0, err
+	}
+	for _, hash := range hashes {
+		ok, err := s.deleteBlob(hash, touchedBefore)
+		if err != nil {
+			return deleted, err
+		}
+		if ok {
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (s *AssetStorage) deleteBlob(hash string, touchedBefore time.Time) (ok bool, err error) {
+	tx, err := s.postgres.DB.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil || !ok {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	query := `
+		DELETE FROM assets_blobs bl
+		WHERE hash = $1 AND touched_at < $2 AND NOT EXISTS (SELECT 1 FROM projects_assets a WHERE a.hash = bl.hash)
+	`
+	res, err := tx.Exec(query, hash, touchedBefore)
+	if err != nil {
+		return false, err
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+		// Ассет успели загрузить снова
+		return false, err
+	}
+	if err = s.blobs.Delete(assetKey(hash)); err != nil {
+		return false, err
+	}
+	if err = s.blobs.Delete(assetThumbnailKey(hash)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// assetKey раскладывает файлы по каталогам первых символов хеша.
+func assetKey(hash string) string {
+	return "assets/" + hash[:2] + "/" + hash
+}
+
+func assetThumbnailKey(hash string) string {
+	return "thumbnails/" + hash[:2] + "/" + hash + ".png"
+}
@@ -0,0 +1,204 @@
+package storages
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/pkg/database"
+)
+
+// ErrAlreadyExists возвращается при нарушении ограничения уникальности.
+var ErrAlreadyExists = errors.New("already exists")
+
+type Branch interface {
+	GetAllByScreenId(screenId string) (branches []entity.ScreenBranch, err error)
+	GetById(screenId, branchId string) (branch entity.ScreenBranch, err error)
+	GetByName(screenId, name string) (branch entity.ScreenBranch, err error)
+	GetAncestry(branchId string) (ancestry []entity.VersionRef, err error)
+	Create(screenId, userId string, params entity.ScreenBranchCreate) (branchId string, err error)
+	Rename(screenId, branchId, name string) (err error)
+	DeleteById(screenId, branchId string) (err error)
+}
+
+type BranchStorage struct {
+	postgres *database.PostgresDB
+	redis    *database.Redis
+}
+
+func NewBranchStorage(pg *database.PostgresDB, redis *database.Redis) *BranchStorage {
+	return &BranchStorage{
+		postgres: pg,
+		redis:    redis,
+	}
+}
+
+// branchSelect выбирает ветки вместе с номером их головной версии.
+const branchSelect = `
+	SELECT b.id, b.screen_id, b.name,
+		COALESCE(b.parent_branch_id::text, '') AS parent_branch_id,
+		COALESCE(b.parent_version, 0) AS parent_version,
+		COALESCE((SELECT MAX(w.version) FROM screens_widgets w WHERE w.branch_id = b.id), 0) AS head_version,
+		COALESCE(b.created_by::text, '') AS created_by,
+		b.created_at,
+		COALESCE(b.updated_at, b.created_at) AS updated_at
+	FROM screens_branches b
+`
+
+func (s *BranchStorage) GetAllByScreenId(screenId string) (branches []entity.ScreenBranch, err error) {
+	query := branchSelect + ` WHERE b.screen_id = $1 ORDER BY b.created_at, b.id`
+	err = s.postgres.DB.Select(&branches, query, screenId)
+	return branches, err
+}
+
+func (s *BranchStorage) GetById(screenId, branchId string) (branch entity.ScreenBranch, err error) {
+	query := branchSelect + ` WHERE b.screen_id = $1 AND b.id = $2`
+	err = s.postgres.DB.Get(&branch, query, screenId, branchId)
+	return branch, err
+}
+
+func (s *BranchStorage) GetByName(screenId, name string) (branch entity.ScreenBranch, err error) {
+	query := branchSelect + ` WHERE b.screen_id = $1 AND b.name = $2`
+	err = s.postgres.DB.Get(&branch, query, screenId, name)
+	return branch, err
+}
+
+// GetAncestry возвращает точки ответвления ветки от родителя до корня. Точки сохраняются
+// и после удаления веток-предков. Если ветки нет, возвращается sql.ErrNoRows.
+func (s *BranchStorage) GetAncestry(branchId string) (ancestry []entity.VersionRef, err error) {
+	var document []byte
+	err = s.postgres.DB.QueryRow(`SELECT ancestry FROM screens_branches WHERE id = $1`, branchId).Scan(&document)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(document, &ancestry)
+	return ancestry, err
+}
+
+// Create создает ветку из версии исходной ветки. Содержимое исходной версии
+// становится первой версией новой ветки.
+func (s *BranchStorage) Create(screenId, userId string, params entity.ScreenBranchCreate) (branchId string, err error) {
+	tx, err := s.postgres.DB.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	// 1. Находим исходную версию
+	sourceQuery := `
+		SELECT w.version, w.widgets, w.settings
+		FROM screens_widgets w
+		JOIN screens_branches b ON b.id = w.branch_id
+		WHERE b.screen_id = $1 AND w.branch_id = $2 AND ($3 = 0 OR w.version = $3)
+		ORDER BY w.version DESC
+		LIMIT 1
+	`
+	var version int
+	var widgets, settings []byte
+	err = tx.QueryRow(sourceQuery, screenId, params.SourceBranchId, params.SourceVersion).Scan(&version, &widgets, &settings)
+	if err != nil {
+		return "", err
+	}
+
+	// 2. Создаём ветку
+	// Точки ответвления: исходная версия, затем предки исходной ветки
+	branchQuery := `
+		INSERT INTO screens_branches (screen_id, name, parent_branch_id, parent_version, created_by, created_at, ancestry)
+		SELECT $1::uuid, $2, $3::uuid, $4::int, $5::uuid, $6::timestamp, jsonb_build_array(jsonb_build_object('branch_id', $3::uuid, 'version', $4::int)) || ancestry
+		FROM screens_branches
+		WHERE id = $3::uuid
+		RETURNING id
+	`
+	err = tx.QueryRow(branchQuery, screenId, params.Name, params.SourceBranchId, version, userId, time.Now().UTC()).Scan(&branchId)
+	if err != nil {
+		if isUniqueViolation(err) {
+			err = ErrAlreadyExists
+		}
+		return "", err
+	}
+
+	// 3. Копируем содержимое исходной версии
+	versionQuery := "INSERT INTO screens_widgets (branch_id, widgets, settings, version) VALUES ($1, $2, $3, 1)"
+	_, err = tx.Exec(versionQuery, branchId, widgets, settings)
+	if err != nil {
+		return "", err
+	}
+
+	return branchId, nil
+}
+
+func (s *BranchStorage) Rename(screenId, branchId, name string) (err error) {
+	query := `UPDATE screens_branches SET name = $3, updated_at = $4 WHERE screen_id = $1 AND id = $2`
+	res, err := s.postgres.DB.Exec(query, screenId, branchId, name, time.Now().UTC())
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrAlreadyExists
+		}
+		return err
+	}
+	return checkAffected(res)
+}
+
+// DeleteById удаляет ветку вместе со всеми ее версиями. Обсуждения ветки остаются
+// в истории ревью и показываются оторванными. Дочерние ветки переходят к родителю
+// удаляемой ветки (точки ответвления остаются в ancestry), открытые запросы
+// на слияние из ветки закрываются.
+func (s *BranchStorage) DeleteById(screenId, branchId string) (err error) {
+	tx, err := s.postgres.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	now := time.Now().UTC()
+	reparentQuery := `
+		UPDATE screens_branches c
+		SET parent_branch_id = b.parent_branch_id, parent_version = b.parent_version, updated_at = $3
+		FROM screens_branches b
+		WHERE b.screen_id = $1 AND b.id = $2 AND c.parent_branch_id = b.id
+	`
+	if _, err = tx.Exec(reparentQuery, screenId, branchId, now); err != nil {
+		return err
+	}
+
+	var res sql.Result
+	res, err = tx.Exec(`DELETE FROM screens_branches WHERE screen_id = $1 AND id = $2`, screenId, branchId)
+	if err != nil {
+		return err
+	}
+	if err = checkAffected(res); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`DELETE FROM screens_widgets WHERE branch_id = $1`, branchId)
+	if err != nil {
+		return err
+	}
+	closeQuery := `
+		UPDATE screens_change_requests
+		SET status = $3, updated_at = $4
+		WHERE screen_id = $1 AND (source_branch_id = $2 OR target_branch_id = $2) AND status = 'open'
+	`
+	_, err = tx.Exec(closeQuery, screenId, branchId, entity.ChangeRequestClosed, now)
+	return err
+}
+
+// isUniqueViolation сообщает, является ли ошибка нарушением ограничения уникальности.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
@@ -0,0 +1,116 @@
+package storages
+
+import (
+	"time"
+
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/pkg/database"
+)
+
+type ChangeRequest interface {
+	GetAllByScreenId(screenId, status string) (requests []entity.ChangeRequest, err error)
+	GetById(screenId, requestId string) (request entity.ChangeRequest, err error)
+	Create(request *entity.ChangeRequest) (err error)
+	SetApproval(requestId, userId string, sourceVersion int) (err error)
+	Close(screenId, requestId string) (err error)
+	SetMerged(screenId, requestId, userId string, version int) (err error)
+}
+
+type ChangeRequestStorage struct {
+	postgres *database.PostgresDB
+	redis    *database.Redis
+}
+
+func NewChangeRequestStorage(pg *database.PostgresDB, redis *database.Redis) *ChangeRequestStorage {
+	return &ChangeRequestStorage{
+		postgres: pg,
+		redis:    redis,
+	}
+}
+
+const changeRequestSelect = `
+	SELECT id, screen_id, source_branch_id, target_branch_id, title, description, status,
+		COALESCE(created_by::text, '') AS created_by, created_at, updated_at,
+		COALESCE(merged_by::text, '') AS merged_by, merged_at, COALESCE(merged_version, 0) AS merged_version
+	FROM screens_change_requests
+`
+
+// GetAllByScreenId возвращает запросы экрана без одобрений, новые первыми. Пустой статус - все запросы.
+func (s *ChangeRequestStorage) GetAllByScreenId(screenId, status string) (requests []entity.ChangeRequest, err error) {
+	query := changeRequestSelect + ` WHERE screen_id = $1 AND ($2 = '' OR status = $2) ORDER BY created_at DESC`
+	err = s.postgres.DB.Select(&requests, query, screenId, status)
+	return requests, err
+}
+
+// GetById возвращает запрос вместе с одобрениями.
+func (s *ChangeRequestStorage) GetById(screenId, requestId string) (request entity.ChangeRequest, err error) {
+	if err = s.postgres.DB.Get(&request, changeRequestSelect+` WHERE screen_id = $1 AND id = $2`, screenId, requestId); err != nil {
+		return entity.ChangeRequest{}, err
+	}
+	query := `
+		SELECT user_id, source_version, created_at
+		FROM screens_change_request_approvals
+		WHERE change_request_id = $1
+		ORDER BY created_at
+	`
+	if err = s.postgres.DB.Select(&request.Approvals, query, requestId); err != nil {
+		return entity.ChangeRequest{}, err
+	}
+	return request, nil
+}
+
+// Create сохраняет открытый запрос. Если у ветки уже есть открытый запрос, возвращается ErrAlreadyExists.
+func (s *ChangeRequestStorage) Create(request *entity.ChangeRequest) (err error) {
+	query := `
+		INSERT INTO screens_change_requests (screen_id, source_branch_id, target_branch_id, title, description, status, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::uuid)
+		RETURNING id, created_at, updated_at
+	`
+	err = s.postgres.DB.QueryRow(query, request.ScreenId, request.SourceBranchId, request.TargetBranchId, request.Title,
+		request.Description, request.Status, request.CreatedBy).Scan(&request.Id, &request.CreatedAt, &request.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+// SetApproval сохраняет одобрение пользователя или переносит его на новую версию источника.
+func (s *ChangeRequestStorage) SetApproval(requestId, userId string, sourceVersion int) (err error) {
+	query := `
+		INSERT INTO screens_change_request_approvals (change_request_id, user_id, source_version, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (change_request_id, user_id) DO UPDATE
+		SET source_version = EXCLUDED.source_version, created_at = EXCLUDED.created_at
+	`
+	_, err = s.postgres.DB.Exec(query, requestId, userId, sourceVersion, time.Now().UTC())
+	return err
+}
+
+// Close закрывает открытый запрос без слияния.
+func (s *ChangeRequestStorage) Close(screenId, requestId string) (err error) {
+	query := `
+		UPDATE screens_change_requests
+		SET status = $3, updated_at = $4
+		WHERE screen_id = $1 AND id = $2 AND status = 'open'
+	`
+	res, err := s.postgres.DB.Exec(query, screenId, requestId, entity.ChangeRequestClosed, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+// SetMerged отмечает открытый запрос слитым в версию version целевой ветки.
+func (s *ChangeRequestStorage) SetMerged(screenId, requestId, userId string, version int) (err error) {
+	now := time.Now().UTC()
+	query := `
+		UPDATE screens_change_requests
+		SET status = $3, merged_by = NULLIF($4, '')::uuid, merged_at = $5, merged_version = $6, updated_at = $5
+		WHERE screen_id = $1 AND id = $2 AND status = 'open'
+	`
+	res, err := s.postgres.DB.Exec(query, screenId, requestId, entity.ChangeRequestMerged, userId, now, version)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
@@ -0,0 +1,177 @@
+package storages
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/pkg/database"
+)
+
+// collabKeyTTL - сколько живут служебные ключи сессии без обновлений.
+const collabKeyTTL = time.Hour
+
+type Collab interface {
+	Publish(branchId string, payload []byte) (err error)
+	Subscribe(branchId string) (messages <-chan []byte, unsubscribe func() error, err error)
+	SetPresence(branchId string, presence entity.CollabPresence) (err error)
+	RemovePresence(branchId, clientId string) (err error)
+	GetPresence(branchId string) (peers []entity.CollabPresence, err error)
+	AcquireLock(branchId string, lock entity.CollabLock) (holder entity.CollabLock, acquired bool, err error)
+	ReleaseLock(branchId, widgetId, clientId string) (err error)
+	GetLocks(branchId string) (locks []entity.CollabLock, err error)
+	AcquireSnapshotLock(branchId string, ttl time.Duration) (acquired bool, err error)
+}
+
+type CollabStorage struct {
+	redis *database.Redis
+}
+
+func NewCollabStorage(redis *database.Redis) *CollabStorage {
+	return &CollabStorage{
+		redis: redis,
+	}
+}
+
+func (s *CollabStorage) Publish(branchId string, payload []byte) (err error) {
+	return s.redis.Client.Publish(collabChannel(branchId), payload).Err()
+}
+
+// Subscribe подписывается на канал ветки. Канал сообщений закрывается после unsubscribe.
+func (s *CollabStorage) Subscribe(branchId string) (messages <-chan []byte, unsubscribe func() error, err error) {
+	pubsub := s.redis.Client.Subscribe(collabChannel(branchId))
+	// Дожидаемся подтверждения подписки, чтобы не потерять первые сообщения
+	if _, err = pubsub.Receive(); err != nil {
+		_ = pubsub.Close()
+		return nil, nil, err
+	}
+	out := make(chan []byte, 256)
+	go func() {
+		defer close(out)
+		for message := range pubsub.Channel() {
+			out <- []byte(message.Payload)
+		}
+	}()
+	return out, pubsub.Close, nil
+}
+
+func (s *CollabStorage) SetPresence(branchId string, presence entity.CollabPresence) (err error) {
+	data, err := json.Marshal(presence)
+	if err != nil {
+		return err
+	}
+	key := collabPresenceKey(branchId)
+	if err = s.redis.Client.HSet(key, presence.ClientId, data).Err(); err != nil {
+		return err
+	}
+	return s.redis.Client.Expire(key, collabKeyTTL).Err()
+}
+
+func (s *CollabStorage) RemovePresence(branchId, clientId string) (err error) {
+	return s.redis.Client.HDel(collabPresenceKey(branchId), clientId).Err()
+}
+
+func (s *CollabStorage) GetPresence(branchId string) (peers []entity.CollabPresence, err error) {
+	values, err := s.redis.Client.HGetAll(collabPresenceKey(branchId)).Result()
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range values {
+		var presence entity.CollabPresence
+		if err = json.Unmarshal([]byte(value), &presence); err != nil {
+			return nil, err
+		}
+		peers = append(peers, presence)
+	}
+	return peers, nil
+}
+
+// AcquireLock берет блокировку виджета до lock.ExpiresAt или продлевает свою.
+// Если виджет заблокирован другим участником, возвращается его блокировка.
+func (s *CollabStorage) AcquireLock(branchId string, lock entity.CollabLock) (holder entity.CollabLock, acquired bool, err error) {
+	data, err := json.Marshal(lock)
+	if err != nil {
+		return entity.CollabLock{}, false, err
+	}
+	key := collabLockKey(branchId, lock.WidgetId)
+	ttl := time.Until(lock.ExpiresAt)
+
+	acquired, err = s.redis.Client.SetNX(key, data, ttl).Result()
+	if err != nil || acquired {
+		return lock, acquired, err
+	}
+	current, err := s.redis.Client.Get(key).Bytes()
+	if err == redis.Nil {
+		// Блокировка истекла между SETNX и GET - пробуем еще раз
+		acquired, err = s.redis.Client.SetNX(key, data, ttl).Result()
+		return lock, acquired, err
+	}
+	if err != nil {
+		return entity.CollabLock{}, false, err
+	}
+	if err = json.Unmarshal(current, &holder); err != nil {
+		return entity.CollabLock{}, false, err
+	}
+	if holder.ClientId != lock.ClientId {
+		return holder, false, nil
+	}
+	return lock, true, s.redis.Client.Set(key, data, ttl).Err()
+}
+
+// ReleaseLock снимает блокировку виджета, если она принадлежит клиенту clientId.
+func (s *CollabStorage) ReleaseLock(branchId, widgetId, clientId string) (err error) {
+	key := collabLockKey(branchId, widgetId)
+	current, err := s.redis.Client.Get(key).Bytes()
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var holder entity.CollabLock
+	if err = json.Unmarshal(current, &holder); err != nil {
+		return err
+	}
+	if holder.ClientId != clientId {
+		return nil
+	}
+	return s.redis.Client.Del(key).Err()
+}
+
+func (s *CollabStorage) GetLocks(branchId string) (locks []entity.CollabLock, err error) {
+	iter := s.redis.Client.Scan(0, collabLockKey(branchId, "*"), 100).Iterator()
+	for iter.Next() {
+		data, err := s.redis.Client.Get(iter.Val()).Bytes()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var lock entity.CollabLock
+		if err = json.Unmarshal(data, &lock); err != nil {
+			return nil, err
+		}
+		locks = append(locks, lock)
+	}
+	return locks, iter.Err()
+}
+
+// AcquireSnapshotLock не дает нескольким экземплярам сервиса одновременно сохранять снимок ветки.
+func (s *CollabStorage) AcquireSnapshotLock(branchId string, ttl time.Duration) (acquired bool, err error) {
+	return s.redis.Client.SetNX(fmt.Sprintf("collab_snapshot:%s", branchId), 1, ttl).Result()
+}
+
+func collabChannel(branchId string) string {
+	return fmt.Sprintf("collab:%s", branchId)
+}
+
+func collabPresenceKey(branchId string) string {
+	return fmt.Sprintf("collab_presence:%s", branchId)
+}
+
+func collabLockKey(branchId, widgetId string) string {
+	return fmt.Sprintf("collab_lock:%s:%s", branchId, widgetId)
+}
@@ -0,0 +1,221 @@
+package storages
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/pkg/database"
+)
+
+type Comment interface {
+	GetThreads(screenId string, filter entity.CommentThreadFilter) (threads []entity.CommentThread, err error)
+	GetThread(screenId, threadId string) (thread entity.CommentThread, err error)
+	CreateThread(thread *entity.CommentThread, comment *entity.Comment) (err error)
+	AddComment(comment *entity.Comment) (err error)
+	SetThreadStatus(screenId, threadId, status, userId string) (err error)
+}
+
+type CommentStorage struct {
+	postgres *database.PostgresDB
+	redis    *database.Redis
+}
+
+func NewCommentStorage(pg *database.PostgresDB, redis *database.Redis) *CommentStorage {
+	return &CommentStorage{
+		postgres: pg,
+		redis:    redis,
+	}
+}
+
+const commentThreadSelect = `
+	SELECT id, screen_id, branch_id, version, widget_id, widget_type, widget_props, widget_parent, widget_index,
+		status, COALESCE(created_by::text, ''), created_at, COALESCE(resolved_by::text, ''), resolved_at
+	FROM screens_comment_threads
+`
+
+// GetThreads возвращает обсуждения экрана с сообщениями, новые первыми.
+func (s *CommentStorage) GetThreads(screenId string, filter entity.CommentThreadFilter) (threads []entity.CommentThread, err error) {
+	conditions := []string{"screen_id = $1"}
+	args := []interface{}{screenId}
+	if filter.BranchId != "" {
+		args = append(args, filter.BranchId)
+		conditions = append(conditions, fmt.Sprintf("branch_id = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	rows, err := s.postgres.DB.Query(commentThreadSelect+` WHERE `+strings.Join(conditions, " AND ")+` ORDER BY created_at DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	index := map[string]int{}
+	for rows.Next() {
+		thread, err := scanCommentThread(rows)
+		if err != nil {
+			return nil, err
+		}
+		index[thread.Id] = len(threads)
+		threads = append(threads, thread)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(threads) == 0 {
+		return threads, nil
+	}
+
+	// Сообщения всех обсуждений экрана одним запросом
+	comments, err := s.getComments(`
+		SELECT c.id, c.thread_id, COALESCE(c.author_id::text, ''), c.body, c.mentions, c.created_at
+		FROM screens_comments c
+		JOIN screens_comment_threads t ON t.id = c.thread_id
+		WHERE t.screen_id = $1
+		ORDER BY c.created_at, c.id
+	`, screenId)
+	if err != nil {
+		return nil, err
+	}
+	for _, comment := range comments {
+		if i, ok := index[comment.ThreadId]; ok {
+			threads[i].Comments = append(threads[i].Comments, comment)
+		}
+	}
+	return threads, nil
+}
+
+func (s *CommentStorage) GetThread(screenId, threadId string) (thread entity.CommentThread, err error) {
+	row := s.postgres.DB.QueryRow(commentThreadSelect+` WHERE screen_id = $1 AND id = $2`, screenId, threadId)
+	thread, err = scanCommentThread(row)
+	if err != nil {
+		return entity.CommentThread{}, err
+	}
+	thread.Comments, err = s.getComments(`
+		SELECT id, thread_id, COALESCE(author_id::text, ''), body, mentions, created_at
+		FROM screens_comments
+		WHERE thread_id = $1
+		ORDER BY created_at, id
+	`, threadId)
+	if err != nil {
+		return entity.CommentThread{}, err
+	}
+	return thread, nil
+}
+
+// CreateThread сохраняет обсуждение вместе с первым сообщением и заполняет их id и время создания.
+func (s *CommentStorage) CreateThread(thread *entity.CommentThread, comment *entity.Comment) (err error) {
+	props, err := marshalDocument(thread.WidgetProps)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.postgres.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	query := `
+		INSERT INTO screens_comment_threads (screen_id, branch_id, version, widget_id, widget_type, widget_props,
+			widget_parent, widget_index, status, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, '')::uuid)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRow(query, thread.ScreenId, thread.BranchId, thread.Version, thread.WidgetId, thread.WidgetType, props,
+		thread.Position.Parent, thread.Position.Index, thread.Status, thread.CreatedBy).Scan(&thread.Id, &thread.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	comment.ThreadId = thread.Id
+	return insertComment(tx, comment)
+}
+
+func (s *CommentStorage) AddComment(comment *entity.Comment) (err error) {
+	return insertComment(s.postgres.DB, comment)
+}
+
+// SetThreadStatus закрывает или заново открывает обсуждение.
+func (s *CommentStorage) SetThreadStatus(screenId, threadId, status, userId string) (err error) {
+	query := `
+		UPDATE screens_comment_threads
+		SET status = $3,
+			resolved_by = CASE WHEN $3 = 'resolved' THEN NULLIF($4, '')::uuid END,
+			resolved_at = CASE WHEN $3 = 'resolved' THEN $5::timestamp END
+		WHERE screen_id = $1 AND id = $2
+	`
+	res, err := s.postgres.DB.Exec(query, screenId, threadId, status, userId, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+func (s *CommentStorage) getComments(query string, args ...interface{}) (comments []entity.Comment, err error) {
+	rows, err := s.postgres.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var comment entity.Comment
+		var mentions []byte
+		err = rows.Scan(&comment.Id, &comment.ThreadId, &comment.AuthorId, &comment.Body, &mentions, &comment.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(mentions, &comment.Mentions); err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	return comments, rows.Err()
+}
+
+// rowQuerier - *sql.DB или *sql.Tx
+type rowQuerier interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func insertComment(db rowQuerier, comment *entity.Comment) error {
+	if comment.Mentions == nil {
+		comment.Mentions = []string{}
+	}
+	mentions, err := json.Marshal(comment.Mentions)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO screens_comments (thread_id, author_id, body, mentions)
+		VALUES ($1, NULLIF($2, '')::uuid, $3, $4)
+		RETURNING id, created_at
+	`
+	return db.QueryRow(query, comment.ThreadId, comment.AuthorId, comment.Body, mentions).Scan(&comment.Id, &comment.CreatedAt)
+}
+
+func scanCommentThread(row rowScanner) (thread entity.CommentThread, err error) {
+	var props []byte
+	err = row.Scan(&thread.Id, &thread.ScreenId, &thread.BranchId, &thread.Version, &thread.WidgetId, &thread.WidgetType, &props,
+		&thread.Position.Parent, &thread.Position.Index, &thread.Status, &thread.CreatedBy, &thread.CreatedAt,
+		&thread.ResolvedBy, &thread.ResolvedAt)
+	if err != nil {
+		return entity.CommentThread{}, err
+	}
+	if err = json.Unmarshal(props, &thread.WidgetProps); err != nil {
+		return entity.CommentThread{}, err
+	}
+	return thread, nil
+}
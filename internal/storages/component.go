@@ -0,0 +1,210 @@
+package storages
+
+import (
+	"encoding/json"
+	"time"
+
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/pkg/database"
+)
+
+type Component interface {
+	GetAllByScope(projectId, workspaceId string) (components []entity.Component, err error)
+	GetById(componentId string) (component entity.Component, err error)
+	GetVersion(componentId string, version int) (componentVersion entity.ComponentVersion, err error)
+	GetVersions(componentId string) (versions []entity.ComponentVersion, err error)
+	Create(component *entity.Component, version *entity.ComponentVersion) (err error)
+	CreateVersion(version *entity.ComponentVersion) (err error)
+	DeleteById(componentId string) (err error)
+	GetUsages(componentId, projectId, workspaceId string) (usages []entity.ComponentUsage, err error)
+}
+
+type ComponentStorage struct {
+	postgres *database.PostgresDB
+	redis    *database.Redis
+}
+
+func NewComponentStorage(pg *database.PostgresDB, redis *database.Redis) *ComponentStorage {
+	return &ComponentStorage{
+		postgres: pg,
+		redis:    redis,
+	}
+}
+
+const componentSelect = `
+	SELECT id, COALESCE(project_id::text, '') AS project_id, COALESCE(workspace_id::text, '') AS workspace_id,
+		name, description, version, COALESCE(created_by::text, '') AS created_by, created_at, updated_at
+	FROM projects_components
+`
+
+// GetAllByScope возвращает компоненты проекта и компоненты воркспейса.
+// Пустой идентификатор исключает соответствующий уровень.
+func (s *ComponentStorage) GetAllByScope(projectId, workspaceId string) (components []entity.Component, err error) {
+	query := componentSelect + `
+		WHERE project_id = NULLIF($1, '')::uuid OR workspace_id = NULLIF($2, '')::uuid
+		ORDER BY name, id
+	`
+	err = s.postgres.DB.Select(&components, query, projectId, workspaceId)
+	return components, err
+}
+
+func (s *ComponentStorage) GetById(componentId string) (component entity.Component, err error) {
+	err = s.postgres.DB.Get(&component, componentSelect+` WHERE id = $1`, componentId)
+	return component, err
+}
+
+// GetVersion возвращает версию компонента с виджетами; нулевая версия - последняя.
+func (s *ComponentStorage) GetVersion(componentId string, version int) (componentVersion entity.ComponentVersion, err error) {
+	query := `
+		SELECT v.component_id, v.version, v.root, v.widgets, v.message, COALESCE(v.created_by::text, ''), v.created_at
+		FROM projects_component_versions v
+		JOIN projects_components c ON c.id = v.component_id
+		WHERE v.component_id = $1 AND v.version = CASE WHEN $2 = 0 THEN c.version ELSE $2 END
+	`
+	var widgets []byte
+	err = s.postgres.DB.QueryRow(query, componentId, version).Scan(&componentVersion.ComponentId, &componentVersion.Version,
+		&componentVersion.Root, &widgets, &componentVersion.Message, &componentVersion.CreatedBy, &componentVersion.CreatedAt)
+	if err != nil {
+		return entity.ComponentVersion{}, err
+	}
+	if err = json.Unmarshal(widgets, &componentVersion.Widgets); err != nil {
+		return entity.ComponentVersion{}, err
+	}
+	return componentVersion, nil
+}
+
+// GetVersions возвращает историю версий компонента без виджетов, новые первыми.
+func (s *ComponentStorage) GetVersions(componentId string) (versions []entity.ComponentVersion, err error) {
+	query := `
+		SELECT component_id, version, root, message, COALESCE(created_by::text, '') AS created_by, created_at
+		FROM projects_component_versions
+		WHERE component_id = $1
+		ORDER BY version DESC
+	`
+	err = s.postgres.DB.Select(&versions, query, componentId)
+	return versions, err
+}
+
+// Create сохраняет компонент вместе с первой версией.
+func (s *ComponentStorage) Create(component *entity.Component, version *entity.ComponentVersion) (err error) {
+	widgets, err := marshalDocument(version.Widgets)
+	if err != nil {
+		return err
+	}
+	tx, err := s.postgres.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	query := `
+		INSERT INTO projects_components (project_id, workspace_id, name, description, version, created_by)
+		VALUES (NULLIF($1, '')::uuid, NULLIF($2, '')::uuid, $3, $4, 1, NULLIF($5, '')::uuid)
+		RETURNING id, version, created_at, updated_at
+	`
+	err = tx.QueryRow(query, component.ProjectId, component.WorkspaceId, component.Name, component.Description, component.CreatedBy).
+		Scan(&component.Id, &component.Version, &component.CreatedAt, &component.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	version.ComponentId, version.Version, version.CreatedAt = component.Id, component.Version, component.CreatedAt
+	versionQuery := `
+		INSERT INTO projects_component_versions (component_id, version, root, widgets, message, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::uuid, $7)
+	`
+	_, err = tx.Exec(versionQuery, version.ComponentId, version.Version, version.Root, widgets, version.Message, version.CreatedBy, version.CreatedAt)
+	return err
+}
+
+// CreateVersion сохраняет версию с заданным номером и делает ее последней.
+// Если номер уже занят, возвращается ErrAlreadyExists.
+func (s *ComponentStorage) CreateVersion(version *entity.ComponentVersion) (err error) {
+	widgets, err := marshalDocument(version.Widgets)
+	if err != nil {
+		return err
+	}
+	tx, err := s.postgres.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	version.CreatedAt = time.Now().UTC()
+	query := `
+		INSERT INTO projects_component_versions (component_id, version, root, widgets, message, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::uuid, $7)
+	`
+	_, err = tx.Exec(query, version.ComponentId, version.Version, version.Root, widgets, version.Message, version.CreatedBy, version.CreatedAt)
+	if isUniqueViolation(err) {
+		return ErrAlreadyExists
+	}
+	if err != nil {
+		return err
+	}
+	res, err := tx.Exec(`UPDATE projects_components SET version = $2, updated_at = $3 WHERE id = $1`,
+		version.ComponentId, version.Version, version.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+func (s *ComponentStorage) DeleteById(componentId string) (err error) {
+	res, err := s.postgres.DB.Exec(`DELETE FROM projects_components WHERE id = $1`, componentId)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+// GetUsages находит экземпляры компонента в головных версиях веток экранов проекта
+// или проектов воркспейса и в последних версиях других компонентов.
+func (s *ComponentStorage) GetUsages(componentId, projectId, workspaceId string) (usages []entity.ComponentUsage, err error) {
+	screensQuery := `
+		SELECT sc.project_id, sc.id AS screen_id, sc.name AS screen_name, b.name AS branch_name, widget.id AS widget_id,
+			COALESCE((widget.value->'props'->>'version')::numeric::int, 0) AS version
+		FROM screens sc
+		JOIN projects p ON p.id = sc.project_id AND p.deleted_at IS NULL
+		JOIN screens_branches b ON b.screen_id = sc.id
+		JOIN LATERAL (
+			SELECT w.widgets FROM screens_widgets w WHERE w.branch_id = b.id ORDER BY w.version DESC LIMIT 1
+		) head ON TRUE
+		CROSS JOIN LATERAL jsonb_each(head.widgets) AS widget(id, value)
+		WHERE sc.deleted_at IS NULL
+			AND (sc.project_id = NULLIF($2, '')::uuid OR p.workspace_id = NULLIF($3, '')::uuid)
+			AND widget.value->>'type' = $4 AND widget.value->'props'->>'component_id' = $1
+		ORDER BY sc.project_id, sc.name, b.name, widget.id
+	`
+	err = s.postgres.DB.Select(&usages, screensQuery, componentId, projectId, workspaceId, entity.WidgetTypeComponent)
+	if err != nil {
+		return nil, err
+	}
+
+	var nested []entity.ComponentUsage
+	componentsQuery := `
+		SELECT c.id AS component_id, c.name AS component_name, widget.id AS widget_id,
+			COALESCE((widget.value->'props'->>'version')::numeric::int, 0) AS version
+		FROM projects_components c
+		JOIN projects_component_versions v ON v.component_id = c.id AND v.version = c.version
+		CROSS JOIN LATERAL jsonb_each(v.widgets) AS widget(id, value)
+		WHERE c.id::text <> $1 AND widget.value->>'type' = $2 AND widget.value->'props'->>'component_id' = $1
+		ORDER BY c.name, widget.id
+	`
+	if err = s.postgres.DB.Select(&nested, componentsQuery, componentId, entity.WidgetTypeComponent); err != nil {
+		return nil, err
+	}
+	return append(usages, nested...), nil
+}
@@ -0,0 +1,201 @@
+package storages
+
+import (
+	"encoding/json"
+	"time"
+
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/pkg/database"
+)
+
+type DataSource interface {
+	GetAllByProjectId(projectId string) (sources []entity.DataSource, err error)
+	GetNames(projectId string) (names []string, err error)
+	GetByName(projectId, name string) (source entity.DataSource, err error)
+	Create(source *entity.DataSource) (err error)
+	Update(source *entity.DataSource) (err error)
+	SaveSample(projectId, name string, sample interface{}) (recordedAt time.Time, err error)
+	DeleteByName(projectId, name string) (err error)
+	IsUsed(projectId, name string) (used bool, err error)
+}
+
+type DataSourceStorage struct {
+	postgres *database.PostgresDB
+	redis    *database.Redis
+}
+
+func NewDataSourceStorage(pg *database.PostgresDB, redis *database.Redis) *DataSourceStorage {
+	return &DataSourceStorage{
+		postgres: pg,
+		redis:    redis,
+	}
+}
+
+// GetAllByProjectId возвращает источники проекта без записанных ответов.
+func (s *DataSourceStorage) GetAllByProjectId(projectId string) (sources []entity.DataSource, err error) {
+	query := `
+		SELECT project_id, name, description, kind, rest, data, NULL::jsonb, recorded_at,
+			COALESCE(created_by::text, ''), created_at, updated_at
+		FROM projects_data_sources
+		WHERE project_id = $1
+		ORDER BY name
+	`
+	rows, err := s.postgres.DB.Query(query, projectId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		source, err := scanDataSource(rows)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return sources, rows.Err()
+}
+
+func (s *DataSourceStorage) GetNames(projectId string) (names []string, err error) {
+	err = s.postgres.DB.Select(&names, `SELECT name FROM projects_data_sources WHERE project_id = $1`, projectId)
+	return names, err
+}
+
+func (s *DataSourceStorage) GetByName(projectId, name string) (source entity.DataSource, err error) {
+	query := `
+		SELECT project_id, name, description, kind, rest, data, sample, recorded_at,
+			COALESCE(created_by::text, ''), created_at, updated_at
+		FROM projects_data_sources
+		WHERE project_id = $1 AND name = $2
+	`
+	return scanDataSource(s.postgres.DB.QueryRow(query, projectId, name))
+}
+
+// Create сохраняет источник. Если имя уже занято в проекте, возвращается ErrAlreadyExists.
+func (s *DataSourceStorage) Create(source *entity.DataSource) (err error) {
+	rest, data, err := marshalDataSource(source)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO projects_data_sources (project_id, name, description, kind, rest, data, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::uuid)
+		RETURNING created_at, updated_at
+	`
+	err = s.postgres.DB.QueryRow(query, source.ProjectId, source.Name, source.Description, source.Kind, rest, data, source.CreatedBy).
+		Scan(&source.CreatedAt, &source.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+// Update меняет описание, вид и настройки источника. Записанный ответ сохраняется,
+// пока источник остается REST-источником. Если источника нет, возвращается sql.ErrNoRows.
+func (s *DataSourceStorage) Update(source *entity.DataSource) (err error) {
+	rest, data, err := marshalDataSource(source)
+	if err != nil {
+		return err
+	}
+	query := `
+		UPDATE projects_data_sources
+		SET description = $3, kind = $4, rest = $5, data = $6,
+			sample = CASE WHEN $4 = 'rest' THEN sample END,
+			recorded_at = CASE WHEN $4 = 'rest' THEN recorded_at END,
+			updated_at = NOW()
+		WHERE project_id = $1 AND name = $2
+		RETURNING recorded_at, COALESCE(created_by::text, ''), created_at, updated_at
+	`
+	return s.postgres.DB.QueryRow(query, source.ProjectId, source.Name, source.Description, source.Kind, rest, data).
+		Scan(&source.RecordedAt, &source.CreatedBy, &source.CreatedAt, &source.UpdatedAt)
+}
+
+// SaveSample сохраняет записанный ответ REST-источника. Если источника нет, возвращается sql.ErrNoRows.
+func (s *DataSourceStorage) SaveSample(projectId, name string, sample interface{}) (recordedAt time.Time, err error) {
+	document, err := json.Marshal(sample)
+	if err != nil {
+		return time.Time{}, err
+	}
+	query := `
+		UPDATE projects_data_sources
+		SET sample = $3, recorded_at = NOW()
+		WHERE project_id = $1 AND name = $2
+		RETURNING recorded_at
+	`
+	err = s.postgres.DB.QueryRow(query, projectId, name, document).Scan(&recordedAt)
+	return recordedAt, err
+}
+
+func (s *DataSourceStorage) DeleteByName(projectId, name string) (err error) {
+	res, err := s.postgres.DB.Exec(`DELETE FROM projects_data_sources WHERE project_id = $1 AND name = $2`, projectId, name)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+// IsUsed проверяет, есть ли выражения с источником в головных версиях веток экранов
+// и последних версиях компонентов проекта.
+func (s *DataSourceStorage) IsUsed(projectId, name string) (used bool, err error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM screens sc
+			JOIN screens_branches b ON b.screen_id = sc.id
+			JOIN LATERAL (
+				SELECT w.widgets, w.settings FROM screens_widgets w WHERE w.branch_id = b.id ORDER BY w.version DESC LIMIT 1
+			) head ON TRUE
+			WHERE sc.project_id = $1 AND sc.deleted_at IS NULL
+				AND (head.widgets::text ~ $2 OR head.settings::text ~ $2)
+		) OR EXISTS (
+			SELECT 1
+			FROM projects_components c
+			JOIN projects_component_versions v ON v.component_id = c.id AND v.version = c.version
+			WHERE c.project_id = $1 AND v.widgets::text ~ $2
+		)
+	`
+	// Имя источника состоит из букв, цифр и '_', экранировать его не нужно
+	pattern := `\{\{\s*` + name + `(\.|\[|\s|\}\})`
+	err = s.postgres.DB.QueryRow(query, projectId, pattern).Scan(&used)
+	return used, err
+}
+
+func scanDataSource(row rowScanner) (source entity.DataSource, err error) {
+	var rest, data, sample []byte
+	err = row.Scan(&source.ProjectId, &source.Name, &source.Description, &source.Kind, &rest, &data, &sample,
+		&source.RecordedAt, &source.CreatedBy, &source.CreatedAt, &source.UpdatedAt)
+	if err != nil {
+		return entity.DataSource{}, err
+	}
+	if rest != nil {
+		if err = json.Unmarshal(rest, &source.REST); err != nil {
+			return entity.DataSource{}, err
+		}
+	}
+	if data != nil {
+		if err = json.Unmarshal(data, &source.Data); err != nil {
+			return entity.DataSource{}, err
+		}
+	}
+	if sample != nil {
+		if err = json.Unmarshal(sample, &source.Sample); err != nil {
+			return entity.DataSource{}, err
+		}
+	}
+	return source, nil
+}
+
+// marshalDataSource возвращает JSON настроек REST-источника и статических данных; у другого вида - NULL.
+func marshalDataSource(source *entity.DataSource) (rest, data interface{}, err error) {
+	if source.REST != nil {
+		if rest, err = json.Marshal(source.REST); err != nil {
+			return nil, nil, err
+		}
+	}
+	if source.Kind == entity.DataSourceKindStatic {
+		if data, err = json.Marshal(source.Data); err != nil {
+			return nil, nil, err
+		}
+	}
+	return rest, data, nil
+}
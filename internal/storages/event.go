@@ -0,0 +1,166 @@
+package storages
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/pkg/database"
+)
+
+const (
+	// eventStreamMaxLen - сколько последних событий хранит поток для продолжения чтения
+	eventStreamMaxLen = 1000
+	// eventStreamTTL - поток без новых событий удаляется целиком
+	eventStreamTTL = 7 * 24 * time.Hour
+)
+
+type Event interface {
+	Append(streams []string, event entity.Event) (err error)
+	GetAfter(stream, lastId string, limit int) (events []entity.Event, trimmed bool, err error)
+	Subscribe() (events <-chan entity.Event, unsubscribe func() error, err error)
+	GetProjectAudience(projectId string) (userIds []string, err error)
+	GetWorkspaceAudience(workspaceId string) (projectIds, userIds []string, err error)
+}
+
+type EventStorage struct {
+	postgres *database.PostgresDB
+	redis    *database.Redis
+}
+
+func NewEventStorage(pg *database.PostgresDB, redis *database.Redis) *EventStorage {
+	return &EventStorage{
+		postgres: pg,
+		redis:    redis,
+	}
+}
+
+// Append добавляет событие в каждый поток и оповещает подписчиков через одноименные каналы.
+func (s *EventStorage) Append(streams []string, event entity.Event) (err error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	// 1. Добавляем событие в потоки
+	pipe := s.redis.Client.Pipeline()
+	ids := make([]*redis.StringCmd, len(streams))
+	for i, stream := range streams {
+		ids[i] = pipe.XAdd(&redis.XAddArgs{
+			Stream:       stream,
+			MaxLenApprox: eventStreamMaxLen,
+			Values:       map[string]interface{}{"event": data},
+		})
+		pipe.Expire(stream, eventStreamTTL)
+	}
+	if _, err = pipe.Exec(); err != nil {
+		return err
+	}
+
+	// 2. Оповещаем подписчиков: событие с id в конкретном потоке
+	pipe = s.redis.Client.Pipeline()
+	for i, stream := range streams {
+		event.Id = ids[i].Val()
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		pipe.Publish(stream, data)
+	}
+	_, err = pipe.Exec()
+	return err
+}
+
+// GetAfter возвращает до limit событий потока после lastId. Если lastId старше хранимой
+// части потока (или поток уже удален), события потеряны: возвращается trimmed.
+func (s *EventStorage) GetAfter(stream, lastId string, limit int) (events []entity.Event, trimmed bool, err error) {
+	ms, seq, ok := entity.ParseEventId(lastId)
+	if !ok {
+		return nil, true, nil
+	}
+	oldest, err := s.redis.Client.XRangeN(stream, "-", "+", 1).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if len(oldest) == 0 || entity.EventIdLess(lastId, oldest[0].ID) {
+		return nil, true, nil
+	}
+
+	messages, err := s.redis.Client.XRangeN(stream, fmt.Sprintf("%d-%d", ms, seq+1), "+", int64(limit)).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	for _, message := range messages {
+		data, _ := message.Values["event"].(string)
+		var event entity.Event
+		if err = json.Unmarshal([]byte(data), &event); err != nil {
+			return nil, false, err
+		}
+		event.Id = message.ID
+		event.Stream = stream
+		events = append(events, event)
+	}
+	return events, false, nil
+}
+
+// Subscribe подписывается на оповещения всех потоков событий.
+func (s *EventStorage) Subscribe() (events <-chan entity.Event, unsubscribe func() error, err error) {
+	pubsub := s.redis.Client.PSubscribe("events:*")
+	if _, err = pubsub.Receive(); err != nil {
+		_ = pubsub.Close()
+		return nil, nil, err
+	}
+	out := make(chan entity.Event, 256)
+	go func() {
+		defer close(out)
+		for message := range pubsub.Channel() {
+			var event entity.Event
+			if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+				continue
+			}
+			event.Stream = message.Channel
+			out <- event
+		}
+	}()
+	return out, pubsub.Close, nil
+}
+
+// GetProjectAudience возвращает пользователей с доступом к проекту: прямых участников
+// и участников его воркспейса.
+func (s *EventStorage) GetProjectAudience(projectId string) (userIds []string, err error) {
+	query := `
+		SELECT pm.user_id::text
+		FROM projects_membership pm
+		WHERE pm.project_id = $1 AND pm.deleted_at IS NULL
+		UNION
+		SELECT wm.user_id::text
+		FROM projects p
+		JOIN workspaces w ON w.id = p.workspace_id AND w.deleted_at IS NULL
+		JOIN workspaces_membership wm ON wm.workspace_id = w.id AND wm.deleted_at IS NULL
+		WHERE p.id = $1
+	`
+	err = s.postgres.DB.Select(&userIds, query, projectId)
+	return userIds, err
+}
+
+// GetWorkspaceAudience возвращает проекты воркспейса и пользователей с доступом к ним.
+func (s *EventStorage) GetWorkspaceAudience(workspaceId string) (projectIds, userIds []string, err error) {
+	err = s.postgres.DB.Select(&projectIds, `SELECT id::text FROM projects WHERE workspace_id = $1 AND deleted_at IS NULL`, workspaceId)
+	if err != nil {
+		return nil, nil, err
+	}
+	query := `
+		SELECT wm.user_id::text
+		FROM workspaces_membership wm
+		WHERE wm.workspace_id = $1 AND wm.deleted_at IS NULL
+		UNION
+		SELECT pm.user_id::text
+		FROM projects_membership pm
+		JOIN projects p ON p.id = pm.project_id
+		WHERE p.workspace_id = $1 AND p.deleted_at IS NULL AND pm.deleted_at IS NULL
+	`
+	err = s.postgres.DB.Select(&userIds, query, workspaceId)
+	return projectIds, userIds, err
+}
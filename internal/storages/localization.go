@@ -0,0 +1,162 @@
+package storages
+
+import (
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/pkg/database"
+)
+
+type Localization interface {
+	GetLocales(projectId string) (locales []entity.Locale, err error)
+	CreateLocale(locale *entity.Locale) (err error)
+	SetDefaultLocale(projectId, code string) (err error)
+	DeleteLocale(projectId, code string) (err error)
+	GetTranslations(projectId string) (translations entity.Translations, err error)
+	SaveTranslations(projectId, userId string, values map[string]map[string]*string) (err error)
+}
+
+type LocalizationStorage struct {
+	postgres *database.PostgresDB
+	redis    *database.Redis
+}
+
+func NewLocalizationStorage(pg *database.PostgresDB, redis *database.Redis) *LocalizationStorage {
+	return &LocalizationStorage{
+		postgres: pg,
+		redis:    redis,
+	}
+}
+
+// GetLocales возвращает локали проекта: сначала локаль по умолчанию, затем по коду.
+func (s *LocalizationStorage) GetLocales(projectId string) (locales []entity.Locale, err error) {
+	query := `
+		SELECT project_id, code, is_default, created_at
+		FROM projects_locales
+		WHERE project_id = $1
+		ORDER BY is_default DESC, code
+	`
+	err = s.postgres.DB.Select(&locales, query, projectId)
+	return locales, err
+}
+
+// CreateLocale добавляет локаль. Первая локаль проекта становится локалью по умолчанию,
+// новая локаль по умолчанию снимает этот признак с прежней.
+// Если локаль уже есть, возвращается ErrAlreadyExists.
+func (s *LocalizationStorage) CreateLocale(locale *entity.Locale) (err error) {
+	tx, err := s.postgres.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	if locale.IsDefault {
+		if _, err = tx.Exec(`UPDATE projects_locales SET is_default = FALSE WHERE project_id = $1 AND is_default`, locale.ProjectId); err != nil {
+			return err
+		}
+	}
+	query := `
+		INSERT INTO projects_locales (project_id, code, is_default)
+		SELECT $1, $2, $3 OR NOT EXISTS (SELECT 1 FROM projects_locales WHERE project_id = $1)
+		RETURNING is_default, created_at
+	`
+	err = tx.QueryRow(query, locale.ProjectId, locale.Code, locale.IsDefault).Scan(&locale.IsDefault, &locale.CreatedAt)
+	if isUniqueViolation(err) {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+// SetDefaultLocale делает локаль локалью по умолчанию. Если локали нет, возвращается sql.ErrNoRows.
+func (s *LocalizationStorage) SetDefaultLocale(projectId, code string) (err error) {
+	tx, err := s.postgres.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	if _, err = tx.Exec(`UPDATE projects_locales SET is_default = FALSE WHERE project_id = $1 AND is_default`, projectId); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`UPDATE projects_locales SET is_default = TRUE WHERE project_id = $1 AND code = $2`, projectId, code)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+// DeleteLocale удаляет локаль вместе с ее переводами.
+func (s *LocalizationStorage) DeleteLocale(projectId, code string) (err error) {
+	res, err := s.postgres.DB.Exec(`DELETE FROM projects_locales WHERE project_id = $1 AND code = $2`, projectId, code)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+func (s *LocalizationStorage) GetTranslations(projectId string) (translations entity.Translations, err error) {
+	rows, err := s.postgres.DB.Query(`SELECT key, locale, value FROM projects_translations WHERE project_id = $1`, projectId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations = entity.Translations{}
+	for rows.Next() {
+		var key, locale, value string
+		if err = rows.Scan(&key, &locale, &value); err != nil {
+			return nil, err
+		}
+		if translations[key] == nil {
+			translations[key] = map[string]string{}
+		}
+		translations[key][locale] = value
+	}
+	return translations, rows.Err()
+}
+
+// SaveTranslations записывает переводы в одной транзакции; nil удаляет перевод.
+func (s *LocalizationStorage) SaveTranslations(projectId, userId string, values map[string]map[string]*string) (err error) {
+	tx, err := s.postgres.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	upsertQuery := `
+		INSERT INTO projects_translations (project_id, key, locale, value, updated_by)
+		VALUES ($1, $2, $3, $4, NULLIF($5, '')::uuid)
+		ON CONFLICT (project_id, key, locale) DO UPDATE
+			SET value = EXCLUDED.value, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+	`
+	deleteQuery := `DELETE FROM projects_translations WHERE project_id = $1 AND key = $2 AND locale = $3`
+	for key, locales := range values {
+		for locale, value := range locales {
+			if value == nil {
+				_, err = tx.Exec(deleteQuery, projectId, key, locale)
+			} else {
+				_, err = tx.Exec(upsertQuery, projectId, key, locale, *value, userId)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -2,9 +2,15 @@ package storages
 
 import (
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/go-redis/redis"
+	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 	"ui-platform-backend-service/internal/entity"
 	"ui-platform-backend-service/pkg/database"
@@ -12,9 +18,92 @@ import (
 
 type Project interface {
 	Create(project entity.Project, ownerId string) (projectId string, err error)
-	GetAllByUserId(userId string) ([]entity.Project, error)
+	GetAllByUserId(userId string, filter entity.ProjectFilter) (entity.ProjectList, error)
 	UpdateById(project entity.Project) error
 	DeleteById(projectId string) error
+	GetById(projectId string) (entity.Project, error)
+	UpdateStatus(projectId, from, to string) error
+	GetMemberRole(projectId, userId string) (string, error)
+	GetTrashByUserId(userId string) ([]entity.Project, error)
+	RestoreById(projectId string) error
+	PurgeById(projectId string) error
+	GetDeletedBefore(before time.Time) ([]string, error)
+	AcquirePurgeLock(token string, ttl time.Duration) (acquired bool, err error)
+	ReleasePurgeLock(token string) error
+	Copy(sourceId, ownerId, name string) (string, error)
+	SetWorkspace(projectId, workspaceId string) error
+	SetTemplate(projectId string, isTemplate bool) error
+	GetTemplates() ([]entity.Project, error)
+	IsTemplate(projectId string) (bool, error)
+}
+
+// ErrInvalidCursor возвращается, если курсор пагинации не удалось разобрать.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// projectSortColumns сопоставляет поля сортировки с колонками запроса.
+var projectSortColumns = map[string]string{
+	entity.ProjectSortCreatedAt: "p.created_at",
+	entity.ProjectSortUpdatedAt: "COALESCE(p.updated_at, p.created_at)",
+	entity.ProjectSortName:      "p.name",
+}
+
+// projectCursor - позиция последней записи страницы для keyset-пагинации.
+// Курсор действителен только для той сортировки, с которой получен.
+type projectCursor struct {
+	SortBy    string `json:"s"`
+	SortOrder string `json:"o"`
+	Value     string `json:"v"`
+	ID        string `json:"id"`
+}
+
+// projectAccessCTE вычисляет эффективную роль пользователя ($1) в каждом доступном ему проекте:
+// максимум из прямого членства в проекте и роли в воркспейсе, которому принадлежит проект.
+const projectAccessCTE = `
+	WITH pu AS (
+		SELECT project_id, MAX(role) AS role
+		FROM (
+			SELECT pm.project_id, pm.role
+			FROM projects_membership pm
+			WHERE pm.user_id = $1 AND pm.deleted_at IS NULL
+			UNION ALL
+			SELECT ap.id, wm.role::text::projects_role
+			FROM projects ap
+			JOIN workspaces w ON w.id = ap.workspace_id AND w.deleted_at IS NULL
+			JOIN workspaces_membership wm ON wm.workspace_id = w.id
+			WHERE wm.user_id = $1 AND wm.deleted_at IS NULL
+		) access
+		GROUP BY project_id
+	)
+`
+
+// projectPurgeQueries удаляют проект и все зависимые от него данные.
+// Порядок важен: сначала удаляются дочерние записи.
+var projectPurgeQueries = []string{
+	`DELETE FROM screens_widgets WHERE branch_id IN (
+		SELECT b.id FROM screens_branches b JOIN screens sc ON sc.id = b.screen_id WHERE sc.project_id = $1
+	)`,
+	`DELETE FROM screens_branches WHERE screen_id IN (SELECT id FROM screens WHERE project_id = $1)`,
+	`DELETE FROM screens_releases WHERE screen_id IN (SELECT id FROM screens WHERE project_id = $1)`,
+	`DELETE FROM screens_comments WHERE thread_id IN (
+		SELECT t.id FROM screens_comment_threads t JOIN screens sc ON sc.id = t.screen_id WHERE sc.project_id = $1
+	)`,
+	`DELETE FROM screens_comment_threads WHERE screen_id IN (SELECT id FROM screens WHERE project_id = $1)`,
+	`DELETE FROM screens_change_request_approvals WHERE change_request_id IN (
+		SELECT cr.id FROM screens_change_requests cr JOIN screens sc ON sc.id = cr.screen_id WHERE sc.project_id = $1
+	)`,
+	`DELETE FROM screens_change_requests WHERE screen_id IN (SELECT id FROM screens WHERE project_id = $1)`,
+	`DELETE FROM screens WHERE project_id = $1`,
+	`DELETE FROM projects_widget_types WHERE project_id = $1`,
+	`DELETE FROM projects_routes WHERE project_id = $1`,
+	`DELETE FROM projects_component_versions WHERE component_id IN (SELECT id FROM projects_components WHERE project_id = $1)`,
+	`DELETE FROM projects_components WHERE project_id = $1`,
+	`DELETE FROM projects_design_tokens WHERE project_id = $1`,
+	`DELETE FROM projects_assets WHERE project_id = $1`,
+	`DELETE FROM projects_translations WHERE project_id = $1`,
+	`DELETE FROM projects_locales WHERE project_id = $1`,
+	`DELETE FROM projects_data_sources WHERE project_id = $1`,
+	`DELETE FROM projects_membership WHERE project_id = $1`,
+	`DELETE FROM projects WHERE id = $1`,
 }
 
 type ProjectStorage struct {
@@ -46,17 +135,27 @@ func (s *ProjectStorage) Create(project entity.Project, ownerId string) (string,
 		}
 	}()
 
-	queryCreateProject := `INSERT INTO projects (name, description, status) VALUES ($1, $2, $3) RETURNING id`
+	queryCreateProject := `INSERT INTO projects (name, description, status, workspace_id) VALUES ($1, $2, $3, NULLIF($4, '')::uuid) RETURNING id`
 	var projectId string
-	err = tx.QueryRow(queryCreateProject, project.Name, project.Description, entity.ProjectStatusUnPublished).Scan(&projectId)
+	err = tx.QueryRow(queryCreateProject, project.Name, project.Description, entity.ProjectStatusUnPublished, project.WorkspaceId).Scan(&projectId)
 	if err != nil {
 		s.log.Error().Err(err).Msg("failed to insert project")
 		tx.Rollback()
 		return "", err
 	}
 
-	queryAddUserToProject := `INSERT INTO projects_membership (project_id, user_id, is_owner) VALUES ($1, $2, $3)`
-	_, err = tx.Exec(queryAddUserToProject, projectId, ownerId, true)
+	// Проектом воркспейса владеет команда: права наследуются из воркспейса
+	if project.WorkspaceId != "" {
+		if err = tx.Commit(); err != nil {
+			s.log.Error().Err(err).Msg("failed to commit transaction")
+			return "", err
+		}
+		s.log.Debug().Str("projectId", projectId).Msg("workspace project created successfully")
+		return projectId, nil
+	}
+
+	queryAddUserToProject := `INSERT INTO projects_membership (project_id, user_id, is_owner, role) VALUES ($1, $2, $3, $4)`
+	_, err = tx.Exec(queryAddUserToProject, projectId, ownerId, true, entity.ProjectRoleOwner)
 	if err != nil {
 		s.log.Error().Err(err).Msg("failed to add user to project")
 		tx.Rollback()
@@ -72,40 +171,96 @@ func (s *ProjectStorage) Create(project entity.Project, ownerId string) (string,
 	return projectId, nil
 }
 
-func (s *ProjectStorage) GetAllByUserId(userId string) ([]entity.Project, error) {
-	s.log.Debug().Str("userId", userId).Msg("fetching all projects for user")
+func (s *ProjectStorage) GetAllByUserId(userId string, filter entity.ProjectFilter) (entity.ProjectList, error) {
+	s.log.Debug().Str("userId", userId).Interface("filter", filter).Msg("fetching projects for user")
 
-	query := `
-		SELECT p.id, p.name, p.description, p.status, p.created_at
+	sortColumn := projectSortColumns[filter.SortBy]
+	conditions := []string{"p.deleted_at IS NULL"}
+	args := []interface{}{userId}
+
+	if filter.Search != "" {
+		args = append(args, filter.Search)
+		conditions = append(conditions, fmt.Sprintf(
+			"to_tsvector('simple', p.name || ' ' || COALESCE(p.description, '')) @@ plainto_tsquery('simple', $%d)", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("p.status = $%d", len(args)))
+	}
+	if filter.Role != "" {
+		args = append(args, filter.Role)
+		conditions = append(conditions, fmt.Sprintf("pu.role = $%d", len(args)))
+	}
+	if filter.WorkspaceId != "" {
+		args = append(args, filter.WorkspaceId)
+		conditions = append(conditions, fmt.Sprintf("p.workspace_id = $%d", len(args)))
+	}
+
+	// Общее количество считаем без учета курсора
+	var list entity.ProjectList
+	queryCount := projectAccessCTE + `
+		SELECT COUNT(*)
 		FROM projects p
-		JOIN projects_membership pu ON p.id = pu.project_id
-		WHERE pu.user_id = $1 AND p.deleted_at IS NULL
-	`
+		JOIN pu ON p.id = pu.project_id
+		WHERE ` + strings.Join(conditions, " AND ")
+	if err := s.postgres.DB.QueryRow(queryCount, args...).Scan(&list.Total); err != nil {
+		s.log.Error().Err(err).Msg("failed to count projects")
+		return entity.ProjectList{}, err
+	}
 
-	rows, err := s.postgres.DB.Query(query, userId)
+	comparison, order := ">", "ASC"
+	if filter.SortOrder == entity.SortOrderDesc {
+		comparison, order = "<", "DESC"
+	}
+	if filter.Cursor != "" {
+		cursor, err := decodeProjectCursor(filter.Cursor, filter.SortBy, filter.SortOrder)
+		if err != nil {
+			return entity.ProjectList{}, ErrInvalidCursor
+		}
+		args = append(args, cursor.Value, cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(%s, p.id) %s ($%d, $%d)", sortColumn, comparison, len(args)-1, len(args)))
+	}
+	args = append(args, filter.Limit+1)
+
+	query := projectAccessCTE + fmt.Sprintf(`
+		SELECT p.id, p.name, COALESCE(p.description, ''), p.status, pu.role, COALESCE(p.workspace_id::text, ''),
+			p.created_at, COALESCE(p.updated_at, p.created_at)
+		FROM projects p
+		JOIN pu ON p.id = pu.project_id
+		WHERE %s
+		ORDER BY %s %s, p.id %s
+		LIMIT $%d
+	`, strings.Join(conditions, " AND "), sortColumn, order, order, len(args))
+
+	rows, err := s.postgres.DB.Query(query, args...)
 	if err != nil {
 		s.log.Error().Err(err).Msg("failed to query projects")
-		return nil, err
+		return entity.ProjectList{}, err
 	}
 	defer rows.Close()
 
-	var projects []entity.Project
 	for rows.Next() {
 		var project entity.Project
-		if err := rows.Scan(&project.ID, &project.Name, &project.Description, &project.Status, &project.CreatedAt); err != nil {
+		if err := rows.Scan(&project.ID, &project.Name, &project.Description, &project.Status, &project.Role, &project.WorkspaceId, &project.CreatedAt, &project.UpdatedAt); err != nil {
 			s.log.Error().Err(err).Msg("failed to scan project row")
-			return nil, err
+			return entity.ProjectList{}, err
 		}
-		projects = append(projects, project)
+		list.Projects = append(list.Projects, project)
 	}
 
 	if err = rows.Err(); err != nil {
 		s.log.Error().Err(err).Msg("rows iteration error")
-		return nil, err
+		return entity.ProjectList{}, err
 	}
 
-	s.log.Debug().Int("count", len(projects)).Msg("projects fetched successfully")
-	return projects, nil
+	// Лишняя запись означает, что есть следующая страница
+	if len(list.Projects) > filter.Limit {
+		list.Projects = list.Projects[:filter.Limit]
+		list.NextCursor = encodeProjectCursor(list.Projects[filter.Limit-1], filter.SortBy, filter.SortOrder)
+	}
+
+	s.log.Debug().Int("count", len(list.Projects)).Int("total", list.Total).Msg("projects fetched successfully")
+	return list, nil
 }
 
 func (s *ProjectStorage) UpdateById(project entity.Project) error {
@@ -115,13 +270,12 @@ func (s *ProjectStorage) UpdateById(project entity.Project) error {
 		UPDATE projects
 		SET name = $2,
 			description = $3,
-			status = $4,
-			updated_at = $5
+			updated_at = $4
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	now := time.Now().UTC()
-	result, err := s.postgres.DB.Exec(query, project.ID, project.Name, project.Description, project.Status, now)
+	result, err := s.postgres.DB.Exec(query, project.ID, project.Name, project.Description, now)
 	if err != nil {
 		s.log.Error().Err(err).Msg("failed to update project")
 		return err
@@ -164,3 +318,248 @@ func (s *ProjectStorage) DeleteById(projectId string) error {
 	s.log.Debug().Msg("project deleted successfully")
 	return nil
 }
+
+func encodeProjectCursor(project entity.Project, sortBy, sortOrder string) string {
+	cursor := projectCursor{SortBy: sortBy, SortOrder: sortOrder, ID: project.ID}
+	switch sortBy {
+	case entity.ProjectSortName:
+		cursor.Value = project.Name
+	case entity.ProjectSortUpdatedAt:
+		cursor.Value = project.UpdatedAt.Format(time.RFC3339Nano)
+	default:
+		cursor.Value = project.CreatedAt.Format(time.RFC3339Nano)
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeProjectCursor разбирает курсор и проверяет, что он получен с той же сортировкой.
+func decodeProjectCursor(raw, sortBy, sortOrder string) (projectCursor, error) {
+	var cursor projectCursor
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return cursor, err
+	}
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return cursor, err
+	}
+	if cursor.ID == "" || cursor.SortBy != sortBy || cursor.SortOrder != sortOrder {
+		return cursor, ErrInvalidCursor
+	}
+	if _, err = uuid.Parse(cursor.ID); err != nil {
+		return cursor, ErrInvalidCursor
+	}
+	if sortBy != entity.ProjectSortName {
+		if _, err = time.Parse(time.RFC3339Nano, cursor.Value); err != nil {
+			return cursor, ErrInvalidCursor
+		}
+	}
+	return cursor, nil
+}
+
+func (s *ProjectStorage) GetById(projectId string) (entity.Project, error) {
+	query := `
+		SELECT id, name, COALESCE(description, ''), status, COALESCE(workspace_id::text, ''), created_at, COALESCE(updated_at, created_at)
+		FROM projects
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	var project entity.Project
+	err := s.postgres.DB.QueryRow(query, projectId).Scan(&project.ID, &project.Name, &project.Description, &project.Status, &project.WorkspaceId, &project.CreatedAt, &project.UpdatedAt)
+	if err != nil {
+		return entity.Project{}, err
+	}
+	return project, nil
+}
+
+// UpdateStatus меняет статус проекта, только если текущий статус равен from.
+// Возвращает sql.ErrNoRows, если статус успели изменить параллельно.
+func (s *ProjectStorage) UpdateStatus(projectId, from, to string) error {
+	s.log.Debug().Str("projectId", projectId).Str("from", from).Str("to", to).Msg("updating project status")
+
+	query := `UPDATE projects SET status = $3, updated_at = $4 WHERE id = $1 AND status = $2 AND deleted_at IS NULL`
+	res, err := s.postgres.DB.Exec(query, projectId, from, to, time.Now().UTC())
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to update project status")
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to fetch affected rows on status update")
+		return err
+	}
+
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetMemberRole возвращает эффективную роль пользователя в проекте, в том числе в удаленном,
+// с учетом роли в воркспейсе проекта.
+func (s *ProjectStorage) GetMemberRole(projectId, userId string) (string, error) {
+	query := projectAccessCTE + `SELECT role FROM pu WHERE project_id = $2`
+	var role string
+	err := s.postgres.DB.QueryRow(query, userId, projectId).Scan(&role)
+	if err != nil {
+		return "", err
+	}
+	return role, nil
+}
+
+func (s *ProjectStorage) GetTrashByUserId(userId string) ([]entity.Project, error) {
+	s.log.Debug().Str("userId", userId).Msg("fetching deleted projects for user")
+
+	query := projectAccessCTE + `
+		SELECT p.id, p.name, COALESCE(p.description, ''), p.status, pu.role, p.created_at, p.deleted_at
+		FROM projects p
+		JOIN pu ON p.id = pu.project_id
+		WHERE p.deleted_at IS NOT NULL
+		ORDER BY p.deleted_at DESC
+	`
+
+	rows, err := s.postgres.DB.Query(query, userId)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to query deleted projects")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projects []entity.Project
+	for rows.Next() {
+		var project entity.Project
+		if err := rows.Scan(&project.ID, &project.Name, &project.Description, &project.Status, &project.Role, &project.CreatedAt, &project.DeletedAt); err != nil {
+			s.log.Error().Err(err).Msg("failed to scan deleted project row")
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+
+	if err = rows.Err(); err != nil {
+		s.log.Error().Err(err).Msg("rows iteration error")
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+func (s *ProjectStorage) RestoreById(projectId string) error {
+	s.log.Debug().Str("projectId", projectId).Msg("restoring project")
+
+	query := `UPDATE projects SET deleted_at = NULL, updated_at = $1 WHERE id = $2 AND deleted_at IS NOT NULL`
+	res, err := s.postgres.DB.Exec(query, time.Now().UTC(), projectId)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to restore project")
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to fetch affected rows on restore")
+		return err
+	}
+
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	s.log.Debug().Msg("project restored successfully")
+	return nil
+}
+
+// PurgeById безвозвратно удаляет проект из корзины вместе с экранами, ветками и версиями виджетов.
+func (s *ProjectStorage) PurgeById(projectId string) error {
+	s.log.Debug().Str("projectId", projectId).Msg("purging project")
+
+	tx, err := s.postgres.DB.Begin()
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	var deleted bool
+	err = tx.QueryRow(`SELECT deleted_at IS NOT NULL FROM projects WHERE id = $1 FOR UPDATE`, projectId).Scan(&deleted)
+	if err == nil && !deleted {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, query := range projectPurgeQueries {
+		if _, err = tx.Exec(query, projectId); err != nil {
+			s.log.Error().Err(err).Msg("failed to purge project data")
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error().Err(err).Msg("failed to commit transaction")
+		return err
+	}
+
+	s.log.Debug().Msg("project purged successfully")
+	return nil
+}
+
+const purgeLockKey = "projects_trash_purge"
+
+// releasePurgeLockScript удаляет блокировку, только если ее держит тот же владелец:
+// истекшую и перехваченную другим экземпляром блокировку снимать нельзя.
+var releasePurgeLockScript = redis.NewScript(`
+	if redis.call("GET", KEYS[1]) == ARGV[1] then
+		return redis.call("DEL", KEYS[1])
+	end
+	return 0
+`)
+
+// AcquirePurgeLock не дает нескольким экземплярам сервиса одновременно очищать корзину.
+// token отличает владельца блокировки при ReleasePurgeLock.
+func (s *ProjectStorage) AcquirePurgeLock(token string, ttl time.Duration) (acquired bool, err error) {
+	return s.redis.Client.SetNX(purgeLockKey, token, ttl).Result()
+}
+
+// ReleasePurgeLock снимает блокировку очистки корзины, взятую с тем же token.
+func (s *ProjectStorage) ReleasePurgeLock(token string) error {
+	return releasePurgeLockScript.Run(s.redis.Client, []string{purgeLockKey}, token).Err()
+}
+
+// GetDeletedBefore возвращает идентификаторы проектов, удаленных раньше указанного момента.
+func (s *ProjectStorage) GetDeletedBefore(before time.Time) ([]string, error) {
+	query := `SELECT id FROM projects WHERE deleted_at IS NOT NULL AND deleted_at < $1`
+
+	var projectIds []string
+	if err := s.postgres.DB.Select(&projectIds, query, before.UTC()); err != nil {
+		s.log.Error().Err(err).Msg("failed to query expired projects")
+		return nil, err
+	}
+	return projectIds, nil
+}
+
+// SetWorkspace передает проект во владение воркспейса.
+func (s *ProjectStorage) SetWorkspace(projectId, workspaceId string) error {
+	query := `UPDATE projects SET workspace_id = $2, updated_at = $3 WHERE id = $1 AND deleted_at IS NULL`
+	res, err := s.postgres.DB.Exec(query, projectId, workspaceId, time.Now().UTC())
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to update project workspace")
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,452 @@
+package storages
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"ui-platform-backend-service/internal/entity"
+)
+
+// Copy создает копию проекта sourceId в одной транзакции: проект, его экраны,
+// ветки с головными версиями и точками ответвления, пользовательские типы виджетов, маршруты,
+// компоненты, дизайн-токены, ассеты, локали и переводы проекта; файлы ассетов общие и не копируются.
+// Ссылки виджетов на компоненты и переходы на экраны по идентификатору переводятся на копии.
+// Владельцем копии становится ownerId.
+func (s *ProjectStorage) Copy(sourceId, ownerId, name string) (string, error) {
+	s.log.Debug().Str("sourceId", sourceId).Str("ownerId", ownerId).Msg("copying project")
+
+	tx, err := s.postgres.DB.Beginx()
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to begin transaction")
+		return "", err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	queryCopyProject := `
+		INSERT INTO projects (name, description, status, source_project_id)
+		SELECT $2, description, $3, id FROM projects WHERE id = $1 AND deleted_at IS NULL
+		RETURNING id
+	`
+	var projectId string
+	err = tx.QueryRow(queryCopyProject, sourceId, name, entity.ProjectStatusUnPublished).Scan(&projectId)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.log.Error().Err(err).Msg("failed to copy project")
+		}
+		tx.Rollback()
+		return "", err
+	}
+
+	queryAddUserToProject := `INSERT INTO projects_membership (project_id, user_id, is_owner, role) VALUES ($1, $2, $3, $4)`
+	_, err = tx.Exec(queryAddUserToProject, projectId, ownerId, true, entity.ProjectRoleOwner)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to add user to project")
+		tx.Rollback()
+		return "", err
+	}
+
+	screensMap, err := s.copyScreens(tx, sourceId, projectId)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to copy screens")
+		tx.Rollback()
+		return "", err
+	}
+
+	// Экраны копии ссылаются на пользовательские типы виджетов исходного проекта
+	queryCopyWidgetTypes := `
+		INSERT INTO projects_widget_types (project_id, name, description, schema, allow_children, created_by)
+		SELECT $2, name, description, schema, allow_children, created_by FROM projects_widget_types WHERE project_id = $1
+	`
+	if _, err = tx.Exec(queryCopyWidgetTypes, sourceId, projectId); err != nil {
+		s.log.Error().Err(err).Msg("failed to copy widget types")
+		tx.Rollback()
+		return "", err
+	}
+
+	// Маршруты переносятся на копии экранов; маршруты удаленных экранов не копируются
+	queryCopyRoute := `
+		INSERT INTO projects_routes (project_id, path, screen_id, created_by)
+		SELECT $2, path, $4, created_by FROM projects_routes WHERE project_id = $1 AND screen_id = $3
+	`
+	for sourceScreenId, newScreenId := range screensMap {
+		if _, err = tx.Exec(queryCopyRoute, sourceId, projectId, sourceScreenId, newScreenId); err != nil {
+			s.log.Error().Err(err).Msg("failed to copy routes")
+			tx.Rollback()
+			return "", err
+		}
+	}
+
+	componentsMap, err := s.copyComponents(tx, sourceId, projectId)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to copy components")
+		tx.Rollback()
+		return "", err
+	}
+
+	if err = s.remapReferences(tx, projectId, componentsMap, screensMap); err != nil {
+		s.log.Error().Err(err).Msg("failed to remap widget references")
+		tx.Rollback()
+		return "", err
+	}
+
+	queryCopyTokens := `
+		INSERT INTO projects_design_tokens (project_id, tokens, version, updated_by)
+		SELECT $2, tokens, 1, updated_by FROM projects_design_tokens WHERE project_id = $1
+	`
+	if _, err = tx.Exec(queryCopyTokens, sourceId, projectId); err != nil {
+		s.log.Error().Err(err).Msg("failed to copy design tokens")
+		tx.Rollback()
+		return "", err
+	}
+
+	queryCopyAssets := `
+		INSERT INTO projects_assets (project_id, hash, name, created_by)
+		SELECT $2, hash, name, created_by FROM projects_assets WHERE project_id = $1
+	`
+	if _, err = tx.Exec(queryCopyAssets, sourceId, projectId); err != nil {
+		s.log.Error().Err(err).Msg("failed to copy assets")
+		tx.Rollback()
+		return "", err
+	}
+
+	queryCopyLocales := `
+		INSERT INTO projects_locales (project_id, code, is_default)
+		SELECT $2, code, is_default FROM projects_locales WHERE project_id = $1
+	`
+	queryCopyTranslations := `
+		INSERT INTO projects_translations (project_id, key, locale, value, updated_by)
+		SELECT $2, key, locale, value, updated_by FROM projects_translations WHERE project_id = $1
+	`
+	for _, query := range []string{queryCopyLocales, queryCopyTranslations} {
+		if _, err = tx.Exec(query, sourceId, projectId); err != nil {
+			s.log.Error().Err(err).Msg("failed to copy localization")
+			tx.Rollback()
+			return "", err
+		}
+	}
+
+	// Секреты авторизации и записанные ответы не копируются: копию получает другой
+	// владелец, секреты в ней нужно ввести заново
+	queryCopyDataSources := `
+		INSERT INTO projects_data_sources (project_id, name, description, kind, rest, data, created_by)
+		SELECT $2, name, description, kind,
+			jsonb_set(rest, '{auth}', COALESCE(rest->'auth', '{}'::jsonb) - ARRAY['token', 'password', 'value']),
+			data, created_by
+		FROM projects_data_sources WHERE project_id = $1
+	`
+	if _, err = tx.Exec(queryCopyDataSources, sourceId, projectId); err != nil {
+		s.log.Error().Err(err).Msg("failed to copy data sources")
+		tx.Rollback()
+		return "", err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error().Err(err).Msg("failed to commit transaction")
+		return "", err
+	}
+
+	s.log.Debug().Str("projectId", projectId).Msg("project copied successfully")
+	return projectId, nil
+}
+
+// copyScreens копирует экраны проекта вместе с ветками (см. copyBranches).
+// Возвращает соответствие идентификаторов исходных экранов новым.
+func (s *ProjectStorage) copyScreens(tx *sqlx.Tx, sourceId, projectId string) (map[string]string, error) {
+	var screenIds []string
+	err := tx.Select(&screenIds, `SELECT id FROM screens WHERE project_id = $1 AND deleted_at IS NULL`, sourceId)
+	if err != nil {
+		return nil, err
+	}
+
+	screensMap := make(map[string]string, len(screenIds))
+	for _, screenId := range screenIds {
+		queryCopyScreen := `
+			INSERT INTO screens (project_id, name, description, status, widgets, settings)
+			SELECT $2, name, description, $3, widgets, settings FROM screens WHERE id = $1
+			RETURNING id
+		`
+		var newScreenId string
+		err = tx.QueryRow(queryCopyScreen, screenId, projectId, entity.ScreenStatusUnPublished).Scan(&newScreenId)
+		if err != nil {
+			return nil, err
+		}
+		screensMap[screenId] = newScreenId
+
+		if err = s.copyBranches(tx, screenId, newScreenId); err != nil {
+			return nil, err
+		}
+	}
+
+	return screensMap, nil
+}
+
+// copyBranches копирует ветки экрана. История версий не копируется: у каждой ветки
+// остаются первая и головная версии и версии, от которых ответвлены другие ветки,
+// с нумерацией заново с единицы. Родители и ancestry переводятся на копии веток,
+// чтобы слияние находило в копии ту же точку ответвления, что и в исходном проекте.
+func (s *ProjectStorage) copyBranches(tx *sqlx.Tx, screenId, newScreenId string) error {
+	var branches []struct {
+		Id             string         `db:"id"`
+		ParentBranchId sql.NullString `db:"parent_branch_id"`
+		ParentVersion  sql.NullInt64  `db:"parent_version"`
+		Ancestry       []byte         `db:"ancestry"`
+	}
+	query := `SELECT id, parent_branch_id, parent_version, ancestry FROM screens_branches WHERE screen_id = $1`
+	if err := tx.Select(&branches, query, screenId); err != nil {
+		return err
+	}
+
+	// Новые идентификаторы назначаются заранее, поэтому порядок вставки веток не важен.
+	// Удаленные предки из ancestry тоже получают новые идентификаторы - общие для всех потомков
+	branchesMap := make(map[string]string, len(branches))
+	forkPoints := make(map[string]map[int]bool, len(branches))
+	for _, branch := range branches {
+		branchesMap[branch.Id] = uuid.NewString()
+		forkPoints[branch.Id] = map[int]bool{}
+	}
+	ancestries := make([][]entity.VersionRef, len(branches))
+	for i, branch := range branches {
+		if err := json.Unmarshal(branch.Ancestry, &ancestries[i]); err != nil {
+			return err
+		}
+		for _, ref := range ancestries[i] {
+			if points, ok := forkPoints[ref.BranchId]; ok {
+				points[ref.Version] = true
+			} else if _, ok := branchesMap[ref.BranchId]; !ok {
+				branchesMap[ref.BranchId] = uuid.NewString()
+			}
+		}
+		if branch.ParentBranchId.Valid && forkPoints[branch.ParentBranchId.String] != nil {
+			forkPoints[branch.ParentBranchId.String][int(branch.ParentVersion.Int64)] = true
+		}
+	}
+
+	// versionsMap: исходная ветка -> исходная версия -> версия в копии
+	versionsMap := make(map[string]map[int]int, len(branches))
+	for _, branch := range branches {
+		var versions []int
+		err := tx.Select(&versions, `SELECT version FROM screens_widgets WHERE branch_id = $1 ORDER BY version`, branch.Id)
+		if err != nil {
+			return err
+		}
+		versionsMap[branch.Id] = map[int]int{}
+		for i, version := range versions {
+			if i == 0 || i == len(versions)-1 || forkPoints[branch.Id][version] {
+				versionsMap[branch.Id][version] = len(versionsMap[branch.Id]) + 1
+			}
+		}
+	}
+	// remapRef переводит точку ответвления на копию; версии удаленных веток не перенумеровываются
+	remapRef := func(ref entity.VersionRef) entity.VersionRef {
+		if versions, ok := versionsMap[ref.BranchId]; ok {
+			ref.Version = versions[ref.Version]
+		}
+		ref.BranchId = branchesMap[ref.BranchId]
+		return ref
+	}
+
+	queryCopyBranch := `
+		INSERT INTO screens_branches (id, screen_id, name, parent_branch_id, parent_version, ancestry)
+		SELECT $2::uuid, $3::uuid, name, $4::uuid, $5::int, $6::jsonb FROM screens_branches WHERE id = $1
+	`
+	queryCopyVersion := `
+		INSERT INTO screens_widgets (branch_id, widgets, settings, version)
+		SELECT $2, widgets, settings, $4 FROM screens_widgets WHERE branch_id = $1 AND version = $3
+	`
+	for i, branch := range branches {
+		ancestry := make([]entity.VersionRef, 0, len(ancestries[i]))
+		for _, ref := range ancestries[i] {
+			ancestry = append(ancestry, remapRef(ref))
+		}
+		document, err := json.Marshal(ancestry)
+		if err != nil {
+			return err
+		}
+		var parentBranchId, parentVersion interface{}
+		if _, ok := versionsMap[branch.ParentBranchId.String]; branch.ParentBranchId.Valid && ok {
+			parent := remapRef(entity.VersionRef{BranchId: branch.ParentBranchId.String, Version: int(branch.ParentVersion.Int64)})
+			parentBranchId, parentVersion = parent.BranchId, parent.Version
+		}
+		newBranchId := branchesMap[branch.Id]
+		_, err = tx.Exec(queryCopyBranch, branch.Id, newBranchId, newScreenId, parentBranchId, parentVersion, document)
+		if err != nil {
+			return err
+		}
+
+		for version, newVersion := range versionsMap[branch.Id] {
+			if _, err = tx.Exec(queryCopyVersion, branch.Id, newBranchId, version, newVersion); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// copyComponents копирует компоненты проекта со всеми версиями. Возвращает соответствие
+// идентификаторов исходных компонентов новым. Компоненты воркспейса не копируются.
+func (s *ProjectStorage) copyComponents(tx *sqlx.Tx, sourceId, projectId string) (map[string]string, error) {
+	var componentIds []string
+	if err := tx.Select(&componentIds, `SELECT id FROM projects_components WHERE project_id = $1`, sourceId); err != nil {
+		return nil, err
+	}
+
+	componentsMap := make(map[string]string, len(componentIds))
+	for _, componentId := range componentIds {
+		queryCopyComponent := `
+			INSERT INTO projects_components (project_id, name, description, version, created_by)
+			SELECT $2, name, description, version, created_by FROM projects_components WHERE id = $1
+			RETURNING id
+		`
+		var newComponentId string
+		if err := tx.QueryRow(queryCopyComponent, componentId, projectId).Scan(&newComponentId); err != nil {
+			return nil, err
+		}
+		queryCopyVersions := `
+			INSERT INTO projects_component_versions (component_id, version, root, widgets, message, created_by, created_at)
+			SELECT $2, version, root, widgets, message, created_by, created_at FROM projects_component_versions WHERE component_id = $1
+		`
+		if _, err := tx.Exec(queryCopyVersions, componentId, newComponentId); err != nil {
+			return nil, err
+		}
+		componentsMap[componentId] = newComponentId
+	}
+	return componentsMap, nil
+}
+
+// remapReferences переводит ссылки виджетов экранов, версий веток и компонентов копии
+// на скопированные компоненты (props.component_id) и экраны (props.action.target
+// переходов по идентификатору экрана, см. entity.NavigationTarget).
+func (s *ProjectStorage) remapReferences(tx *sqlx.Tx, projectId string, componentsMap, screensMap map[string]string) error {
+	// Версии компонентов адресуются парой (component_id, version), остальные документы - id
+	type document struct {
+		Key     string `db:"key"`
+		Version int    `db:"version"`
+		Widgets []byte `db:"widgets"`
+	}
+	documents := []struct {
+		selectQuery string
+		updateQuery string
+	}{
+		{
+			`SELECT id AS key, 0 AS version, widgets FROM screens WHERE project_id = $1`,
+			`UPDATE screens SET widgets = $2 WHERE id = $1`,
+		},
+		{
+			`SELECT w.id AS key, 0 AS version, w.widgets FROM screens_widgets w
+				JOIN screens_branches b ON b.id = w.branch_id
+				JOIN screens sc ON sc.id = b.screen_id
+				WHERE sc.project_id = $1`,
+			`UPDATE screens_widgets SET widgets = $2 WHERE id = $1`,
+		},
+		{
+			`SELECT v.component_id AS key, v.version, v.widgets FROM projects_component_versions v
+				JOIN projects_components c ON c.id = v.component_id
+				WHERE c.project_id = $1`,
+			`UPDATE projects_component_versions SET widgets = $2 WHERE component_id = $1 AND version = $3`,
+		},
+	}
+	for _, d := range documents {
+		var rows []document
+		if err := tx.Select(&rows, d.selectQuery, projectId); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			var widgets map[string]interface{}
+			if err := json.Unmarshal(row.Widgets, &widgets); err != nil {
+				return err
+			}
+			if !remapWidgetRefs(widgets, componentsMap, screensMap) {
+				continue
+			}
+			data, err := json.Marshal(widgets)
+			if err != nil {
+				return err
+			}
+			args := []interface{}{row.Key, data}
+			if row.Version > 0 {
+				args = append(args, row.Version)
+			}
+			if _, err = tx.Exec(d.updateQuery, args...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// remapWidgetRefs заменяет в виджетах ссылки на компоненты и переходы на экраны по
+// соответствиям идентификаторов. Возвращает true, если документ изменился.
+func remapWidgetRefs(widgets map[string]interface{}, componentsMap, screensMap map[string]string) bool {
+	changed := false
+	for _, widget := range widgets {
+		w, _ := widget.(map[string]interface{})
+		props, _ := w["props"].(map[string]interface{})
+		if props == nil {
+			continue
+		}
+		if id, ok := props["component_id"].(string); ok && w["type"] == entity.WidgetTypeComponent {
+			if newId, ok := componentsMap[id]; ok {
+				props["component_id"] = newId
+				changed = true
+			}
+		}
+		if target, ok := entity.NavigationTarget(entity.Widget{Props: props}); ok {
+			if newId, ok := screensMap[target]; ok {
+				props["action"].(map[string]interface{})["target"] = newId
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+func (s *ProjectStorage) SetTemplate(projectId string, isTemplate bool) error {
+	query := `UPDATE projects SET is_template = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+	res, err := s.postgres.DB.Exec(query, projectId, isTemplate)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to update project template flag")
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (s *ProjectStorage) GetTemplates() ([]entity.Project, error) {
+	query := `
+		SELECT id, name, COALESCE(description, '') AS description, status, is_template, created_at, COALESCE(updated_at, created_at) AS updated_at
+		FROM projects
+		WHERE is_template = TRUE AND deleted_at IS NULL
+		ORDER BY name, id
+	`
+	var templates []entity.Project
+	if err := s.postgres.DB.Select(&templates, query); err != nil {
+		s.log.Error().Err(err).Msg("failed to query templates")
+		return nil, err
+	}
+	return templates, nil
+}
+
+// IsTemplate сообщает, помечен ли неудаленный проект как шаблон.
+func (s *ProjectStorage) IsTemplate(projectId string) (bool, error) {
+	var isTemplate bool
+	query := `SELECT is_template FROM projects WHERE id = $1 AND deleted_at IS NULL`
+	err := s.postgres.DB.QueryRow(query, projectId).Scan(&isTemplate)
+	if err != nil {
+		return false, err
+	}
+	return isTemplate, nil
+}
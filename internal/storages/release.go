@@ -0,0 +1,155 @@
+package storages
+
+import (
+	"encoding/json"
+	"time"
+
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/pkg/database"
+)
+
+type Release interface {
+	GetAllByScreenId(screenId string) (releases []entity.ScreenRelease, err error)
+	Get(screenId string, releaseNumber int) (release entity.ScreenRelease, err error)
+	Create(release *entity.ScreenRelease) (err error)
+	SetLive(screenId string, releaseNumber int) (err error)
+}
+
+type ReleaseStorage struct {
+	postgres *database.PostgresDB
+	redis    *database.Redis
+}
+
+func NewReleaseStorage(pg *database.PostgresDB, redis *database.Redis) *ReleaseStorage {
+	return &ReleaseStorage{
+		postgres: pg,
+		redis:    redis,
+	}
+}
+
+// GetAllByScreenId возвращает релизы экрана без документов, новые первыми.
+func (s *ReleaseStorage) GetAllByScreenId(screenId string) (releases []entity.ScreenRelease, err error) {
+	query := `
+		SELECT id, screen_id, release_number, branch_id, version, notes, is_live,
+			COALESCE(created_by::text, '') AS created_by, created_at
+		FROM screens_releases
+		WHERE screen_id = $1
+		ORDER BY release_number DESC
+	`
+	err = s.postgres.DB.Select(&releases, query, screenId)
+	return releases, err
+}
+
+// Get возвращает релиз с документами; нулевой номер означает живой релиз.
+func (s *ReleaseStorage) Get(screenId string, releaseNumber int) (release entity.ScreenRelease, err error) {
+	query := `
+		SELECT id, screen_id, release_number, branch_id, version, widgets, settings, themes, notes, is_live,
+			COALESCE(created_by::text, ''), created_at
+		FROM screens_releases
+		WHERE screen_id = $1 AND (($2 = 0 AND is_live) OR release_number = $2)
+	`
+	var widgets, settings, themes []byte
+	err = s.postgres.DB.QueryRow(query, screenId, releaseNumber).Scan(&release.Id, &release.ScreenId, &release.ReleaseNumber,
+		&release.BranchId, &release.Version, &widgets, &settings, &themes, &release.Notes, &release.IsLive, &release.CreatedBy, &release.CreatedAt)
+	if err != nil {
+		return entity.ScreenRelease{}, err
+	}
+	if err = json.Unmarshal(themes, &release.Themes); err != nil {
+		return entity.ScreenRelease{}, err
+	}
+	if err = json.Unmarshal(widgets, &release.Widgets); err != nil {
+		return entity.ScreenRelease{}, err
+	}
+	if err = json.Unmarshal(settings, &release.Settings); err != nil {
+		return entity.ScreenRelease{}, err
+	}
+	return release, nil
+}
+
+// Create сохраняет новый релиз с номером на единицу больше последнего, делает его живым
+// и помечает экран опубликованным. Заполняет у release номер, id и время создания.
+func (s *ReleaseStorage) Create(release *entity.ScreenRelease) (err error) {
+	widgets, err := marshalDocument(release.Widgets)
+	if err != nil {
+		return err
+	}
+	settings, err := marshalDocument(release.Settings)
+	if err != nil {
+		return err
+	}
+	themes, err := json.Marshal(release.Themes)
+	if err != nil {
+		return err
+	}
+	if release.Themes == nil {
+		themes = []byte("{}")
+	}
+
+	tx, err := s.postgres.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	// 1. Снимаем признак живого релиза с предыдущего
+	if _, err = tx.Exec(`UPDATE screens_releases SET is_live = FALSE WHERE screen_id = $1 AND is_live`, release.ScreenId); err != nil {
+		return err
+	}
+
+	// 2. Добавляем релиз
+	query := `
+		INSERT INTO screens_releases (screen_id, release_number, branch_id, version, widgets, settings, themes, notes, is_live, live_at, created_by)
+		SELECT $1, COALESCE(MAX(release_number), 0) + 1, $2, $3, $4, $5, $6, $7, TRUE, NOW(), NULLIF($8, '')::uuid
+		FROM screens_releases
+		WHERE screen_id = $1
+		RETURNING id, release_number, created_at
+	`
+	err = tx.QueryRow(query, release.ScreenId, release.BranchId, release.Version, widgets, settings, themes, release.Notes, release.CreatedBy).
+		Scan(&release.Id, &release.ReleaseNumber, &release.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			err = ErrAlreadyExists
+		}
+		return err
+	}
+	release.IsLive = true
+
+	// 3. Экран опубликован
+	_, err = tx.Exec(`UPDATE screens SET status = $2, updated_at = $3 WHERE id = $1`,
+		release.ScreenId, entity.ScreenStatusPublished, time.Now().UTC())
+	return err
+}
+
+// SetLive делает живым релиз releaseNumber экрана. live_at отмечает момент переключения,
+// чтобы откат на старый релиз тоже сдвигал Last-Modified runtime API.
+func (s *ReleaseStorage) SetLive(screenId string, releaseNumber int) (err error) {
+	tx, err := s.postgres.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	if _, err = tx.Exec(`UPDATE screens_releases SET is_live = FALSE WHERE screen_id = $1 AND is_live`, screenId); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`UPDATE screens_releases SET is_live = TRUE, live_at = NOW() WHERE screen_id = $1 AND release_number = $2`, screenId, releaseNumber)
+	if err != nil {
+		if isUniqueViolation(err) {
+			err = ErrAlreadyExists
+		}
+		return err
+	}
+	return checkAffected(res)
+}
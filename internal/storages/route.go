@@ -0,0 +1,82 @@
+package storages
+
+import (
+	"time"
+
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/pkg/database"
+)
+
+type Route interface {
+	GetAllByProjectId(projectId string) (routes []entity.Route, err error)
+	GetById(projectId, routeId string) (route entity.Route, err error)
+	Create(route *entity.Route) (err error)
+	UpdateById(route *entity.Route) (err error)
+	DeleteById(projectId, routeId string) (err error)
+}
+
+type RouteStorage struct {
+	postgres *database.PostgresDB
+	redis    *database.Redis
+}
+
+func NewRouteStorage(pg *database.PostgresDB, redis *database.Redis) *RouteStorage {
+	return &RouteStorage{
+		postgres: pg,
+		redis:    redis,
+	}
+}
+
+const routeSelect = `
+	SELECT id, project_id, path, screen_id, COALESCE(created_by::text, '') AS created_by, created_at, updated_at
+	FROM projects_routes
+`
+
+func (s *RouteStorage) GetAllByProjectId(projectId string) (routes []entity.Route, err error) {
+	err = s.postgres.DB.Select(&routes, routeSelect+` WHERE project_id = $1 ORDER BY path`, projectId)
+	return routes, err
+}
+
+func (s *RouteStorage) GetById(projectId, routeId string) (route entity.Route, err error) {
+	err = s.postgres.DB.Get(&route, routeSelect+` WHERE project_id = $1 AND id = $2`, projectId, routeId)
+	return route, err
+}
+
+// Create сохраняет маршрут. Если путь уже занят в проекте, возвращается ErrAlreadyExists.
+func (s *RouteStorage) Create(route *entity.Route) (err error) {
+	query := `
+		INSERT INTO projects_routes (project_id, path, screen_id, created_by)
+		VALUES ($1, $2, $3, NULLIF($4, '')::uuid)
+		RETURNING id, created_at, updated_at
+	`
+	err = s.postgres.DB.QueryRow(query, route.ProjectId, route.Path, route.ScreenId, route.CreatedBy).
+		Scan(&route.Id, &route.CreatedAt, &route.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+// UpdateById меняет путь и экран маршрута.
+func (s *RouteStorage) UpdateById(route *entity.Route) (err error) {
+	query := `
+		UPDATE projects_routes
+		SET path = $3, screen_id = $4, updated_at = $5
+		WHERE project_id = $1 AND id = $2
+		RETURNING COALESCE(created_by::text, ''), created_at, updated_at
+	`
+	err = s.postgres.DB.QueryRow(query, route.ProjectId, route.Id, route.Path, route.ScreenId, time.Now().UTC()).
+		Scan(&route.CreatedBy, &route.CreatedAt, &route.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+func (s *RouteStorage) DeleteById(projectId, routeId string) (err error) {
+	res, err := s.postgres.DB.Exec(`DELETE FROM projects_routes WHERE project_id = $1 AND id = $2`, projectId, routeId)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
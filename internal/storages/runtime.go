@@ -0,0 +1,178 @@
+package storages
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/pkg/database"
+)
+
+type Runtime interface {
+	GetProject(projectId string) (project entity.RuntimeProject, err error)
+	GetCache(projectId string) (project entity.RuntimeProject, ok bool, err error)
+	SetCache(project entity.RuntimeProject, ttl time.Duration) (err error)
+	DeleteCache(projectId string) (err error)
+}
+
+type RuntimeStorage struct {
+	postgres *database.PostgresDB
+	redis    *database.Redis
+}
+
+func NewRuntimeStorage(pg *database.PostgresDB, redis *database.Redis) *RuntimeStorage {
+	return &RuntimeStorage{
+		postgres: pg,
+		redis:    redis,
+	}
+}
+
+// GetProject собирает живые релизы экранов опубликованного проекта.
+// Для удаленного или неопубликованного проекта возвращается sql.ErrNoRows.
+func (s *RuntimeStorage) GetProject(projectId string) (project entity.RuntimeProject, err error) {
+	var published bool
+	query := `SELECT EXISTS (SELECT 1 FROM projects WHERE id = $1 AND status = $2 AND deleted_at IS NULL)`
+	if err = s.postgres.DB.QueryRow(query, projectId, entity.ProjectStatusPublished).Scan(&published); err != nil {
+		return entity.RuntimeProject{}, err
+	}
+	if !published {
+		return entity.RuntimeProject{}, sql.ErrNoRows
+	}
+
+	releasesQuery := `
+		SELECT r.screen_id, r.release_number, r.widgets, r.settings, r.themes, r.created_at
+		FROM screens_releases r
+		JOIN screens sc ON sc.id = r.screen_id
+		WHERE sc.project_id = $1 AND sc.deleted_at IS NULL AND r.is_live
+		ORDER BY sc.created_at, sc.id
+	`
+	rows, err := s.postgres.DB.Query(releasesQuery, projectId)
+	if err != nil {
+		return entity.RuntimeProject{}, err
+	}
+	defer rows.Close()
+
+	project = entity.RuntimeProject{Id: projectId, Screens: []entity.RuntimeScreen{}}
+	for rows.Next() {
+		var screen entity.RuntimeScreen
+		var widgets, settings, themes []byte
+		if err = rows.Scan(&screen.Id, &screen.ReleaseNumber, &widgets, &settings, &themes, &screen.PublishedAt); err != nil {
+			return entity.RuntimeProject{}, err
+		}
+		if err = json.Unmarshal(themes, &screen.Themes); err != nil {
+			return entity.RuntimeProject{}, err
+		}
+		if err = json.Unmarshal(widgets, &screen.Widgets); err != nil {
+			return entity.RuntimeProject{}, err
+		}
+		if err = json.Unmarshal(settings, &screen.Settings); err != nil {
+			return entity.RuntimeProject{}, err
+		}
+		project.Screens = append(project.Screens, screen)
+	}
+	if err = rows.Err(); err != nil {
+		return entity.RuntimeProject{}, err
+	}
+
+	// Маршруты только на экраны с живым релизом
+	routesQuery := `
+		SELECT rt.path, rt.screen_id
+		FROM projects_routes rt
+		JOIN screens sc ON sc.id = rt.screen_id AND sc.deleted_at IS NULL
+		WHERE rt.project_id = $1 AND EXISTS (SELECT 1 FROM screens_releases r WHERE r.screen_id = sc.id AND r.is_live)
+		ORDER BY rt.path
+	`
+	project.Routes = []entity.RuntimeRoute{}
+	if err = s.postgres.DB.Select(&project.Routes, routesQuery, projectId); err != nil {
+		return entity.RuntimeProject{}, err
+	}
+
+	// Переводы не фиксируются в релизах: правки текстов видны сразу после сброса кеша
+	var locales []entity.Locale
+	localesQuery := `SELECT project_id, code, is_default, created_at FROM projects_locales WHERE project_id = $1 ORDER BY code`
+	if err = s.postgres.DB.Select(&locales, localesQuery, projectId); err != nil {
+		return entity.RuntimeProject{}, err
+	}
+	for _, locale := range locales {
+		project.Locales = append(project.Locales, locale.Code)
+		if locale.IsDefault {
+			project.DefaultLocale = locale.Code
+		}
+	}
+	var translations []entity.Translation
+	translationsQuery := `SELECT key, locale, value FROM projects_translations WHERE project_id = $1`
+	if err = s.postgres.DB.Select(&translations, translationsQuery, projectId); err != nil {
+		return entity.RuntimeProject{}, err
+	}
+	project.Translations = entity.Translations{}
+	for _, translation := range translations {
+		if project.Translations[translation.Key] == nil {
+			project.Translations[translation.Key] = map[string]string{}
+		}
+		project.Translations[translation.Key][translation.Locale] = translation.Value
+	}
+
+	// Время изменения берется из данных, а не из момента сборки, чтобы пересборка кеша
+	// не сдвигала Last-Modified. Удаление маршрутов и переводов его не сдвигает - такие
+	// изменения клиенты видят по ETag
+	updatedAtQuery := `
+		SELECT GREATEST(
+			(SELECT COALESCE(updated_at, created_at) FROM projects WHERE id = $1),
+			(SELECT MAX(r.live_at) FROM screens_releases r JOIN screens sc ON sc.id = r.screen_id WHERE sc.project_id = $1 AND r.is_live),
+			(SELECT MAX(deleted_at) FROM screens WHERE project_id = $1),
+			(SELECT MAX(updated_at) FROM projects_routes WHERE project_id = $1),
+			(SELECT MAX(created_at) FROM projects_locales WHERE project_id = $1),
+			(SELECT MAX(updated_at) FROM projects_translations WHERE project_id = $1)
+		)
+	`
+	if err = s.postgres.DB.QueryRow(updatedAtQuery, projectId).Scan(&project.UpdatedAt); err != nil {
+		return entity.RuntimeProject{}, err
+	}
+	return project, nil
+}
+
+// runtimeCache - запись кеша снимка. UpdatedAt не сериализуется в самом снимке,
+// поэтому хранится рядом.
+type runtimeCache struct {
+	Project   entity.RuntimeProject `json:"project"`
+	UpdatedAt time.Time             `json:"updated_at"`
+}
+
+func (s *RuntimeStorage) GetCache(projectId string) (project entity.RuntimeProject, ok bool, err error) {
+	data, err := s.redis.Client.Get(runtimeCacheKey(projectId)).Bytes()
+	if err == redis.Nil {
+		return entity.RuntimeProject{}, false, nil
+	}
+	if err != nil {
+		return entity.RuntimeProject{}, false, err
+	}
+	var cache runtimeCache
+	if err = json.Unmarshal(data, &cache); err != nil {
+		return entity.RuntimeProject{}, false, err
+	}
+	// Записи прежнего формата (снимок без обертки) считаются промахом
+	if cache.Project.Id == "" {
+		return entity.RuntimeProject{}, false, nil
+	}
+	cache.Project.UpdatedAt = cache.UpdatedAt
+	return cache.Project, true, nil
+}
+
+func (s *RuntimeStorage) SetCache(project entity.RuntimeProject, ttl time.Duration) (err error) {
+	data, err := json.Marshal(runtimeCache{Project: project, UpdatedAt: project.UpdatedAt})
+	if err != nil {
+		return err
+	}
+	return s.redis.Client.Set(runtimeCacheKey(project.Id), data, ttl).Err()
+}
+
+func (s *RuntimeStorage) DeleteCache(projectId string) (err error) {
+	return s.redis.Client.Del(runtimeCacheKey(projectId)).Err()
+}
+
+func runtimeCacheKey(projectId string) string {
+	return fmt.Sprintf("runtime_project:%s", projectId)
+}
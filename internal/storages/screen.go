@@ -1,6 +1,8 @@
 package storages
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 	"ui-platform-backend-service/internal/entity"
 	"ui-platform-backend-service/pkg/database"
@@ -8,6 +10,13 @@ import (
 
 type Screen interface {
 	Create(screen *entity.Screen) (screenId string, err error)
+	GetAllByProjectId(projectId string) (screens []entity.Screen, err error)
+	GetWidgetsByProjectId(projectId string) (screens []entity.Screen, err error)
+	GetById(projectId, screenId string) (screen entity.Screen, err error)
+	UpdateById(screen *entity.Screen) (err error)
+	DeleteById(projectId, screenId string) (err error)
+	CountByStatus(projectId, status string) (count int, err error)
+	Exists(projectId, screenId string) (exists bool, err error)
 }
 
 type ScreenStorage struct {
@@ -23,6 +32,15 @@ func NewScreenStorage(pg *database.PostgresDB, redis *database.Redis) *ScreenSto
 }
 
 func (s *ScreenStorage) Create(screen *entity.Screen) (screenId string, err error) {
+	widgets, err := marshalDocument(screen.Widgets)
+	if err != nil {
+		return "", err
+	}
+	settings, err := marshalDocument(screen.Settings)
+	if err != nil {
+		return "", err
+	}
+
 	tx, err := s.postgres.DB.Begin()
 	if err != nil {
 		return "", err
@@ -31,23 +49,170 @@ func (s *ScreenStorage) Create(screen *entity.Screen) (screenId string, err erro
 		if err != nil {
 			_ = tx.Rollback()
 		} else {
-			_ = tx.Commit()
+			err = tx.Commit()
 		}
 	}()
 
 	// 1. Создаём экран
-	query := "INSERT INTO screens (project_id, name, description, status, widgets, settings) VALUES ($1, $2, $3, $4) RETURNING id"
-	err = tx.QueryRow(query, screen.ProjectId, screen.Name, screen.Description, entity.ScreenStatusUnPublished, map[string]interface{}{}, map[string]interface{}{}).Scan(&screenId)
+	query := "INSERT INTO screens (project_id, name, description, status, widgets, settings) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+	err = tx.QueryRow(query, screen.ProjectId, screen.Name, screen.Description, entity.ScreenStatusUnPublished, widgets, settings).Scan(&screenId)
 	if err != nil {
 		return "", err
 	}
 
 	// 2. Создаём ветки
-	branchQuery := "INSERT INTO screens_branches (screen_id, name, created_at) VALUES ($1, $2, $3)"
-	_, err = tx.Exec(branchQuery, screenId, "Main", time.Now().UTC())
+	var branchId string
+	branchQuery := "INSERT INTO screens_branches (screen_id, name, created_at) VALUES ($1, $2, $3) RETURNING id"
+	err = tx.QueryRow(branchQuery, screenId, entity.ScreenMainBranch, time.Now().UTC()).Scan(&branchId)
+	if err != nil {
+		return "", err
+	}
+
+	// 3. Сохраняем первую версию виджетов в Main
+	versionQuery := "INSERT INTO screens_widgets (branch_id, widgets, settings, version) VALUES ($1, $2, $3, 1)"
+	_, err = tx.Exec(versionQuery, branchId, widgets, settings)
 	if err != nil {
 		return "", err
 	}
 
 	return screenId, nil
 }
+
+// GetAllByProjectId возвращает экраны проекта без документов виджетов и настроек.
+func (s *ScreenStorage) GetAllByProjectId(projectId string) (screens []entity.Screen, err error) {
+	query := `
+		SELECT id, project_id, name, COALESCE(description, ''), status, created_at, COALESCE(updated_at, created_at)
+		FROM screens
+		WHERE project_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at, id
+	`
+	rows, err := s.postgres.DB.Query(query, projectId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var screen entity.Screen
+		err = rows.Scan(&screen.Id, &screen.ProjectId, &screen.Name, &screen.Description, &screen.Status, &screen.CreatedAt, &screen.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		screens = append(screens, screen)
+	}
+	return screens, rows.Err()
+}
+
+// GetWidgetsByProjectId возвращает экраны проекта с виджетами головной версии Main.
+func (s *ScreenStorage) GetWidgetsByProjectId(projectId string) (screens []entity.Screen, err error) {
+	query := `
+		SELECT id, project_id, name, status, widgets
+		FROM screens
+		WHERE project_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at, id
+	`
+	rows, err := s.postgres.DB.Query(query, projectId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var screen entity.Screen
+		var widgets []byte
+		if err = rows.Scan(&screen.Id, &screen.ProjectId, &screen.Name, &screen.Status, &widgets); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(widgets, &screen.Widgets); err != nil {
+			return nil, err
+		}
+		screens = append(screens, screen)
+	}
+	return screens, rows.Err()
+}
+
+func (s *ScreenStorage) GetById(projectId, screenId string) (screen entity.Screen, err error) {
+	query := `
+		SELECT sc.id, sc.project_id, sc.name, COALESCE(sc.description, ''), sc.status, sc.widgets, sc.settings,
+			COALESCE((
+				SELECT MAX(w.version)
+				FROM screens_widgets w
+				JOIN screens_branches b ON b.id = w.branch_id
+				WHERE b.screen_id = sc.id AND b.name = $3
+			), 0),
+			sc.created_at, COALESCE(sc.updated_at, sc.created_at)
+		FROM screens sc
+		WHERE sc.id = $1 AND sc.project_id = $2 AND sc.deleted_at IS NULL
+	`
+	var widgets, settings []byte
+	err = s.postgres.DB.QueryRow(query, screenId, projectId, entity.ScreenMainBranch).Scan(&screen.Id, &screen.ProjectId, &screen.Name, &screen.Description, &screen.Status,
+		&widgets, &settings, &screen.Version, &screen.CreatedAt, &screen.UpdatedAt)
+	if err != nil {
+		return entity.Screen{}, err
+	}
+	if err = json.Unmarshal(widgets, &screen.Widgets); err != nil {
+		return entity.Screen{}, err
+	}
+	if err = json.Unmarshal(settings, &screen.Settings); err != nil {
+		return entity.Screen{}, err
+	}
+	return screen, nil
+}
+
+// UpdateById обновляет описание экрана. Виджеты и настройки меняются только через версии веток.
+func (s *ScreenStorage) UpdateById(screen *entity.Screen) (err error) {
+	query := `
+		UPDATE screens
+		SET name = $3, description = $4, updated_at = $5
+		WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL
+	`
+	res, err := s.postgres.DB.Exec(query, screen.Id, screen.ProjectId, screen.Name, screen.Description, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+func (s *ScreenStorage) DeleteById(projectId, screenId string) (err error) {
+	query := `UPDATE screens SET deleted_at = $3 WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL`
+	res, err := s.postgres.DB.Exec(query, screenId, projectId, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+func (s *ScreenStorage) CountByStatus(projectId, status string) (count int, err error) {
+	query := "SELECT COUNT(*) FROM screens WHERE project_id = $1 AND status = $2 AND deleted_at IS NULL"
+	err = s.postgres.DB.QueryRow(query, projectId, status).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (s *ScreenStorage) Exists(projectId, screenId string) (exists bool, err error) {
+	query := "SELECT EXISTS (SELECT 1 FROM screens WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL)"
+	err = s.postgres.DB.QueryRow(query, screenId, projectId).Scan(&exists)
+	return exists, err
+}
+
+// marshalDocument сериализует JSON-документ экрана для колонки JSONB; nil сохраняется как пустой объект.
+func marshalDocument(document map[string]interface{}) ([]byte, error) {
+	if document == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(document)
+}
+
+// checkAffected возвращает sql.ErrNoRows, если запрос не изменил ни одной строки.
+func checkAffected(res sql.Result) error {
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
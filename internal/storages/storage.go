@@ -2,25 +2,60 @@ package storages
 
 import (
 	"github.com/rs/zerolog"
+	"ui-platform-backend-service/pkg/blobstore"
 	"ui-platform-backend-service/pkg/database"
 )
 
 type Storage struct {
-	User    User
-	Project Project
-	Screen  Screen
+	User          User
+	Project       Project
+	Screen        Screen
+	Workspace     Workspace
+	Branch        Branch
+	Version       Version
+	WidgetType    WidgetType
+	Release       Release
+	Runtime       Runtime
+	Collab        Collab
+	Event         Event
+	Comment       Comment
+	ChangeRequest ChangeRequest
+	Route         Route
+	Component     Component
+	DesignToken   DesignToken
+	Asset         Asset
+	Localization  Localization
+	DataSource    DataSource
 }
 
 type StorageDeps struct {
 	PostgresDB *database.PostgresDB
 	Redis      *database.Redis
-	Log        zerolog.Logger
+	// Blobs - хранилище файлов ассетов
+	Blobs blobstore.Store
+	Log   zerolog.Logger
 }
 
 func NewStorage(deps StorageDeps) *Storage {
 	return &Storage{
-		User:    NewUserStorage(deps.PostgresDB, deps.Redis),
-		Project: NewProjectStorage(deps.PostgresDB, deps.Redis, deps.Log),
-		Screen:  NewScreenStorage(deps.PostgresDB, deps.Redis),
+		User:          NewUserStorage(deps.PostgresDB, deps.Redis),
+		Project:       NewProjectStorage(deps.PostgresDB, deps.Redis, deps.Log),
+		Screen:        NewScreenStorage(deps.PostgresDB, deps.Redis),
+		Workspace:     NewWorkspaceStorage(deps.PostgresDB, deps.Redis, deps.Log),
+		Branch:        NewBranchStorage(deps.PostgresDB, deps.Redis),
+		Version:       NewVersionStorage(deps.PostgresDB, deps.Redis),
+		WidgetType:    NewWidgetTypeStorage(deps.PostgresDB, deps.Redis),
+		Release:       NewReleaseStorage(deps.PostgresDB, deps.Redis),
+		Runtime:       NewRuntimeStorage(deps.PostgresDB, deps.Redis),
+		Collab:        NewCollabStorage(deps.Redis),
+		Event:         NewEventStorage(deps.PostgresDB, deps.Redis),
+		Comment:       NewCommentStorage(deps.PostgresDB, deps.Redis),
+		ChangeRequest: NewChangeRequestStorage(deps.PostgresDB, deps.Redis),
+		Route:         NewRouteStorage(deps.PostgresDB, deps.Redis),
+		Component:     NewComponentStorage(deps.PostgresDB, deps.Redis),
+		DesignToken:   NewDesignTokenStorage(deps.PostgresDB, deps.Redis),
+		Asset:         NewAssetStorage(deps.PostgresDB, deps.Redis, deps.Blobs),
+		Localization:  NewLocalizationStorage(deps.PostgresDB, deps.Redis),
+		DataSource:    NewDataSourceStorage(deps.PostgresDB, deps.Redis),
 	}
 }
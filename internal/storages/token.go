@@ -0,0 +1,70 @@
+package storages
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/pkg/database"
+)
+
+type DesignToken interface {
+	GetByProjectId(projectId string) (tokens entity.DesignTokens, err error)
+	Save(tokens *entity.DesignTokens, baseVersion int) (err error)
+}
+
+type DesignTokenStorage struct {
+	postgres *database.PostgresDB
+	redis    *database.Redis
+}
+
+func NewDesignTokenStorage(pg *database.PostgresDB, redis *database.Redis) *DesignTokenStorage {
+	return &DesignTokenStorage{
+		postgres: pg,
+		redis:    redis,
+	}
+}
+
+// GetByProjectId возвращает токены проекта. Если токены еще не сохранялись,
+// возвращается пустой набор с нулевой версией.
+func (s *DesignTokenStorage) GetByProjectId(projectId string) (tokens entity.DesignTokens, err error) {
+	query := `
+		SELECT tokens, version, COALESCE(updated_by::text, ''), updated_at
+		FROM projects_design_tokens
+		WHERE project_id = $1
+	`
+	tokens.ProjectId = projectId
+	var document []byte
+	err = s.postgres.DB.QueryRow(query, projectId).Scan(&document, &tokens.Version, &tokens.UpdatedBy, &tokens.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		tokens.Tokens = map[string]entity.DesignToken{}
+		return tokens, nil
+	}
+	if err != nil {
+		return entity.DesignTokens{}, err
+	}
+	if err = json.Unmarshal(document, &tokens.Tokens); err != nil {
+		return entity.DesignTokens{}, err
+	}
+	return tokens, nil
+}
+
+// Save заменяет токены проекта, если сохраненная версия равна baseVersion.
+// Иначе возвращается sql.ErrNoRows.
+func (s *DesignTokenStorage) Save(tokens *entity.DesignTokens, baseVersion int) (err error) {
+	document, err := json.Marshal(tokens.Tokens)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO projects_design_tokens AS t (project_id, tokens, version, updated_by)
+		SELECT $1, $2, 1, NULLIF($4, '')::uuid WHERE $3 = 0
+		ON CONFLICT (project_id) DO UPDATE
+			SET tokens = EXCLUDED.tokens, version = t.version + 1, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+			WHERE t.version = $3
+		RETURNING version, updated_at
+	`
+	return s.postgres.DB.QueryRow(query, tokens.ProjectId, document, baseVersion, tokens.UpdatedBy).
+		Scan(&tokens.Version, &tokens.UpdatedAt)
+}
@@ -0,0 +1,150 @@
+package storages
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/pkg/database"
+)
+
+type Version interface {
+	GetAllByBranchId(branchId string, limit, before int) (versions []entity.ScreenVersion, err error)
+	Get(branchId string, version int) (screenVersion entity.ScreenVersion, err error)
+	Create(screenVersion *entity.ScreenVersion) (err error)
+	GetLastMerge(targetBranchId, sourceBranchId string) (screenVersion entity.ScreenVersion, err error)
+}
+
+type VersionStorage struct {
+	postgres *database.PostgresDB
+	redis    *database.Redis
+}
+
+func NewVersionStorage(pg *database.PostgresDB, redis *database.Redis) *VersionStorage {
+	return &VersionStorage{
+		postgres: pg,
+		redis:    redis,
+	}
+}
+
+// GetAllByBranchId возвращает историю версий ветки без документов, от новых к старым.
+// before ограничивает выборку версиями с меньшим номером (0 - без ограничения).
+func (s *VersionStorage) GetAllByBranchId(branchId string, limit, before int) (versions []entity.ScreenVersion, err error) {
+	query := `
+		SELECT id, branch_id, version, COALESCE(author_id::text, '') AS author_id, message,
+			COALESCE(restored_from, 0) AS restored_from,
+			COALESCE(merge_source_branch_id::text, '') AS merge_source_branch_id,
+			COALESCE(merge_source_version, 0) AS merge_source_version,
+			created_at
+		FROM screens_widgets
+		WHERE branch_id = $1 AND ($2 = 0 OR version < $2)
+		ORDER BY version DESC
+		LIMIT $3
+	`
+	err = s.postgres.DB.Select(&versions, query, branchId, before, limit)
+	return versions, err
+}
+
+// Get возвращает версию ветки с документами; нулевая версия означает головную.
+func (s *VersionStorage) Get(branchId string, version int) (screenVersion entity.ScreenVersion, err error) {
+	query := `
+		SELECT id, branch_id, version, widgets, settings, COALESCE(author_id::text, ''), message,
+			COALESCE(restored_from, 0), COALESCE(merge_source_branch_id::text, ''), COALESCE(merge_source_version, 0), created_at
+		FROM screens_widgets
+		WHERE branch_id = $1 AND ($2 = 0 OR version = $2)
+		ORDER BY version DESC
+		LIMIT 1
+	`
+	var widgets, settings []byte
+	err = s.postgres.DB.QueryRow(query, branchId, version).Scan(&screenVersion.Id, &screenVersion.BranchId, &screenVersion.Version,
+		&widgets, &settings, &screenVersion.AuthorId, &screenVersion.Message, &screenVersion.RestoredFrom,
+		&screenVersion.MergeSourceBranchId, &screenVersion.MergeSourceVersion, &screenVersion.CreatedAt)
+	if err != nil {
+		return entity.ScreenVersion{}, err
+	}
+	if err = json.Unmarshal(widgets, &screenVersion.Widgets); err != nil {
+		return entity.ScreenVersion{}, err
+	}
+	if err = json.Unmarshal(settings, &screenVersion.Settings); err != nil {
+		return entity.ScreenVersion{}, err
+	}
+	return screenVersion, nil
+}
+
+// Create добавляет новую головную версию ветки и заполняет у screenVersion номер, id и время создания.
+// Если номер версии задан заранее, версия создается только поверх головной версии с номером на единицу
+// меньше; иначе возвращается ErrAlreadyExists. Гонку двух сохранений поверх одной головы разрешает
+// ограничение UNIQUE (branch_id, version).
+// Для ветки Main документы экрана синхронизируются с новой головной версией.
+func (s *VersionStorage) Create(screenVersion *entity.ScreenVersion) (err error) {
+	widgets, err := marshalDocument(screenVersion.Widgets)
+	if err != nil {
+		return err
+	}
+	settings, err := marshalDocument(screenVersion.Settings)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.postgres.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	// 1. Добавляем версию
+	query := `
+		INSERT INTO screens_widgets (branch_id, widgets, settings, version, author_id, message, restored_from,
+			merge_source_branch_id, merge_source_version)
+		SELECT $1, $2, $3, COALESCE(MAX(version), 0) + 1, NULLIF($4, '')::uuid, $5, NULLIF($6, 0),
+			NULLIF($7, '')::uuid, NULLIF($8, 0)
+		FROM screens_widgets
+		WHERE branch_id = $1
+		HAVING $9::int = 0 OR COALESCE(MAX(version), 0) + 1 = $9::int
+		RETURNING id, version, created_at
+	`
+	err = tx.QueryRow(query, screenVersion.BranchId, widgets, settings, screenVersion.AuthorId, screenVersion.Message, screenVersion.RestoredFrom,
+		screenVersion.MergeSourceBranchId, screenVersion.MergeSourceVersion, screenVersion.Version).
+		Scan(&screenVersion.Id, &screenVersion.Version, &screenVersion.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || isUniqueViolation(err) {
+			err = ErrAlreadyExists
+		}
+		return err
+	}
+
+	// 2. Синхронизируем экран с головой Main
+	syncQuery := `
+		UPDATE screens sc
+		SET widgets = $2, settings = $3, updated_at = $4
+		FROM screens_branches b
+		WHERE b.id = $1 AND b.name = $5 AND sc.id = b.screen_id
+	`
+	_, err = tx.Exec(syncQuery, screenVersion.BranchId, widgets, settings, time.Now().UTC(), entity.ScreenMainBranch)
+	return err
+}
+
+// GetLastMerge возвращает последнюю версию ветки targetBranchId, созданную слиянием из sourceBranchId.
+func (s *VersionStorage) GetLastMerge(targetBranchId, sourceBranchId string) (screenVersion entity.ScreenVersion, err error) {
+	query := `
+		SELECT id, branch_id, version, COALESCE(author_id::text, '') AS author_id, message,
+			COALESCE(restored_from, 0) AS restored_from,
+			merge_source_branch_id::text AS merge_source_branch_id,
+			merge_source_version,
+			created_at
+		FROM screens_widgets
+		WHERE branch_id = $1 AND merge_source_branch_id = $2
+		ORDER BY version DESC
+		LIMIT 1
+	`
+	err = s.postgres.DB.Get(&screenVersion, query, targetBranchId, sourceBranchId)
+	return screenVersion, err
+}
@@ -0,0 +1,136 @@
+package storages
+
+import (
+	"encoding/json"
+	"time"
+
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/pkg/database"
+)
+
+type WidgetType interface {
+	GetAllByProjectId(projectId string) (widgetTypes []entity.WidgetType, err error)
+	GetByName(projectId, name string) (widgetType entity.WidgetType, err error)
+	Create(widgetType entity.WidgetType) (err error)
+	UpdateByName(widgetType entity.WidgetType) (err error)
+	DeleteByName(projectId, name string) (err error)
+	IsUsed(projectId, name string) (used bool, err error)
+}
+
+type WidgetTypeStorage struct {
+	postgres *database.PostgresDB
+	redis    *database.Redis
+}
+
+func NewWidgetTypeStorage(pg *database.PostgresDB, redis *database.Redis) *WidgetTypeStorage {
+	return &WidgetTypeStorage{
+		postgres: pg,
+		redis:    redis,
+	}
+}
+
+const widgetTypeSelect = `
+	SELECT project_id, name, COALESCE(description, ''), schema, allow_children,
+		COALESCE(created_by::text, ''), created_at, COALESCE(updated_at, created_at)
+	FROM projects_widget_types
+`
+
+func (s *WidgetTypeStorage) GetAllByProjectId(projectId string) (widgetTypes []entity.WidgetType, err error) {
+	rows, err := s.postgres.DB.Query(widgetTypeSelect+` WHERE project_id = $1 ORDER BY name`, projectId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		widgetType, err := scanWidgetType(rows)
+		if err != nil {
+			return nil, err
+		}
+		widgetTypes = append(widgetTypes, widgetType)
+	}
+	return widgetTypes, rows.Err()
+}
+
+func (s *WidgetTypeStorage) GetByName(projectId, name string) (widgetType entity.WidgetType, err error) {
+	row := s.postgres.DB.QueryRow(widgetTypeSelect+` WHERE project_id = $1 AND name = $2`, projectId, name)
+	return scanWidgetType(row)
+}
+
+func (s *WidgetTypeStorage) Create(widgetType entity.WidgetType) (err error) {
+	schema, err := json.Marshal(widgetType.Schema)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO projects_widget_types (project_id, name, description, schema, allow_children, created_by)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::uuid)
+	`
+	_, err = s.postgres.DB.Exec(query, widgetType.ProjectId, widgetType.Name, widgetType.Description, schema,
+		widgetType.AllowChildren, widgetType.CreatedBy)
+	if isUniqueViolation(err) {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+func (s *WidgetTypeStorage) UpdateByName(widgetType entity.WidgetType) (err error) {
+	schema, err := json.Marshal(widgetType.Schema)
+	if err != nil {
+		return err
+	}
+	query := `
+		UPDATE projects_widget_types
+		SET description = $3, schema = $4, allow_children = $5, updated_at = $6
+		WHERE project_id = $1 AND name = $2
+	`
+	res, err := s.postgres.DB.Exec(query, widgetType.ProjectId, widgetType.Name, widgetType.Description, schema,
+		widgetType.AllowChildren, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+func (s *WidgetTypeStorage) DeleteByName(projectId, name string) (err error) {
+	res, err := s.postgres.DB.Exec(`DELETE FROM projects_widget_types WHERE project_id = $1 AND name = $2`, projectId, name)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+// IsUsed проверяет, есть ли виджеты этого типа в головных версиях веток экранов проекта.
+func (s *WidgetTypeStorage) IsUsed(projectId, name string) (used bool, err error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM screens sc
+			JOIN screens_branches b ON b.screen_id = sc.id
+			JOIN LATERAL (
+				SELECT w.widgets FROM screens_widgets w WHERE w.branch_id = b.id ORDER BY w.version DESC LIMIT 1
+			) head ON TRUE
+			CROSS JOIN LATERAL jsonb_each(head.widgets) AS widget(id, value)
+			WHERE sc.project_id = $1 AND sc.deleted_at IS NULL AND widget.value->>'type' = $2
+		)
+	`
+	err = s.postgres.DB.QueryRow(query, projectId, name).Scan(&used)
+	return used, err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWidgetType(row rowScanner) (widgetType entity.WidgetType, err error) {
+	var schema []byte
+	err = row.Scan(&widgetType.ProjectId, &widgetType.Name, &widgetType.Description, &schema, &widgetType.AllowChildren,
+		&widgetType.CreatedBy, &widgetType.CreatedAt, &widgetType.UpdatedAt)
+	if err != nil {
+		return entity.WidgetType{}, err
+	}
+	if err = json.Unmarshal(schema, &widgetType.Schema); err != nil {
+		return entity.WidgetType{}, err
+	}
+	return widgetType, nil
+}
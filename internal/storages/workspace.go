@@ -0,0 +1,242 @@
+package storages
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/rs/zerolog"
+	"ui-platform-backend-service/internal/entity"
+	"ui-platform-backend-service/pkg/database"
+)
+
+type Workspace interface {
+	Create(workspace entity.Workspace, ownerId string) (workspaceId string, err error)
+	GetAllByUserId(userId string) ([]entity.Workspace, error)
+	GetById(workspaceId string) (entity.Workspace, error)
+	UpdateById(workspace entity.Workspace) error
+	DeleteById(workspaceId string) error
+	CountProjects(workspaceId string) (int, error)
+	GetMemberRole(workspaceId, userId string) (string, error)
+	GetMembers(workspaceId string) ([]entity.WorkspaceMember, error)
+	SetMember(workspaceId, userId, role string) error
+	RemoveMember(workspaceId, userId string) error
+	CountOwners(workspaceId string) (int, error)
+}
+
+type WorkspaceStorage struct {
+	postgres *database.PostgresDB
+	redis    *database.Redis
+	log      zerolog.Logger
+}
+
+func NewWorkspaceStorage(pg *database.PostgresDB, redis *database.Redis, log zerolog.Logger) *WorkspaceStorage {
+	return &WorkspaceStorage{
+		postgres: pg,
+		redis:    redis,
+		log:      log,
+	}
+}
+
+func (s *WorkspaceStorage) Create(workspace entity.Workspace, ownerId string) (string, error) {
+	s.log.Debug().Str("ownerId", ownerId).Interface("workspace", workspace).Msg("creating new workspace")
+
+	tx, err := s.postgres.DB.Begin()
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to begin transaction")
+		return "", err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	queryCreateWorkspace := `INSERT INTO workspaces (name, description) VALUES ($1, $2) RETURNING id`
+	var workspaceId string
+	err = tx.QueryRow(queryCreateWorkspace, workspace.Name, workspace.Description).Scan(&workspaceId)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to insert workspace")
+		tx.Rollback()
+		return "", err
+	}
+
+	queryAddOwner := `INSERT INTO workspaces_membership (workspace_id, user_id, role) VALUES ($1, $2, $3)`
+	_, err = tx.Exec(queryAddOwner, workspaceId, ownerId, entity.ProjectRoleOwner)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to add owner to workspace")
+		tx.Rollback()
+		return "", err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error().Err(err).Msg("failed to commit transaction")
+		return "", err
+	}
+
+	s.log.Debug().Str("workspaceId", workspaceId).Msg("workspace created successfully")
+	return workspaceId, nil
+}
+
+func (s *WorkspaceStorage) GetAllByUserId(userId string) ([]entity.Workspace, error) {
+	query := `
+		SELECT w.id, w.name, COALESCE(w.description, '') AS description, wm.role, w.created_at, COALESCE(w.updated_at, w.created_at) AS updated_at
+		FROM workspaces w
+		JOIN workspaces_membership wm ON w.id = wm.workspace_id
+		WHERE wm.user_id = $1 AND wm.deleted_at IS NULL AND w.deleted_at IS NULL
+		ORDER BY w.name, w.id
+	`
+	var workspaces []entity.Workspace
+	if err := s.postgres.DB.Select(&workspaces, query, userId); err != nil {
+		s.log.Error().Err(err).Msg("failed to query workspaces")
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+func (s *WorkspaceStorage) GetById(workspaceId string) (entity.Workspace, error) {
+	query := `
+		SELECT id, name, COALESCE(description, '') AS description, created_at, COALESCE(updated_at, created_at) AS updated_at
+		FROM workspaces
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	var workspace entity.Workspace
+	if err := s.postgres.DB.Get(&workspace, query, workspaceId); err != nil {
+		return entity.Workspace{}, err
+	}
+	return workspace, nil
+}
+
+func (s *WorkspaceStorage) UpdateById(workspace entity.Workspace) error {
+	query := `UPDATE workspaces SET name = $2, description = $3, updated_at = $4 WHERE id = $1 AND deleted_at IS NULL`
+	res, err := s.postgres.DB.Exec(query, workspace.ID, workspace.Name, workspace.Description, time.Now().UTC())
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to update workspace")
+		return err
+	}
+	return checkAffected(res)
+}
+
+func (s *WorkspaceStorage) DeleteById(workspaceId string) error {
+	query := `UPDATE workspaces SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`
+	res, err := s.postgres.DB.Exec(query, workspaceId, time.Now().UTC())
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to delete workspace")
+		return err
+	}
+	return checkAffected(res)
+}
+
+// CountProjects возвращает количество неудаленных проектов воркспейса.
+func (s *WorkspaceStorage) CountProjects(workspaceId string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM projects WHERE workspace_id = $1 AND deleted_at IS NULL`
+	if err := s.postgres.DB.QueryRow(query, workspaceId).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (s *WorkspaceStorage) GetMemberRole(workspaceId, userId string) (string, error) {
+	query := `
+		SELECT wm.role
+		FROM workspaces_membership wm
+		JOIN workspaces w ON w.id = wm.workspace_id
+		WHERE wm.workspace_id = $1 AND wm.user_id = $2 AND wm.deleted_at IS NULL AND w.deleted_at IS NULL
+	`
+	var role string
+	if err := s.postgres.DB.QueryRow(query, workspaceId, userId).Scan(&role); err != nil {
+		return "", err
+	}
+	return role, nil
+}
+
+func (s *WorkspaceStorage) GetMembers(workspaceId string) ([]entity.WorkspaceMember, error) {
+	query := `
+		SELECT wm.user_id, u.email, wm.role, wm.added_at
+		FROM workspaces_membership wm
+		JOIN users u ON u.id = wm.user_id
+		WHERE wm.workspace_id = $1 AND wm.deleted_at IS NULL
+		ORDER BY wm.added_at
+	`
+	var members []entity.WorkspaceMember
+	if err := s.postgres.DB.Select(&members, query, workspaceId); err != nil {
+		s.log.Error().Err(err).Msg("failed to query workspace members")
+		return nil, err
+	}
+	return members, nil
+}
+
+// SetMember добавляет участника в воркспейс или меняет его роль.
+func (s *WorkspaceStorage) SetMember(workspaceId, userId, role string) error {
+	query := `
+		INSERT INTO workspaces_membership (workspace_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (workspace_id, user_id)
+		DO UPDATE SET role = EXCLUDED.role, deleted_at = NULL
+	`
+	_, err := s.postgres.DB.Exec(query, workspaceId, userId, role)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to set workspace member")
+	}
+	return err
+}
+
+// RemoveMember исключает участника из воркспейса и отзывает его прямой доступ к проектам воркспейса,
+// так что проекты остаются за командой.
+func (s *WorkspaceStorage) RemoveMember(workspaceId, userId string) error {
+	tx, err := s.postgres.DB.Begin()
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	now := time.Now().UTC()
+	queryRemoveMember := `UPDATE workspaces_membership SET deleted_at = $3 WHERE workspace_id = $1 AND user_id = $2 AND deleted_at IS NULL`
+	res, err := tx.Exec(queryRemoveMember, workspaceId, userId, now)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to remove workspace member")
+		tx.Rollback()
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err == nil && rows == 0 {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	queryRevokeProjects := `
+		UPDATE projects_membership SET deleted_at = $3
+		WHERE user_id = $2 AND deleted_at IS NULL
+		  AND project_id IN (SELECT id FROM projects WHERE workspace_id = $1)
+	`
+	if _, err = tx.Exec(queryRevokeProjects, workspaceId, userId, now); err != nil {
+		s.log.Error().Err(err).Msg("failed to revoke workspace projects membership")
+		tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error().Err(err).Msg("failed to commit transaction")
+		return err
+	}
+	return nil
+}
+
+func (s *WorkspaceStorage) CountOwners(workspaceId string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM workspaces_membership WHERE workspace_id = $1 AND role = $2 AND deleted_at IS NULL`
+	if err := s.postgres.DB.QueryRow(query, workspaceId, entity.ProjectRoleOwner).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
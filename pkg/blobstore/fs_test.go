@@ -0,0 +1,68 @@
+package blobstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFSPutGetDelete(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewFS(filepath.Join(root, "blobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = store.Put("p1/a/logo.png", []byte("v1"), "image/png"); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.Put("p1/a/logo.png", []byte("v2"), "image/png"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := store.Get("p1/a/logo.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "v2" {
+		t.Fatalf("data = %q, want v2", data)
+	}
+
+	// Временные файлы после записи не остаются
+	entries, err := os.ReadDir(filepath.Join(root, "blobs", "p1", "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "logo.png" {
+		t.Fatalf("entries = %v, want only logo.png", entries)
+	}
+
+	if err = store.Delete("p1/a/logo.png"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.Get("p1/a/logo.png"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("get error = %v, want ErrNotFound", err)
+	}
+	if err = store.Delete("p1/a/logo.png"); err != nil {
+		t.Fatalf("delete error = %v, want nil", err)
+	}
+}
+
+func TestFSInvalidKey(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewFS(filepath.Join(root, "blobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"", "../outside", "p1/../../outside", "/abs", "p1//a", ".hidden", "p1/a b"} {
+		if err := store.Put(key, []byte("x"), ""); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("put %q error = %v, want ErrInvalidKey", key, err)
+		}
+		if _, err := store.Get(key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("get %q error = %v, want ErrInvalidKey", key, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "outside")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("file outside root: %v", err)
+	}
+}
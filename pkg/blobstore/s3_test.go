@@ -0,0 +1,166 @@
+package blobstore
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testAccessKey = "AKIDEXAMPLE"
+	testSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	testRegion    = "eu-central-1"
+)
+
+var testNow = time.Date(2024, 3, 5, 10, 20, 30, 0, time.UTC)
+
+// newTestS3 направляет все запросы клиента на тестовый сервер независимо от хоста,
+// чтобы проверять и адресацию бакета поддоменом.
+func newTestS3(t *testing.T, pathStyle bool, handler http.HandlerFunc) (*S3, *httptest.Server) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	store, err := NewS3(S3Config{
+		Endpoint:  server.URL,
+		Region:    testRegion,
+		Bucket:    "assets",
+		AccessKey: testAccessKey,
+		SecretKey: testSecretKey,
+		PathStyle: pathStyle,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.now = func() time.Time { return testNow }
+	store.client.Transport = &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+		},
+	}
+	return store, server
+}
+
+// checkSignature проверяет подпись запроса так, как ее проверяет S3: по заголовкам,
+// которые получил сервер.
+func checkSignature(t *testing.T, r *http.Request, body []byte) {
+	t.Helper()
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash != sha256Hex(body) {
+		t.Errorf("x-amz-content-sha256 = %q, want hash of body", payloadHash)
+	}
+	if got := r.Header.Get("X-Amz-Date"); got != "20240305T102030Z" {
+		t.Errorf("x-amz-date = %q", got)
+	}
+
+	canonicalRequest := r.Method + "\n" + r.URL.EscapedPath() + "\n" + r.URL.RawQuery + "\n" +
+		"host:" + r.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + r.Header.Get("X-Amz-Date") + "\n\n" +
+		"host;x-amz-content-sha256;x-amz-date\n" +
+		payloadHash
+	scope := "20240305/" + testRegion + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + r.Header.Get("X-Amz-Date") + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+	key := []byte("AWS4" + testSecretKey)
+	for _, part := range []string{"20240305", testRegion, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	want := "AWS4-HMAC-SHA256 Credential=" + testAccessKey + "/" + scope +
+		", SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=" + hex.EncodeToString(hmacSHA256(key, stringToSign))
+	if got := r.Header.Get("Authorization"); got != want {
+		t.Errorf("authorization = %q, want %q", got, want)
+	}
+}
+
+func TestS3PutPathStyle(t *testing.T) {
+	data := []byte("hello")
+	var requests int
+	var endpointHost string
+	store, server := newTestS3(t, true, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPut || r.URL.Path != "/assets/p1/logo.png" {
+			t.Errorf("request = %s %s, want PUT /assets/p1/logo.png", r.Method, r.URL.Path)
+		}
+		if r.Host != endpointHost {
+			t.Errorf("host = %q, want %q", r.Host, endpointHost)
+		}
+		if got := r.Header.Get("Content-Type"); got != "image/png" {
+			t.Errorf("content-type = %q", got)
+		}
+		if string(body) != "hello" {
+			t.Errorf("body = %q", body)
+		}
+		checkSignature(t, r, body)
+	})
+	endpointHost = strings.TrimPrefix(server.URL, "http://")
+
+	if err := store.Put("p1/logo.png", data, "image/png"); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Fatalf("requests = %d, want 1", requests)
+	}
+}
+
+func TestS3GetVirtualHost(t *testing.T) {
+	var bucketHost string
+	store, server := newTestS3(t, false, func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != bucketHost {
+			t.Errorf("host = %q, want %q", r.Host, bucketHost)
+		}
+		if r.Method != http.MethodGet || r.URL.Path != "/p1/logo.png" {
+			t.Errorf("request = %s %s, want GET /p1/logo.png", r.Method, r.URL.Path)
+		}
+		checkSignature(t, r, nil)
+		_, _ = w.Write([]byte("content"))
+	})
+	bucketHost = "assets." + strings.TrimPrefix(server.URL, "http://")
+
+	data, err := store.Get("p1/logo.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "content" {
+		t.Fatalf("data = %q", data)
+	}
+}
+
+func TestS3NotFound(t *testing.T) {
+	store, _ := newTestS3(t, true, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	if _, err := store.Get("p1/missing.png"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("get error = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete("p1/missing.png"); err != nil {
+		t.Fatalf("delete error = %v, want nil", err)
+	}
+}
+
+func TestS3Errors(t *testing.T) {
+	var requests int
+	store, _ := newTestS3(t, true, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("<Error><Code>AccessDenied</Code></Error>"))
+	})
+
+	if err := store.Put("p1/logo.png", []byte("x"), ""); err == nil || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Fatalf("put error = %v, want AccessDenied", err)
+	}
+	if err := store.Put("../logo.png", []byte("x"), ""); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("put error = %v, want ErrInvalidKey", err)
+	}
+	if requests != 1 {
+		t.Fatalf("requests = %d, want 1", requests)
+	}
+}
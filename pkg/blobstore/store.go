@@ -0,0 +1,30 @@
+// Package blobstore хранит бинарные объекты по ключу. Ключи - пути вида "a/b/c"
+// из латиницы, цифр, '.', '-' и '_'; объекты неизменяемы, повторная запись
+// по тому же ключу заменяет содержимое.
+package blobstore
+
+import (
+	"errors"
+	"regexp"
+)
+
+// ErrNotFound - объекта с ключом нет в хранилище.
+var ErrNotFound = errors.New("blob not found")
+
+// ErrInvalidKey - ключ не подходит под формат ключей хранилища.
+var ErrInvalidKey = errors.New("invalid blob key")
+
+type Store interface {
+	Put(key string, data []byte, contentType string) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+}
+
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*(/[A-Za-z0-9_-][A-Za-z0-9._-]*)*$`)
+
+func checkKey(key string) error {
+	if len(key) > 1024 || !keyPattern.MatchString(key) {
+		return ErrInvalidKey
+	}
+	return nil
+}
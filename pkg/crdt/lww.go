@@ -0,0 +1,200 @@
+// Package crdt реализует LWW-документ (last writer wins) над JSON-объектами
+// для совместного редактирования.
+//
+// Каждая операция получает Lamport-метку (Stamp) реплики, которая ее приняла.
+// Метки задают общий порядок операций на всех репликах; состояние документа -
+// результат применения операций к базе в порядке меток. Поэтому реплики,
+// получившие одинаковый набор операций в любом порядке, сходятся к одному
+// документу, а из двух записей в одно место побеждает более поздняя.
+//
+// Операции адресуют члены объектов (JSON Pointer). Операция, у которой
+// в момент применения нет родительского объекта, пропускается.
+package crdt
+
+import (
+	"sort"
+
+	"ui-platform-backend-service/pkg/jsonpatch"
+)
+
+const (
+	OpSet    = "set"
+	OpRemove = "remove"
+)
+
+// Stamp - Lamport-метка операции. При равных часах порядок определяет реплика.
+type Stamp struct {
+	Clock   uint64 `json:"clock"`
+	Replica string `json:"replica"`
+}
+
+// Less сообщает, что метка s предшествует метке other.
+func (s Stamp) Less(other Stamp) bool {
+	if s.Clock != other.Clock {
+		return s.Clock < other.Clock
+	}
+	return s.Replica < other.Replica
+}
+
+// Vector - наибольшие часы операций каждой реплики, вошедших в снимок.
+// Реплика рассылает свои операции по порядку, поэтому снимок с часами c
+// реплики r содержит все ее операции с часами не больше c.
+type Vector map[string]uint64
+
+// Covers сообщает, что операция с меткой stamp уже входит в снимок.
+func (v Vector) Covers(stamp Stamp) bool {
+	return stamp.Clock <= v[stamp.Replica]
+}
+
+func (v Vector) merge(other Vector) {
+	for replica, clock := range other {
+		if clock > v[replica] {
+			v[replica] = clock
+		}
+	}
+}
+
+// Op - операция над документом.
+type Op struct {
+	Type  string      `json:"type"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+	Stamp Stamp       `json:"stamp"`
+}
+
+// Document - LWW-документ одной реплики. Методы не потокобезопасны.
+type Document struct {
+	replica string
+	clock   uint64
+	base    interface{}
+	floor   Vector
+	applied Vector
+	ops     []Op
+	seen    map[Stamp]bool
+	state   interface{}
+}
+
+// NewDocument создает документ реплики replica с начальным состоянием base.
+func NewDocument(replica string, base interface{}) *Document {
+	return &Document{
+		replica: replica,
+		base:    base,
+		floor:   Vector{},
+		applied: Vector{},
+		seen:    map[Stamp]bool{},
+		state:   base,
+	}
+}
+
+// State возвращает текущее состояние документа. Результат нельзя изменять.
+func (d *Document) State() interface{} {
+	return d.state
+}
+
+// Vector возвращает вектор всех операций, примененных к документу.
+func (d *Document) Vector() Vector {
+	vector := Vector{}
+	vector.merge(d.floor)
+	vector.merge(d.applied)
+	return vector
+}
+
+// Pending возвращает число операций, еще не вошедших в базу документа.
+func (d *Document) Pending() int {
+	return len(d.ops)
+}
+
+// Local ставит на операцию новую метку реплики и применяет ее.
+func (d *Document) Local(op Op) Op {
+	d.clock++
+	op.Stamp = Stamp{Clock: d.clock, Replica: d.replica}
+	d.Apply(op)
+	return op
+}
+
+// Apply применяет операцию с уже проставленной меткой, например пришедшую
+// от другой реплики. Повторно полученная операция и операция, уже вошедшая
+// в базу (см. Rebase), игнорируются.
+func (d *Document) Apply(op Op) bool {
+	if d.seen[op.Stamp] || d.floor.Covers(op.Stamp) {
+		return false
+	}
+	d.seen[op.Stamp] = true
+	if op.Stamp.Clock > d.applied[op.Stamp.Replica] {
+		d.applied[op.Stamp.Replica] = op.Stamp.Clock
+	}
+	if op.Stamp.Clock > d.clock {
+		d.clock = op.Stamp.Clock
+	}
+
+	// Обычный случай - операция новее всех известных: применяем к состоянию
+	if len(d.ops) == 0 || d.ops[len(d.ops)-1].Stamp.Less(op.Stamp) {
+		d.ops = append(d.ops, op)
+		d.state = apply(d.state, op)
+		return true
+	}
+	// Опоздавшая операция: вставляем по порядку и переигрываем журнал
+	index := sort.Search(len(d.ops), func(i int) bool { return op.Stamp.Less(d.ops[i].Stamp) })
+	d.ops = append(d.ops, Op{})
+	copy(d.ops[index+1:], d.ops[index:])
+	d.ops[index] = op
+	d.replay()
+	return true
+}
+
+// Rebase заменяет базу документа снимком, включающим операции вектора vector.
+// Не вошедшие в снимок операции переигрываются поверх него.
+func (d *Document) Rebase(base interface{}, vector Vector) {
+	d.floor.merge(vector)
+	for _, clock := range vector {
+		if clock > d.clock {
+			d.clock = clock
+		}
+	}
+	kept := d.ops[:0]
+	for _, op := range d.ops {
+		if vector.Covers(op.Stamp) {
+			delete(d.seen, op.Stamp)
+		} else {
+			kept = append(kept, op)
+		}
+	}
+	d.ops = kept
+	d.base = base
+	d.replay()
+}
+
+func (d *Document) replay() {
+	d.state = d.base
+	for _, op := range d.ops {
+		d.state = apply(d.state, op)
+	}
+}
+
+func apply(state interface{}, op Op) interface{} {
+	tokens, err := jsonpatch.ParsePointer(op.Path)
+	if err != nil || len(tokens) == 0 {
+		return state
+	}
+	parent, err := jsonpatch.Get(state, jsonpatch.FormatPointer(tokens[:len(tokens)-1]))
+	if err != nil {
+		return state
+	}
+	if _, ok := parent.(map[string]interface{}); !ok {
+		return state
+	}
+
+	var updated interface{}
+	switch op.Type {
+	case OpSet:
+		updated, err = jsonpatch.Set(state, op.Path, op.Value)
+	case OpRemove:
+		updated, err = jsonpatch.Remove(state, op.Path)
+	default:
+		return state
+	}
+	if err != nil {
+		return state
+	}
+	return updated
+}
@@ -0,0 +1,180 @@
+package crdt
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func decode(t *testing.T, data string) interface{} {
+	t.Helper()
+	var value interface{}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		t.Fatalf("decode %s: %v", data, err)
+	}
+	return value
+}
+
+func checkState(t *testing.T, doc *Document, want string) {
+	t.Helper()
+	if state := doc.State(); !reflect.DeepEqual(state, decode(t, want)) {
+		t.Fatalf("state = %v, want %s", state, want)
+	}
+}
+
+func TestStampLess(t *testing.T) {
+	tests := []struct {
+		a, b Stamp
+		less bool
+	}{
+		{Stamp{1, "a"}, Stamp{2, "a"}, true},
+		{Stamp{2, "a"}, Stamp{1, "b"}, false},
+		{Stamp{1, "a"}, Stamp{1, "b"}, true},
+		{Stamp{1, "b"}, Stamp{1, "a"}, false},
+		{Stamp{1, "a"}, Stamp{1, "a"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.a.Less(tt.b); got != tt.less {
+			t.Errorf("%v.Less(%v) = %v, want %v", tt.a, tt.b, got, tt.less)
+		}
+	}
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	a := NewDocument("a", decode(t, `{"w": {}}`))
+	b := NewDocument("b", decode(t, `{"w": {}}`))
+
+	// Одинаковые часы: побеждает реплика с большим идентификатором
+	opA := a.Local(Op{Type: OpSet, Path: "/w/label", Value: "from a"})
+	opB := b.Local(Op{Type: OpSet, Path: "/w/label", Value: "from b"})
+	a.Apply(opB)
+	b.Apply(opA)
+	checkState(t, a, `{"w": {"label": "from b"}}`)
+	checkState(t, b, `{"w": {"label": "from b"}}`)
+
+	// Реплика, видевшая чужую операцию, ставит более позднюю метку
+	opA = a.Local(Op{Type: OpSet, Path: "/w/label", Value: "a again"})
+	if opA.Stamp.Clock != 2 {
+		t.Fatalf("clock = %d, want 2", opA.Stamp.Clock)
+	}
+	b.Apply(opA)
+	checkState(t, a, `{"w": {"label": "a again"}}`)
+	checkState(t, b, `{"w": {"label": "a again"}}`)
+}
+
+func TestDeliveryOrder(t *testing.T) {
+	ops := []Op{
+		{Type: OpSet, Path: "/w", Value: map[string]interface{}{}, Stamp: Stamp{1, "a"}},
+		{Type: OpSet, Path: "/w/x", Value: 1.0, Stamp: Stamp{2, "a"}},
+		{Type: OpSet, Path: "/w/x", Value: 2.0, Stamp: Stamp{2, "b"}},
+		{Type: OpRemove, Path: "/w/y", Stamp: Stamp{3, "a"}},
+		{Type: OpSet, Path: "/w/y", Value: "y", Stamp: Stamp{1, "b"}},
+	}
+	want := `{"w": {"x": 2}}`
+
+	orders := map[string][]int{
+		"in order":      {0, 1, 2, 3, 4},
+		"reversed":      {4, 3, 2, 1, 0},
+		"late parent":   {1, 2, 0, 4, 3},
+		"late loser":    {2, 3, 4, 0, 1},
+		"with repeated": {0, 2, 2, 1, 4, 3, 0},
+	}
+	for name, order := range orders {
+		t.Run(name, func(t *testing.T) {
+			doc := NewDocument("c", decode(t, `{}`))
+			for _, i := range order {
+				doc.Apply(ops[i])
+			}
+			checkState(t, doc, want)
+			if doc.Pending() != len(ops) {
+				t.Fatalf("pending = %d, want %d", doc.Pending(), len(ops))
+			}
+		})
+	}
+}
+
+func TestApplyDuplicate(t *testing.T) {
+	doc := NewDocument("a", decode(t, `{}`))
+	op := Op{Type: OpSet, Path: "/x", Value: 1.0, Stamp: Stamp{1, "b"}}
+	if !doc.Apply(op) {
+		t.Fatal("first apply ignored")
+	}
+	if doc.Apply(op) {
+		t.Fatal("repeated apply not ignored")
+	}
+}
+
+func TestMissingParent(t *testing.T) {
+	doc := NewDocument("a", decode(t, `{}`))
+	doc.Apply(Op{Type: OpSet, Path: "/w/x", Value: 1.0, Stamp: Stamp{1, "a"}})
+	checkState(t, doc, `{}`)
+
+	// Родитель, созданный позже по меткам, не оживляет пропущенную операцию
+	doc.Apply(Op{Type: OpSet, Path: "/w", Value: map[string]interface{}{}, Stamp: Stamp{2, "a"}})
+	checkState(t, doc, `{"w": {}}`)
+}
+
+func TestRebase(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    string
+		vector  Vector
+		state   string
+		pending int
+	}{
+		{
+			name:    "snapshot with some ops",
+			base:    `{"x": 1}`,
+			vector:  Vector{"b": 1},
+			state:   `{"x": 1, "y": 2, "z": 3}`,
+			pending: 2,
+		},
+		{
+			name:    "snapshot with all ops",
+			base:    `{"x": 1, "y": 2, "z": 3}`,
+			vector:  Vector{"b": 2, "a": 3},
+			state:   `{"x": 1, "y": 2, "z": 3}`,
+			pending: 0,
+		},
+		{
+			name:    "snapshot with newer edits",
+			base:    `{"x": 1, "w": true}`,
+			vector:  Vector{"b": 1, "c": 5},
+			state:   `{"x": 1, "y": 2, "z": 3, "w": true}`,
+			pending: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := NewDocument("a", decode(t, `{}`))
+			remote := []Op{
+				{Type: OpSet, Path: "/x", Value: 1.0, Stamp: Stamp{1, "b"}},
+				{Type: OpSet, Path: "/y", Value: 2.0, Stamp: Stamp{2, "b"}},
+			}
+			for _, op := range remote {
+				doc.Apply(op)
+			}
+			doc.Local(Op{Type: OpSet, Path: "/z", Value: 3.0})
+
+			doc.Rebase(decode(t, tt.base), tt.vector)
+			checkState(t, doc, tt.state)
+			if doc.Pending() != tt.pending {
+				t.Fatalf("pending = %d, want %d", doc.Pending(), tt.pending)
+			}
+
+			// Операции, вошедшие в снимок, при повторной доставке не применяются
+			if doc.Apply(remote[0]) {
+				t.Fatal("op covered by snapshot applied again")
+			}
+			// Новые операции реплики идут после всех, вошедших в снимок
+			if op := doc.Local(Op{Type: OpSet, Path: "/x", Value: 4.0}); op.Stamp.Clock <= tt.vector["c"] {
+				t.Fatalf("clock = %d after rebase to %v", op.Stamp.Clock, tt.vector)
+			}
+			for replica, clock := range tt.vector {
+				if doc.Vector()[replica] < clock {
+					t.Fatalf("vector = %v, want to include %v", doc.Vector(), tt.vector)
+				}
+			}
+		})
+	}
+}
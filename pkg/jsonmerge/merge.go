@@ -0,0 +1,180 @@
+// Package jsonmerge реализует трехстороннее слияние JSON-документов,
+// декодированных в interface{}.
+//
+// Слияние рекурсивно спускается по объектам. Непересекающиеся изменения двух
+// сторон объединяются автоматически, пересекающиеся попадают в список конфликтов,
+// а в результат для них записывается значение стороны ours.
+package jsonmerge
+
+import (
+	"reflect"
+	"sort"
+
+	"ui-platform-backend-service/pkg/jsonpatch"
+)
+
+// Conflict - расхождение двух сторон по одному пути документа.
+// Флаги *Deleted означают, что на соответствующей стороне значение по пути отсутствует.
+type Conflict struct {
+	Path          string      `json:"path"`
+	Base          interface{} `json:"base,omitempty"`
+	Ours          interface{} `json:"ours,omitempty"`
+	Theirs        interface{} `json:"theirs,omitempty"`
+	BaseDeleted   bool        `json:"base_deleted,omitempty"`
+	OursDeleted   bool        `json:"ours_deleted,omitempty"`
+	TheirsDeleted bool        `json:"theirs_deleted,omitempty"`
+}
+
+// ThreeWay сливает изменения ours и theirs относительно общего предка base.
+func ThreeWay(base, ours, theirs interface{}) (merged interface{}, conflicts []Conflict) {
+	m := &merger{}
+	merged, _ = m.merge(nil, value{base, true}, value{ours, true}, value{theirs, true})
+	return merged, m.conflicts
+}
+
+// value - JSON-значение с признаком присутствия: отсутствующий член объекта отличается от null.
+type value struct {
+	v  interface{}
+	ok bool
+}
+
+func (a value) equal(b value) bool {
+	return a.ok == b.ok && (!a.ok || reflect.DeepEqual(a.v, b.v))
+}
+
+type merger struct {
+	conflicts []Conflict
+}
+
+// merge возвращает результат слияния и признак его присутствия.
+func (m *merger) merge(path []string, base, ours, theirs value) (interface{}, bool) {
+	switch {
+	case ours.equal(theirs):
+		return ours.v, ours.ok
+	case base.equal(ours):
+		return theirs.v, theirs.ok
+	case base.equal(theirs):
+		return ours.v, ours.ok
+	}
+
+	// Обе стороны изменили значение по-разному
+	oursObject, oursIsObject := ours.v.(map[string]interface{})
+	theirsObject, theirsIsObject := theirs.v.(map[string]interface{})
+	if ours.ok && theirs.ok && oursIsObject && theirsIsObject {
+		baseObject, _ := base.v.(map[string]interface{})
+		return m.mergeObjects(path, baseObject, oursObject, theirsObject), true
+	}
+	if ours.ok && theirs.ok {
+		if merged, ok := mergeSets(base.v, ours.v, theirs.v); ok {
+			return merged, true
+		}
+	}
+
+	m.conflicts = append(m.conflicts, Conflict{
+		Path:          jsonpatch.FormatPointer(path),
+		Base:          base.v,
+		Ours:          ours.v,
+		Theirs:        theirs.v,
+		BaseDeleted:   !base.ok,
+		OursDeleted:   !ours.ok,
+		TheirsDeleted: !theirs.ok,
+	})
+	return ours.v, ours.ok
+}
+
+func (m *merger) mergeObjects(path []string, base, ours, theirs map[string]interface{}) map[string]interface{} {
+	keys := make(map[string]struct{}, len(ours)+len(theirs))
+	for k := range base {
+		keys[k] = struct{}{}
+	}
+	for k := range ours {
+		keys[k] = struct{}{}
+	}
+	for k := range theirs {
+		keys[k] = struct{}{}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	merged := make(map[string]interface{}, len(keys))
+	for _, k := range sorted {
+		b, bOk := base[k]
+		o, oOk := ours[k]
+		t, tOk := theirs[k]
+		childPath := append(append(make([]string, 0, len(path)+1), path...), k)
+		if v, ok := m.merge(childPath, value{b, bOk}, value{o, oOk}, value{t, tOk}); ok {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// mergeSets сливает массивы уникальных строк (например, списки дочерних виджетов)
+// как множества: порядок берется из ours, добавления theirs дописываются в конец.
+// Возвращает false, если значения не являются такими массивами.
+func mergeSets(base, ours, theirs interface{}) ([]interface{}, bool) {
+	baseSet, ok := stringSet(base)
+	if !ok && base != nil {
+		return nil, false
+	}
+	oursList, ok := ours.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	theirsList, ok := theirs.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	oursSet, ok := stringSet(oursList)
+	if !ok {
+		return nil, false
+	}
+	theirsSet, ok := stringSet(theirsList)
+	if !ok {
+		return nil, false
+	}
+
+	merged := make([]interface{}, 0, len(oursList)+len(theirsList))
+	for _, item := range oursList {
+		s := item.(string)
+		// Удалено на стороне theirs
+		if _, inBase := baseSet[s]; inBase {
+			if _, inTheirs := theirsSet[s]; !inTheirs {
+				continue
+			}
+		}
+		merged = append(merged, item)
+	}
+	for _, item := range theirsList {
+		s := item.(string)
+		_, inBase := baseSet[s]
+		_, inOurs := oursSet[s]
+		if !inBase && !inOurs {
+			merged = append(merged, item)
+		}
+	}
+	return merged, true
+}
+
+// stringSet возвращает множество элементов массива, если все они - уникальные строки.
+func stringSet(v interface{}) (map[string]struct{}, bool) {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	set := make(map[string]struct{}, len(list))
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		if _, dup := set[s]; dup {
+			return nil, false
+		}
+		set[s] = struct{}{}
+	}
+	return set, true
+}
@@ -0,0 +1,182 @@
+package jsonmerge
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func decode(t *testing.T, data string) interface{} {
+	t.Helper()
+	var value interface{}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		t.Fatalf("decode %s: %v", data, err)
+	}
+	return value
+}
+
+func TestThreeWay(t *testing.T) {
+	tests := []struct {
+		name      string
+		base      string
+		ours      string
+		theirs    string
+		want      string
+		conflicts []string
+	}{
+		{
+			name:   "disjoint changes",
+			base:   `{"a": 1, "b": 1}`,
+			ours:   `{"a": 2, "b": 1}`,
+			theirs: `{"a": 1, "b": 2, "c": 3}`,
+			want:   `{"a": 2, "b": 2, "c": 3}`,
+		},
+		{
+			name:   "same change on both sides",
+			base:   `{"a": 1}`,
+			ours:   `{"a": 2}`,
+			theirs: `{"a": 2}`,
+			want:   `{"a": 2}`,
+		},
+		{
+			name:   "nested objects",
+			base:   `{"w": {"props": {"x": 1, "y": 1}}}`,
+			ours:   `{"w": {"props": {"x": 2, "y": 1}}}`,
+			theirs: `{"w": {"props": {"x": 1, "y": 2}}}`,
+			want:   `{"w": {"props": {"x": 2, "y": 2}}}`,
+		},
+		{
+			name:      "conflicting values keep ours",
+			base:      `{"w": {"label": "a"}}`,
+			ours:      `{"w": {"label": "b"}}`,
+			theirs:    `{"w": {"label": "c"}}`,
+			want:      `{"w": {"label": "b"}}`,
+			conflicts: []string{"/w/label"},
+		},
+		{
+			name:      "modify and delete",
+			base:      `{"a": 1, "b": 1}`,
+			ours:      `{"a": 2, "b": 1}`,
+			theirs:    `{"b": 1}`,
+			want:      `{"a": 2, "b": 1}`,
+			conflicts: []string{"/a"},
+		},
+		{
+			name:   "delete on one side",
+			base:   `{"a": 1, "b": 1}`,
+			ours:   `{"a": 1, "b": 1}`,
+			theirs: `{"b": 1}`,
+			want:   `{"b": 1}`,
+		},
+		{
+			name:      "escaped conflict path",
+			base:      `{"a/b": {"~": 1}}`,
+			ours:      `{"a/b": {"~": 2}}`,
+			theirs:    `{"a/b": {"~": 3}}`,
+			want:      `{"a/b": {"~": 2}}`,
+			conflicts: []string{"/a~1b/~0"},
+		},
+		{
+			name:   "set additions on both sides",
+			base:   `{"children": ["x"]}`,
+			ours:   `{"children": ["x", "a"]}`,
+			theirs: `{"children": ["x", "b"]}`,
+			want:   `{"children": ["x", "a", "b"]}`,
+		},
+		{
+			name:   "set removal and addition",
+			base:   `{"children": ["x", "y"]}`,
+			ours:   `{"children": ["y", "x", "a"]}`,
+			theirs: `{"children": ["y"]}`,
+			want:   `{"children": ["y", "a"]}`,
+		},
+		{
+			name:   "set added on both sides",
+			base:   `{}`,
+			ours:   `{"children": ["a"]}`,
+			theirs: `{"children": ["b"]}`,
+			want:   `{"children": ["a", "b"]}`,
+		},
+		{
+			name:      "arrays of numbers conflict",
+			base:      `{"list": [1]}`,
+			ours:      `{"list": [1, 2]}`,
+			theirs:    `{"list": [1, 3]}`,
+			want:      `{"list": [1, 2]}`,
+			conflicts: []string{"/list"},
+		},
+		{
+			name:      "arrays with duplicates conflict",
+			base:      `{"list": ["a"]}`,
+			ours:      `{"list": ["a", "a"]}`,
+			theirs:    `{"list": ["a", "b"]}`,
+			want:      `{"list": ["a", "a"]}`,
+			conflicts: []string{"/list"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflicts := ThreeWay(decode(t, tt.base), decode(t, tt.ours), decode(t, tt.theirs))
+			if want := decode(t, tt.want); !reflect.DeepEqual(merged, want) {
+				t.Fatalf("merged = %v, want %v", merged, want)
+			}
+			paths := make([]string, 0, len(conflicts))
+			for _, conflict := range conflicts {
+				paths = append(paths, conflict.Path)
+			}
+			if len(paths) != len(tt.conflicts) || (len(paths) > 0 && !reflect.DeepEqual(paths, tt.conflicts)) {
+				t.Fatalf("conflicts = %v, want %v", paths, tt.conflicts)
+			}
+		})
+	}
+}
+
+func TestThreeWayConflictSides(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   string
+		ours   string
+		theirs string
+		want   Conflict
+	}{
+		{
+			name:   "both modified",
+			base:   `{"a": 1}`,
+			ours:   `{"a": 2}`,
+			theirs: `{"a": 3}`,
+			want:   Conflict{Path: "/a", Base: 1.0, Ours: 2.0, Theirs: 3.0},
+		},
+		{
+			name:   "deleted by theirs",
+			base:   `{"a": 1}`,
+			ours:   `{"a": 2}`,
+			theirs: `{}`,
+			want:   Conflict{Path: "/a", Base: 1.0, Ours: 2.0, TheirsDeleted: true},
+		},
+		{
+			name:   "deleted by ours",
+			base:   `{"a": 1}`,
+			ours:   `{}`,
+			theirs: `{"a": 3}`,
+			want:   Conflict{Path: "/a", Base: 1.0, OursDeleted: true, Theirs: 3.0},
+		},
+		{
+			name:   "added on both sides",
+			base:   `{}`,
+			ours:   `{"a": 2}`,
+			theirs: `{"a": null}`,
+			want:   Conflict{Path: "/a", BaseDeleted: true, Ours: 2.0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, conflicts := ThreeWay(decode(t, tt.base), decode(t, tt.ours), decode(t, tt.theirs))
+			if len(conflicts) != 1 {
+				t.Fatalf("conflicts = %+v, want one", conflicts)
+			}
+			if !reflect.DeepEqual(conflicts[0], tt.want) {
+				t.Fatalf("conflict = %+v, want %+v", conflicts[0], tt.want)
+			}
+		})
+	}
+}
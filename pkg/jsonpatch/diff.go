@@ -0,0 +1,121 @@
+package jsonpatch
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Diff строит JSON Patch, который превращает документ from в документ to.
+// Объекты сравниваются по ключам рекурсивно, массивы равной длины - поэлементно,
+// остальные массивы - через наибольшую общую подпоследовательность.
+func Diff(from, to interface{}) []Operation {
+	ops := []Operation{}
+	diff(nil, from, to, &ops)
+	return ops
+}
+
+func diff(path []string, from, to interface{}, ops *[]Operation) {
+	if reflect.DeepEqual(from, to) {
+		return
+	}
+	switch f := from.(type) {
+	case map[string]interface{}:
+		if t, ok := to.(map[string]interface{}); ok {
+			diffObjects(path, f, t, ops)
+			return
+		}
+	case []interface{}:
+		if t, ok := to.([]interface{}); ok {
+			diffArrays(path, f, t, ops)
+			return
+		}
+	}
+	*ops = append(*ops, Operation{Op: OpReplace, Path: FormatPointer(path), Value: to})
+}
+
+func diffObjects(path []string, from, to map[string]interface{}, ops *[]Operation) {
+	for _, k := range sortedKeys(from) {
+		if _, ok := to[k]; !ok {
+			*ops = append(*ops, Operation{Op: OpRemove, Path: FormatPointer(child(path, k))})
+		}
+	}
+	for _, k := range sortedKeys(to) {
+		f, ok := from[k]
+		if !ok {
+			*ops = append(*ops, Operation{Op: OpAdd, Path: FormatPointer(child(path, k)), Value: to[k]})
+			continue
+		}
+		diff(child(path, k), f, to[k], ops)
+	}
+}
+
+func diffArrays(path []string, from, to []interface{}, ops *[]Operation) {
+	if len(from) == len(to) {
+		for i := range from {
+			diff(child(path, strconv.Itoa(i)), from[i], to[i], ops)
+		}
+		return
+	}
+
+	// Элементы вне общей подпоследовательности удаляются с конца,
+	// затем недостающие добавляются по возрастанию индексов итогового массива
+	keepFrom, keepTo := lcs(from, to)
+	for i := len(from) - 1; i >= 0; i-- {
+		if !keepFrom[i] {
+			*ops = append(*ops, Operation{Op: OpRemove, Path: FormatPointer(child(path, strconv.Itoa(i)))})
+		}
+	}
+	for i := range to {
+		if !keepTo[i] {
+			*ops = append(*ops, Operation{Op: OpAdd, Path: FormatPointer(child(path, strconv.Itoa(i))), Value: to[i]})
+		}
+	}
+}
+
+// lcs отмечает элементы обоих массивов, входящие в наибольшую общую подпоследовательность.
+func lcs(a, b []interface{}) (keepA, keepB []bool) {
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if reflect.DeepEqual(a[i], b[j]) {
+				table[i][j] = table[i+1][j+1] + 1
+			} else if table[i+1][j] >= table[i][j+1] {
+				table[i][j] = table[i+1][j]
+			} else {
+				table[i][j] = table[i][j+1]
+			}
+		}
+	}
+
+	keepA, keepB = make([]bool, len(a)), make([]bool, len(b))
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case reflect.DeepEqual(a[i], b[j]):
+			keepA[i], keepB[j] = true, true
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return keepA, keepB
+}
+
+func child(path []string, token string) []string {
+	return append(append(make([]string, 0, len(path)+1), path...), token)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,148 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+var (
+	// ErrInvalidOperation - операция патча некорректна.
+	ErrInvalidOperation = errors.New("invalid patch operation")
+	// ErrTestFailed - операция test не совпала с документом.
+	ErrTestFailed = errors.New("patch test failed")
+)
+
+// Operation - операция JSON Patch (RFC 6902).
+type Operation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+	// noValue - в разобранном JSON не было члена value; у операций, собранных в коде, false
+	noValue bool
+}
+
+// MarshalJSON всегда пишет value у add, replace и test: для них член обязателен
+// (RFC 6902, 4.1), и замена на null без него была бы некорректной операцией.
+func (o Operation) MarshalJSON() ([]byte, error) {
+	type operation Operation
+	if o.Op != OpAdd && o.Op != OpReplace && o.Op != OpTest {
+		return json.Marshal(operation(o))
+	}
+	return json.Marshal(struct {
+		operation
+		Value interface{} `json:"value"`
+	}{operation(o), o.Value})
+}
+
+// UnmarshalJSON запоминает, был ли у операции член value: "value": null и отсутствие
+// value различаются, и add, replace и test без value некорректны (RFC 6902, 4.1).
+func (o *Operation) UnmarshalJSON(data []byte) error {
+	type operation Operation
+	var decoded struct {
+		operation
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*o = Operation(decoded.operation)
+	o.noValue = decoded.Value == nil
+	if o.noValue {
+		return nil
+	}
+	return json.Unmarshal(decoded.Value, &o.Value)
+}
+
+// Apply применяет JSON Patch (RFC 6902) к документу и возвращает новый документ.
+// Операции применяются по порядку; при ошибке патч не применяется целиком.
+func Apply(doc interface{}, ops []Operation) (interface{}, error) {
+	var err error
+	for i, op := range ops {
+		doc, err = applyOperation(doc, op)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+func applyOperation(doc interface{}, op Operation) (interface{}, error) {
+	if op.noValue && (op.Op == OpAdd || op.Op == OpReplace || op.Op == OpTest) {
+		return nil, fmt.Errorf("%w: %s requires value", ErrInvalidOperation, op.Op)
+	}
+	switch op.Op {
+	case OpAdd:
+		return Set(doc, op.Path, op.Value)
+	case OpRemove:
+		return Remove(doc, op.Path)
+	case OpReplace:
+		return Replace(doc, op.Path, op.Value)
+	case OpMove:
+		if op.From == op.Path {
+			return doc, nil
+		}
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("%w: cannot move a value into itself", ErrInvalidOperation)
+		}
+		value, err := Get(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		if doc, err = Remove(doc, op.From); err != nil {
+			return nil, err
+		}
+		return Set(doc, op.Path, value)
+	case OpCopy:
+		value, err := Get(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return Set(doc, op.Path, value)
+	case OpTest:
+		value, err := Get(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(value, op.Value) {
+			return nil, ErrTestFailed
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidOperation, op.Op)
+}
+
+// MergePatch применяет JSON Merge Patch (RFC 7396): объекты сливаются рекурсивно,
+// null удаляет член объекта, любое другое значение заменяет цель целиком.
+func MergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return DeepCopy(patch)
+	}
+	result := map[string]interface{}{}
+	if targetObject, ok := target.(map[string]interface{}); ok {
+		for k, v := range targetObject {
+			result[k] = v
+		}
+	}
+	for k, v := range patchObject {
+		if v == nil {
+			delete(result, k)
+			continue
+		}
+		result[k] = MergePatch(result[k], v)
+	}
+	return result
+}
@@ -0,0 +1,217 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func decode(t *testing.T, data string) interface{} {
+	t.Helper()
+	var value interface{}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		t.Fatalf("decode %s: %v", data, err)
+	}
+	return value
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+		err   error
+	}{
+		{
+			name:  "escaped slash",
+			doc:   `{"a/b": 1}`,
+			patch: `[{"op": "replace", "path": "/a~1b", "value": 2}]`,
+			want:  `{"a/b": 2}`,
+		},
+		{
+			name:  "escaped tilde",
+			doc:   `{"m~n": 1}`,
+			patch: `[{"op": "remove", "path": "/m~0n"}]`,
+			want:  `{}`,
+		},
+		{
+			name:  "tilde before one is not a slash",
+			doc:   `{"~1": 1, "/": 2}`,
+			patch: `[{"op": "remove", "path": "/~01"}]`,
+			want:  `{"/": 2}`,
+		},
+		{
+			name:  "move between objects",
+			doc:   `{"a": {"x": 1}, "b": {}}`,
+			patch: `[{"op": "move", "from": "/a/x", "path": "/b/y"}]`,
+			want:  `{"a": {}, "b": {"y": 1}}`,
+		},
+		{
+			name:  "move within array",
+			doc:   `{"list": [1, 2, 3]}`,
+			patch: `[{"op": "move", "from": "/list/0", "path": "/list/-"}]`,
+			want:  `{"list": [2, 3, 1]}`,
+		},
+		{
+			name:  "move into sibling with common prefix",
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "move", "from": "/a", "path": "/ab"}]`,
+			want:  `{"ab": 1}`,
+		},
+		{
+			name:  "move into descendant",
+			doc:   `{"a": {"b": {}}}`,
+			patch: `[{"op": "move", "from": "/a", "path": "/a/b/c"}]`,
+			err:   ErrInvalidOperation,
+		},
+		{
+			name:  "move to itself",
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "move", "from": "/a", "path": "/a"}]`,
+			want:  `{"a": 1}`,
+		},
+		{
+			name:  "copy keeps source",
+			doc:   `{"a": {"x": [1]}}`,
+			patch: `[{"op": "copy", "from": "/a", "path": "/b"}, {"op": "add", "path": "/b/x/-", "value": 2}]`,
+			want:  `{"a": {"x": [1]}, "b": {"x": [1, 2]}}`,
+		},
+		{
+			name:  "copy from missing path",
+			doc:   `{}`,
+			patch: `[{"op": "copy", "from": "/a", "path": "/b"}]`,
+			err:   ErrPathNotFound,
+		},
+		{
+			name:  "add null value",
+			doc:   `{}`,
+			patch: `[{"op": "add", "path": "/a", "value": null}]`,
+			want:  `{"a": null}`,
+		},
+		{
+			name:  "add without value",
+			doc:   `{}`,
+			patch: `[{"op": "add", "path": "/a"}]`,
+			err:   ErrInvalidOperation,
+		},
+		{
+			name:  "replace without value",
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "replace", "path": "/a"}]`,
+			err:   ErrInvalidOperation,
+		},
+		{
+			name:  "test without value",
+			doc:   `{"a": null}`,
+			patch: `[{"op": "test", "path": "/a"}]`,
+			err:   ErrInvalidOperation,
+		},
+		{
+			name:  "test null",
+			doc:   `{"a": null}`,
+			patch: `[{"op": "test", "path": "/a", "value": null}]`,
+			want:  `{"a": null}`,
+		},
+		{
+			name:  "test mismatch",
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "test", "path": "/a", "value": 2}]`,
+			err:   ErrTestFailed,
+		},
+		{
+			name:  "unknown op",
+			doc:   `{}`,
+			patch: `[{"op": "merge", "path": "/a", "value": 1}]`,
+			err:   ErrInvalidOperation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ops []Operation
+			if err := json.Unmarshal([]byte(tt.patch), &ops); err != nil {
+				t.Fatal(err)
+			}
+			doc := decode(t, tt.doc)
+			got, err := Apply(doc, ops)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := decode(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Fatalf("result = %v, want %v", got, want)
+			}
+			if !reflect.DeepEqual(doc, decode(t, tt.doc)) {
+				t.Fatalf("source document changed: %v", doc)
+			}
+		})
+	}
+}
+
+func TestOperationJSON(t *testing.T) {
+	tests := []struct {
+		op   Operation
+		want string
+	}{
+		{Operation{Op: OpAdd, Path: "/a"}, `{"op":"add","path":"/a","value":null}`},
+		{Operation{Op: OpReplace, Path: "/a", Value: 1.0}, `{"op":"replace","path":"/a","value":1}`},
+		{Operation{Op: OpTest, Path: "/a"}, `{"op":"test","path":"/a","value":null}`},
+		{Operation{Op: OpRemove, Path: "/a"}, `{"op":"remove","path":"/a"}`},
+		{Operation{Op: OpMove, From: "/a", Path: "/b"}, `{"op":"move","path":"/b","from":"/a"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.op.Op, func(t *testing.T) {
+			data, err := json.Marshal(tt.op)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Fatalf("json = %s, want %s", data, tt.want)
+			}
+
+			var decoded Operation
+			if err = json.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if _, err = Apply(map[string]interface{}{"a": nil}, []Operation{decoded}); errors.Is(err, ErrInvalidOperation) {
+				t.Fatalf("round trip lost value: %v", err)
+			}
+		})
+	}
+}
+
+func TestPointerEscaping(t *testing.T) {
+	tests := []struct {
+		tokens  []string
+		pointer string
+	}{
+		{[]string{}, ""},
+		{[]string{""}, "/"},
+		{[]string{"a/b", "c"}, "/a~1b/c"},
+		{[]string{"m~n"}, "/m~0n"},
+		{[]string{"~1"}, "/~01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			if got := FormatPointer(tt.tokens); got != tt.pointer {
+				t.Fatalf("FormatPointer = %q, want %q", got, tt.pointer)
+			}
+			tokens, err := ParsePointer(tt.pointer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tokens, tt.tokens) {
+				t.Fatalf("ParsePointer = %q, want %q", tokens, tt.tokens)
+			}
+		})
+	}
+	if _, err := ParsePointer("a/b"); !errors.Is(err, ErrInvalidPointer) {
+		t.Fatalf("ParsePointer without leading slash: error = %v, want ErrInvalidPointer", err)
+	}
+}
@@ -0,0 +1,254 @@
+// Package jsonpatch реализует работу с JSON-документами, декодированными
+// в interface{} (map[string]interface{}, []interface{}, string, float64, bool, nil):
+// JSON Pointer (RFC 6901) и сопутствующие операции чтения и изменения.
+//
+// Функции пакета не изменяют переданные документы: изменяемые узлы копируются.
+package jsonpatch
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidPointer - строка не является корректным JSON Pointer.
+	ErrInvalidPointer = errors.New("invalid json pointer")
+	// ErrPathNotFound - по указанному пути нет значения.
+	ErrPathNotFound = errors.New("path not found")
+)
+
+// ParsePointer разбирает JSON Pointer на экранированные обратно токены.
+// Пустая строка указывает на весь документ.
+func ParsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidPointer, pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// FormatPointer собирает JSON Pointer из токенов с экранированием "~" и "/".
+func FormatPointer(tokens []string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteByte('/')
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
+
+// Get возвращает значение по JSON Pointer.
+func Get(doc interface{}, pointer string) (interface{}, error) {
+	tokens, err := ParsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	return get(doc, tokens)
+}
+
+func get(doc interface{}, tokens []string) (interface{}, error) {
+	node := doc
+	for i, token := range tokens {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			value, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrPathNotFound, FormatPointer(tokens[:i+1]))
+			}
+			node = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(n), false)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrPathNotFound, FormatPointer(tokens[:i+1]))
+			}
+			node = n[index]
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrPathNotFound, FormatPointer(tokens[:i+1]))
+		}
+	}
+	return node, nil
+}
+
+// Set записывает значение по пути: заменяет член объекта или добавляет новый,
+// в массиве вставляет элемент по индексу ("-" - в конец). Возвращает новый документ.
+func Set(doc interface{}, pointer string, value interface{}) (interface{}, error) {
+	tokens, err := ParsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	return update(doc, tokens, func(parent interface{}, token string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			p[token] = DeepCopy(value)
+			return p, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(p), true)
+			if err != nil {
+				return nil, err
+			}
+			p = append(p, nil)
+			copy(p[index+1:], p[index:])
+			p[index] = DeepCopy(value)
+			return p, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrPathNotFound, pointer)
+	}, value)
+}
+
+// Replace заменяет существующее значение по пути. Возвращает новый документ.
+func Replace(doc interface{}, pointer string, value interface{}) (interface{}, error) {
+	tokens, err := ParsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	return update(doc, tokens, func(parent interface{}, token string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := p[token]; !ok {
+				return nil, fmt.Errorf("%w: %s", ErrPathNotFound, pointer)
+			}
+			p[token] = DeepCopy(value)
+			return p, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(p), false)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrPathNotFound, pointer)
+			}
+			p[index] = DeepCopy(value)
+			return p, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrPathNotFound, pointer)
+	}, value)
+}
+
+// Remove удаляет значение по пути. Возвращает новый документ.
+func Remove(doc interface{}, pointer string) (interface{}, error) {
+	tokens, err := ParsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	return update(doc, tokens, func(parent interface{}, token string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := p[token]; !ok {
+				return nil, fmt.Errorf("%w: %s", ErrPathNotFound, pointer)
+			}
+			delete(p, token)
+			return p, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(p), false)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrPathNotFound, pointer)
+			}
+			return append(p[:index], p[index+1:]...), nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrPathNotFound, pointer)
+	}, nil)
+}
+
+// update копирует путь до родителя последнего токена и применяет к копии родителя fn.
+// Пустой путь заменяет весь документ значением root.
+func update(doc interface{}, tokens []string, fn func(parent interface{}, token string) (interface{}, error), root interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return DeepCopy(root), nil
+	}
+	return updateNode(doc, tokens, 0, fn)
+}
+
+func updateNode(node interface{}, tokens []string, depth int, fn func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	token := tokens[depth]
+	if depth == len(tokens)-1 {
+		return fn(shallowCopy(node), token)
+	}
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[token]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrPathNotFound, FormatPointer(tokens[:depth+1]))
+		}
+		updated, err := updateNode(child, tokens, depth+1, fn)
+		if err != nil {
+			return nil, err
+		}
+		copied := shallowCopy(n).(map[string]interface{})
+		copied[token] = updated
+		return copied, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(n), false)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrPathNotFound, FormatPointer(tokens[:depth+1]))
+		}
+		updated, err := updateNode(n[index], tokens, depth+1, fn)
+		if err != nil {
+			return nil, err
+		}
+		copied := shallowCopy(n).([]interface{})
+		copied[index] = updated
+		return copied, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrPathNotFound, FormatPointer(tokens[:depth+1]))
+}
+
+// arrayIndex разбирает индекс массива. allowEnd разрешает "-" и индекс, равный длине.
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if token == "-" && allowEnd {
+		return length, nil
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPointer, token)
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPointer, token)
+	}
+	if index > length || (index == length && !allowEnd) {
+		return 0, fmt.Errorf("%w: array index %d out of range", ErrPathNotFound, index)
+	}
+	return index, nil
+}
+
+func shallowCopy(node interface{}) interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(n))
+		for k, v := range n {
+			copied[k] = v
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(n))
+		copy(copied, n)
+		return copied
+	}
+	return node
+}
+
+// DeepCopy возвращает полную копию JSON-значения.
+func DeepCopy(node interface{}) interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(n))
+		for k, v := range n {
+			copied[k] = DeepCopy(v)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(n))
+		for i, v := range n {
+			copied[i] = DeepCopy(v)
+		}
+		return copied
+	}
+	return node
+}
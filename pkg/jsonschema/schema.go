@@ -0,0 +1,245 @@
+// Package jsonschema реализует подмножество JSON Schema, достаточное для описания
+// свойств виджетов: type, properties, required, additionalProperties, items, enum,
+// minLength/maxLength/pattern, minimum/maximum, minItems/maxItems.
+//
+// Проверяются документы, декодированные в interface{} (числа - float64).
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	TypeObject  = "object"
+	TypeArray   = "array"
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeInteger = "integer"
+	TypeBoolean = "boolean"
+	TypeNull    = "null"
+)
+
+// ErrInvalidSchema - схема использует неподдерживаемые ключевые слова или противоречива.
+var ErrInvalidSchema = errors.New("invalid schema")
+
+// Schema - схема JSON-значения. Пустой Type разрешает значение любого типа.
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+// Error - нарушение схемы. Path - JSON Pointer на значение в проверяемом документе.
+type Error struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e Error) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// Check проверяет корректность самой схемы.
+func (s *Schema) Check() error {
+	return s.check("")
+}
+
+func (s *Schema) check(path string) error {
+	if s == nil {
+		return fmt.Errorf("%w: %s: empty schema", ErrInvalidSchema, pathOrRoot(path))
+	}
+	switch s.Type {
+	case "", TypeObject, TypeArray, TypeString, TypeNumber, TypeInteger, TypeBoolean, TypeNull:
+	default:
+		return fmt.Errorf("%w: %s: unknown type %q", ErrInvalidSchema, pathOrRoot(path), s.Type)
+	}
+	if s.Pattern != "" {
+		if _, err := regexp.Compile(s.Pattern); err != nil {
+			return fmt.Errorf("%w: %s: invalid pattern", ErrInvalidSchema, pathOrRoot(path))
+		}
+	}
+	if s.MinLength != nil && s.MaxLength != nil && *s.MinLength > *s.MaxLength ||
+		s.Minimum != nil && s.Maximum != nil && *s.Minimum > *s.Maximum ||
+		s.MinItems != nil && s.MaxItems != nil && *s.MinItems > *s.MaxItems {
+		return fmt.Errorf("%w: %s: minimum is greater than maximum", ErrInvalidSchema, pathOrRoot(path))
+	}
+	for _, name := range s.Required {
+		if s.Properties == nil || s.Properties[name] == nil {
+			return fmt.Errorf("%w: %s: required property %q is not declared", ErrInvalidSchema, pathOrRoot(path), name)
+		}
+	}
+	for _, name := range sortedNames(s.Properties) {
+		if err := s.Properties[name].check(path + "/properties/" + escape(name)); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.check(path + "/items")
+	}
+	return nil
+}
+
+// Validate проверяет значение по схеме и возвращает все найденные нарушения.
+// Пути ошибок строятся от path - указателя на value в исходном документе.
+func (s *Schema) Validate(value interface{}, path string) []Error {
+	var errs []Error
+	s.validate(value, path, &errs)
+	return errs
+}
+
+func (s *Schema) validate(value interface{}, path string, errs *[]Error) {
+	if s == nil {
+		return
+	}
+	if s.Type != "" && !hasType(value, s.Type) {
+		*errs = append(*errs, Error{Path: path, Message: "must be " + s.Type})
+		return
+	}
+	if len(s.Enum) > 0 && !inEnum(value, s.Enum) {
+		*errs = append(*errs, Error{Path: path, Message: "must be one of " + formatEnum(s.Enum)})
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := len([]rune(v))
+		if s.MinLength != nil && length < *s.MinLength {
+			*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("must be at least %d characters", *s.MinLength)})
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("must be at most %d characters", *s.MaxLength)})
+		}
+		if s.Pattern != "" {
+			if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(v) {
+				*errs = append(*errs, Error{Path: path, Message: "must match pattern " + s.Pattern})
+			}
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			*errs = append(*errs, Error{Path: path, Message: "must be at least " + formatNumber(*s.Minimum)})
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			*errs = append(*errs, Error{Path: path, Message: "must be at most " + formatNumber(*s.Maximum)})
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("must have at least %d items", *s.MinItems)})
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("must have at most %d items", *s.MaxItems)})
+		}
+		for i, item := range v {
+			s.Items.validate(item, path+"/"+strconv.Itoa(i), errs)
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, Error{Path: path + "/" + escape(name), Message: "is required"})
+			}
+		}
+		for _, name := range sortedKeys(v) {
+			property, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*errs = append(*errs, Error{Path: path + "/" + escape(name), Message: "is not allowed"})
+				}
+				continue
+			}
+			property.validate(v[name], path+"/"+escape(name), errs)
+		}
+	}
+}
+
+func hasType(value interface{}, typ string) bool {
+	switch typ {
+	case TypeObject:
+		_, ok := value.(map[string]interface{})
+		return ok
+	case TypeArray:
+		_, ok := value.([]interface{})
+		return ok
+	case TypeString:
+		_, ok := value.(string)
+		return ok
+	case TypeNumber:
+		_, ok := value.(float64)
+		return ok
+	case TypeInteger:
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case TypeBoolean:
+		_, ok := value.(bool)
+		return ok
+	case TypeNull:
+		return value == nil
+	}
+	return false
+}
+
+func inEnum(value interface{}, enum []interface{}) bool {
+	for _, allowed := range enum {
+		if reflect.DeepEqual(value, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatEnum(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, value := range enum {
+		values[i] = fmt.Sprint(value)
+	}
+	return strings.Join(values, ", ")
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+func escape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedNames(m map[string]*Schema) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
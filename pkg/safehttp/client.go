@@ -0,0 +1,115 @@
+// Package safehttp выполняет HTTP-запросы по адресам, которые задают пользователи.
+// Клиент подключается только к публичным адресам: loopback, частные сети, link-local
+// (в том числе метаданные облака) и прочие служебные диапазоны запрещены. Проверяется
+// адрес, к которому действительно открывается соединение, поэтому DNS rebinding
+// и редиректы на внутренние адреса тоже отклоняются. Редиректы на другой хост
+// не выполняются: заголовки запроса могут содержать ключи доступа.
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress - адрес назначения не публичный.
+var ErrForbiddenAddress = errors.New("destination address is not allowed")
+
+// ErrForbiddenRedirect - сервер перенаправил запрос на другой хост.
+var ErrForbiddenRedirect = errors.New("redirect is not allowed")
+
+// ErrTooLarge - ответ больше допустимого размера.
+var ErrTooLarge = errors.New("response is too large")
+
+// maxRedirects - сколько редиректов выполняет клиент.
+const maxRedirects = 5
+
+// NewClient возвращает клиент с общим таймаутом запроса. Переменные окружения
+// прокси игнорируются: иначе проверялся бы адрес прокси, а не назначения.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			return checkAddress(address)
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   timeout,
+			ResponseHeaderTimeout: timeout,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       30 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if !strings.EqualFold(req.URL.Host, via[0].URL.Host) {
+				return fmt.Errorf("%w: redirect to another host %s", ErrForbiddenRedirect, req.URL.Host)
+			}
+			return nil
+		},
+	}
+}
+
+func checkAddress(address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !IsPublic(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, ip)
+	}
+	return nil
+}
+
+// nonPublicPrefixes - служебные диапазоны, не покрытые методами netip.Addr.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// IsPublic проверяет, что адрес маршрутизируется в интернете.
+func IsPublic(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// ReadAll читает тело ответа не больше limit байт.
+func ReadAll(r io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, ErrTooLarge
+	}
+	return data, nil
+}
@@ -0,0 +1,71 @@
+// Package thumbnail уменьшает растровые изображения для превью.
+package thumbnail
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+)
+
+// Size возвращает размеры изображения без декодирования пикселей.
+// Поддерживаются PNG, JPEG и GIF.
+func Size(data []byte) (width, height int, err error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, err
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// Make вписывает изображение в квадрат maxSide с сохранением пропорций и кодирует в PNG.
+// Изображение меньше квадрата не увеличивается.
+func Make(data []byte, maxSide int) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxSide || height > maxSide {
+		if width >= height {
+			width, height = maxSide, max(1, height*maxSide/width)
+		} else {
+			width, height = max(1, width*maxSide/height), maxSide
+		}
+	}
+
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, resize(src, width, height)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resize уменьшает изображение усреднением пикселей исходной области (box filter).
+func resize(src image.Image, width, height int) *image.NRGBA {
+	bounds := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*bounds.Dy()/height)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*bounds.Dx()/width)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a, n = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa), n+1
+				}
+			}
+			// RGBA() возвращает значения с учетом прозрачности, NRGBA при записи переводит их обратно
+			pixel := color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)}
+			dst.Set(x, y, pixel)
+		}
+	}
+	return dst
+}
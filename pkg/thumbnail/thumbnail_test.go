@@ -0,0 +1,85 @@
+package thumbnail
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, width, height int, fill color.Color) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, fill)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestSize(t *testing.T) {
+	width, height, err := Size(encodePNG(t, 30, 20, color.White))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if width != 30 || height != 20 {
+		t.Fatalf("size = %dx%d, want 30x20", width, height)
+	}
+	if _, _, err = Size([]byte("not an image")); err == nil {
+		t.Fatal("expected error for invalid image")
+	}
+}
+
+func TestMake(t *testing.T) {
+	fill := color.NRGBA{R: 200, G: 100, B: 50, A: 255}
+	tests := []struct {
+		name          string
+		width, height int
+		wantW, wantH  int
+	}{
+		{"landscape", 400, 100, 64, 16},
+		{"portrait", 100, 400, 16, 64},
+		{"thin", 1000, 2, 64, 1},
+		{"small is not enlarged", 20, 10, 20, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Make(encodePNG(t, tt.width, tt.height, fill), 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b := img.Bounds(); b.Dx() != tt.wantW || b.Dy() != tt.wantH {
+				t.Fatalf("size = %dx%d, want %dx%d", b.Dx(), b.Dy(), tt.wantW, tt.wantH)
+			}
+			if got := color.NRGBAModel.Convert(img.At(0, 0)).(color.NRGBA); got != fill {
+				t.Fatalf("pixel = %v, want %v", got, fill)
+			}
+		})
+	}
+}
+
+func TestMakeTransparent(t *testing.T) {
+	fill := color.NRGBA{R: 255, A: 128}
+	data, err := Make(encodePNG(t, 100, 100, fill), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := color.NRGBAModel.Convert(img.At(5, 5)).(color.NRGBA)
+	if got.R < 254 || got.G != 0 || got.B != 0 || got.A != 128 {
+		t.Fatalf("pixel = %v, want %v", got, fill)
+	}
+}
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_projects_name;
+DROP INDEX IF EXISTS idx_projects_updated_at;
+DROP INDEX IF EXISTS idx_projects_created_at;
+DROP INDEX IF EXISTS idx_projects_search;
+
+ALTER TABLE projects_membership DROP COLUMN IF EXISTS role;
+DROP TYPE IF EXISTS projects_role;
@@ -0,0 +1,11 @@
+-- projects_membership roles
+CREATE TYPE projects_role AS ENUM ('viewer', 'editor', 'admin', 'owner');
+ALTER TABLE projects_membership ADD COLUMN IF NOT EXISTS role projects_role NOT NULL DEFAULT 'viewer';
+UPDATE projects_membership SET role = 'owner' WHERE is_owner = TRUE;
+
+-- listing: search and keyset pagination
+CREATE INDEX IF NOT EXISTS idx_projects_search ON projects
+    USING GIN (to_tsvector('simple', name || ' ' || COALESCE(description, '')));
+CREATE INDEX IF NOT EXISTS idx_projects_created_at ON projects (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_projects_updated_at ON projects ((COALESCE(updated_at, created_at)), id);
+CREATE INDEX IF NOT EXISTS idx_projects_name ON projects (name, id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_projects_is_template;
+ALTER TABLE projects DROP COLUMN IF EXISTS source_project_id;
+ALTER TABLE projects DROP COLUMN IF EXISTS is_template;
@@ -0,0 +1,4 @@
+-- templates and duplication
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS is_template BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS source_project_id UUID DEFAULT NULL;
+CREATE INDEX IF NOT EXISTS idx_projects_is_template ON projects (is_template) WHERE deleted_at IS NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_projects_workspace_id;
+ALTER TABLE projects DROP COLUMN IF EXISTS workspace_id;
@@ -0,0 +1,3 @@
+-- projects owned by a workspace
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS workspace_id UUID DEFAULT NULL;
+CREATE INDEX IF NOT EXISTS idx_projects_workspace_id ON projects (workspace_id);
@@ -0,0 +1,12 @@
+-- projects_widget_types: custom widget types of a project
+CREATE TABLE IF NOT EXISTS projects_widget_types (
+                                                     project_id UUID NOT NULL,
+                                                     name VARCHAR(64) NOT NULL,
+                                                     description VARCHAR(500),
+                                                     schema JSONB NOT NULL,
+                                                     allow_children BOOLEAN NOT NULL DEFAULT FALSE,
+                                                     created_by UUID DEFAULT NULL,
+                                                     created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+                                                     updated_at TIMESTAMP DEFAULT NOW(),
+                                                     PRIMARY KEY (project_id, name)
+);
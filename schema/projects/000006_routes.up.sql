@@ -0,0 +1,14 @@
+-- projects_routes: URL paths of a project mapped to screens
+CREATE TABLE IF NOT EXISTS projects_routes
+(
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID         NOT NULL,
+    path       VARCHAR(300) NOT NULL,
+    screen_id  UUID         NOT NULL,
+    created_by UUID         DEFAULT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+
+    UNIQUE (project_id, path)
+);
+CREATE INDEX IF NOT EXISTS idx_routes_screen_id ON projects_routes (screen_id);
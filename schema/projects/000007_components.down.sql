@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS projects_component_versions;
+DROP TABLE IF EXISTS projects_components;
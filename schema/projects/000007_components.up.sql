@@ -0,0 +1,31 @@
+-- projects_components: reusable widget subtrees of a project or a workspace
+CREATE TABLE IF NOT EXISTS projects_components
+(
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id   UUID          DEFAULT NULL,
+    workspace_id UUID          DEFAULT NULL,
+    name         VARCHAR(100)  NOT NULL,
+    description  VARCHAR(4000) NOT NULL DEFAULT '',
+    version      INT           NOT NULL DEFAULT 1,
+    created_by   UUID          DEFAULT NULL,
+    created_at   TIMESTAMP     NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMP     NOT NULL DEFAULT NOW(),
+
+    CHECK ((project_id IS NULL) <> (workspace_id IS NULL))
+);
+CREATE INDEX IF NOT EXISTS idx_components_project_id ON projects_components (project_id);
+CREATE INDEX IF NOT EXISTS idx_components_workspace_id ON projects_components (workspace_id);
+
+-- projects_component_versions: immutable versions of a component subtree
+CREATE TABLE IF NOT EXISTS projects_component_versions
+(
+    component_id UUID         NOT NULL REFERENCES projects_components (id) ON DELETE CASCADE,
+    version      INT          NOT NULL,
+    root         VARCHAR(255) NOT NULL,
+    widgets      JSONB        NOT NULL,
+    message      VARCHAR(500) NOT NULL DEFAULT '',
+    created_by   UUID         DEFAULT NULL,
+    created_at   TIMESTAMP    NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (component_id, version)
+);
@@ -0,0 +1,9 @@
+-- projects_design_tokens: design tokens document of a project
+CREATE TABLE IF NOT EXISTS projects_design_tokens
+(
+    project_id UUID PRIMARY KEY,
+    tokens     JSONB     NOT NULL DEFAULT '{}',
+    version    INT       NOT NULL DEFAULT 1,
+    updated_by UUID      DEFAULT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS projects_assets;
+DROP TABLE IF EXISTS assets_blobs;
@@ -0,0 +1,27 @@
+-- assets_blobs: content-addressed files shared by all projects
+CREATE TABLE IF NOT EXISTS assets_blobs
+(
+    hash          CHAR(64)     PRIMARY KEY,
+    kind          VARCHAR(20)  NOT NULL,
+    mime_type     VARCHAR(100) NOT NULL,
+    size          BIGINT       NOT NULL,
+    width         INT          NOT NULL DEFAULT 0,
+    height        INT          NOT NULL DEFAULT 0,
+    has_thumbnail BOOLEAN      NOT NULL DEFAULT FALSE,
+    -- touched_at: last upload of the file, orphaned blobs are removed after a grace period
+    touched_at    TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+-- projects_assets: files uploaded to a project
+CREATE TABLE IF NOT EXISTS projects_assets
+(
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID         NOT NULL,
+    hash       CHAR(64)     NOT NULL REFERENCES assets_blobs (hash),
+    name       VARCHAR(255) NOT NULL,
+    created_by UUID         DEFAULT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+
+    UNIQUE (project_id, hash)
+);
+CREATE INDEX IF NOT EXISTS idx_assets_hash ON projects_assets (hash);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS projects_translations;
+DROP TABLE IF EXISTS projects_locales;
@@ -0,0 +1,25 @@
+-- projects_locales: languages a project is translated to; the default locale is the source language
+CREATE TABLE IF NOT EXISTS projects_locales
+(
+    project_id UUID        NOT NULL,
+    code       VARCHAR(20) NOT NULL,
+    is_default BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP   NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (project_id, code)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_locales_default ON projects_locales (project_id) WHERE is_default;
+
+-- projects_translations: text of a translation key in a locale
+CREATE TABLE IF NOT EXISTS projects_translations
+(
+    project_id UUID          NOT NULL,
+    key        VARCHAR(200)  NOT NULL,
+    locale     VARCHAR(20)   NOT NULL,
+    value      VARCHAR(10000) NOT NULL,
+    updated_by UUID          DEFAULT NULL,
+    updated_at TIMESTAMP     NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (project_id, key, locale),
+    FOREIGN KEY (project_id, locale) REFERENCES projects_locales (project_id, code) ON DELETE CASCADE
+);
@@ -0,0 +1,17 @@
+-- projects_data_sources: data sources that widget bindings refer to by name
+CREATE TABLE IF NOT EXISTS projects_data_sources
+(
+    project_id  UUID         NOT NULL,
+    name        VARCHAR(64)  NOT NULL,
+    description VARCHAR(500) NOT NULL DEFAULT '',
+    kind        VARCHAR(20)  NOT NULL,
+    rest        JSONB        DEFAULT NULL,
+    data        JSONB        DEFAULT NULL,
+    sample      JSONB        DEFAULT NULL,
+    recorded_at TIMESTAMP    DEFAULT NULL,
+    created_by  UUID         DEFAULT NULL,
+    created_at  TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMP    NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (project_id, name)
+);
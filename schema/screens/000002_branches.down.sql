@@ -0,0 +1,4 @@
+ALTER TABLE screens_branches DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE screens_branches DROP COLUMN IF EXISTS created_by;
+ALTER TABLE screens_branches DROP COLUMN IF EXISTS parent_version;
+ALTER TABLE screens_branches DROP COLUMN IF EXISTS parent_branch_id;
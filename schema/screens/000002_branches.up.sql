@@ -0,0 +1,5 @@
+-- branch ancestry: the branch and version a branch was forked from
+ALTER TABLE screens_branches ADD COLUMN IF NOT EXISTS parent_branch_id UUID DEFAULT NULL;
+ALTER TABLE screens_branches ADD COLUMN IF NOT EXISTS parent_version INT DEFAULT NULL;
+ALTER TABLE screens_branches ADD COLUMN IF NOT EXISTS created_by UUID DEFAULT NULL;
+ALTER TABLE screens_branches ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT NOW();
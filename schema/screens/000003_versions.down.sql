@@ -0,0 +1,3 @@
+ALTER TABLE screens_widgets DROP COLUMN IF EXISTS restored_from;
+ALTER TABLE screens_widgets DROP COLUMN IF EXISTS message;
+ALTER TABLE screens_widgets DROP COLUMN IF EXISTS author_id;
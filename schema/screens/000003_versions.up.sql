@@ -0,0 +1,4 @@
+-- version metadata: who saved the version and why
+ALTER TABLE screens_widgets ADD COLUMN IF NOT EXISTS author_id UUID DEFAULT NULL;
+ALTER TABLE screens_widgets ADD COLUMN IF NOT EXISTS message VARCHAR(500) NOT NULL DEFAULT '';
+ALTER TABLE screens_widgets ADD COLUMN IF NOT EXISTS restored_from INT DEFAULT NULL;
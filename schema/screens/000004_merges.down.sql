@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_widgets_merge_source;
+ALTER TABLE screens_widgets DROP COLUMN IF EXISTS merge_source_version;
+ALTER TABLE screens_widgets DROP COLUMN IF EXISTS merge_source_branch_id;
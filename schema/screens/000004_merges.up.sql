@@ -0,0 +1,5 @@
+-- merge versions remember which source branch version they merged
+ALTER TABLE screens_widgets ADD COLUMN IF NOT EXISTS merge_source_branch_id UUID DEFAULT NULL;
+ALTER TABLE screens_widgets ADD COLUMN IF NOT EXISTS merge_source_version INT DEFAULT NULL;
+CREATE INDEX IF NOT EXISTS idx_widgets_merge_source ON screens_widgets (branch_id, merge_source_branch_id)
+    WHERE merge_source_branch_id IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_releases_live;
+DROP TABLE IF EXISTS screens_releases;
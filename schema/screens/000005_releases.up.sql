@@ -0,0 +1,19 @@
+-- screens_releases: immutable published snapshots of screen versions
+CREATE TABLE IF NOT EXISTS screens_releases
+(
+    id             UUID PRIMARY KEY       DEFAULT gen_random_uuid(),
+    screen_id      UUID          NOT NULL,
+    release_number INT           NOT NULL,
+    branch_id      UUID          NOT NULL,
+    version        INT           NOT NULL,
+    widgets        JSONB         NOT NULL,
+    settings       JSONB         NOT NULL,
+    notes          VARCHAR(1000) NOT NULL DEFAULT '',
+    is_live        BOOLEAN       NOT NULL DEFAULT FALSE,
+    created_by     UUID                   DEFAULT NULL,
+    created_at     TIMESTAMP     NOT NULL DEFAULT NOW(),
+
+    UNIQUE (screen_id, release_number)
+);
+-- only one live release per screen
+CREATE UNIQUE INDEX IF NOT EXISTS idx_releases_live ON screens_releases (screen_id) WHERE is_live;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS screens_comments;
+DROP TABLE IF EXISTS screens_comment_threads;
@@ -0,0 +1,31 @@
+-- screens_comment_threads: review threads anchored to a widget of a branch version
+CREATE TABLE IF NOT EXISTS screens_comment_threads
+(
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    screen_id     UUID             NOT NULL,
+    branch_id     UUID             NOT NULL,
+    version       INT              NOT NULL,
+    widget_id     VARCHAR(200)     NOT NULL,
+    widget_type   VARCHAR(100)     NOT NULL DEFAULT '',
+    widget_props  JSONB            NOT NULL DEFAULT '{}',
+    widget_parent VARCHAR(200)     NOT NULL DEFAULT '',
+    widget_index  INT              NOT NULL DEFAULT 0,
+    status        VARCHAR(20)      NOT NULL DEFAULT 'open',
+    created_by    UUID             DEFAULT NULL,
+    created_at    TIMESTAMP        NOT NULL DEFAULT NOW(),
+    resolved_by   UUID             DEFAULT NULL,
+    resolved_at   TIMESTAMP        DEFAULT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_comment_threads_screen_id ON screens_comment_threads (screen_id, branch_id);
+
+-- screens_comments: messages of a thread, the first one opens the thread
+CREATE TABLE IF NOT EXISTS screens_comments
+(
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    thread_id     UUID             NOT NULL REFERENCES screens_comment_threads (id) ON DELETE CASCADE,
+    author_id     UUID             DEFAULT NULL,
+    body          VARCHAR(4000)    NOT NULL,
+    mentions      JSONB            NOT NULL DEFAULT '[]',
+    created_at    TIMESTAMP        NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_comments_thread_id ON screens_comments (thread_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS screens_change_request_approvals;
+DROP INDEX IF EXISTS idx_change_requests_open;
+DROP TABLE IF EXISTS screens_change_requests;
@@ -0,0 +1,31 @@
+-- screens_change_requests: requests to merge a screen branch into Main
+CREATE TABLE IF NOT EXISTS screens_change_requests
+(
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    screen_id        UUID             NOT NULL,
+    source_branch_id UUID             NOT NULL,
+    target_branch_id UUID             NOT NULL,
+    title            VARCHAR(200)     NOT NULL,
+    description      VARCHAR(4000)    NOT NULL DEFAULT '',
+    status           VARCHAR(20)      NOT NULL DEFAULT 'open',
+    created_by       UUID             DEFAULT NULL,
+    created_at       TIMESTAMP        NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMP        NOT NULL DEFAULT NOW(),
+    merged_by        UUID             DEFAULT NULL,
+    merged_at        TIMESTAMP        DEFAULT NULL,
+    merged_version   INT              DEFAULT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_change_requests_screen_id ON screens_change_requests (screen_id);
+-- only one open request per source branch
+CREATE UNIQUE INDEX IF NOT EXISTS idx_change_requests_open ON screens_change_requests (source_branch_id) WHERE status = 'open';
+
+-- screens_change_request_approvals: admin approvals of a source branch version
+CREATE TABLE IF NOT EXISTS screens_change_request_approvals
+(
+    change_request_id UUID      NOT NULL REFERENCES screens_change_requests (id) ON DELETE CASCADE,
+    user_id           UUID      NOT NULL,
+    source_version    INT       NOT NULL,
+    created_at        TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (change_request_id, user_id)
+);
@@ -0,0 +1,2 @@
+-- theme overrides of design tokens resolved at publish: theme -> JSON pointer -> value
+ALTER TABLE screens_releases ADD COLUMN IF NOT EXISTS themes JSONB NOT NULL DEFAULT '{}';
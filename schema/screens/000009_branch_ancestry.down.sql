@@ -0,0 +1 @@
+ALTER TABLE screens_branches DROP COLUMN IF EXISTS ancestry;
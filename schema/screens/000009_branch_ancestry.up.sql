@@ -0,0 +1,21 @@
+-- branch fork points from the parent up to the root: [{"branch_id": ..., "version": ...}];
+-- unlike parent_branch_id they are kept when ancestor branches are deleted
+ALTER TABLE screens_branches ADD COLUMN IF NOT EXISTS ancestry JSONB NOT NULL DEFAULT '[]';
+
+WITH RECURSIVE chain AS (
+    SELECT id AS branch_id, parent_branch_id, parent_version, 1 AS depth
+    FROM screens_branches
+    WHERE parent_branch_id IS NOT NULL
+    UNION ALL
+    SELECT c.branch_id, p.parent_branch_id, p.parent_version, c.depth + 1
+    FROM chain c
+    JOIN screens_branches p ON p.id = c.parent_branch_id
+    WHERE p.parent_branch_id IS NOT NULL
+)
+UPDATE screens_branches b
+SET ancestry = (
+    SELECT jsonb_agg(jsonb_build_object('branch_id', c.parent_branch_id, 'version', c.parent_version) ORDER BY c.depth)
+    FROM chain c
+    WHERE c.branch_id = b.id
+)
+WHERE b.parent_branch_id IS NOT NULL;
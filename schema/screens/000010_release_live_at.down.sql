@@ -0,0 +1 @@
+ALTER TABLE screens_releases DROP COLUMN IF EXISTS live_at;
@@ -0,0 +1,3 @@
+-- when a release last became live; the runtime API derives Last-Modified from it
+ALTER TABLE screens_releases ADD COLUMN IF NOT EXISTS live_at TIMESTAMP DEFAULT NULL;
+UPDATE screens_releases SET live_at = created_at WHERE is_live;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_workspaces_membership_workspace_id;
+DROP INDEX IF EXISTS idx_workspaces_membership_user_id;
+DROP TABLE IF EXISTS workspaces_membership;
+DROP TYPE IF EXISTS workspaces_role;
+
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,23 @@
+-- workspaces
+CREATE TABLE IF NOT EXISTS workspaces (
+                                          id UUID NOT NULL DEFAULT gen_random_uuid(),
+                                          name VARCHAR(100) NOT NULL,
+                                          description VARCHAR(4000),
+                                          created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+                                          updated_at TIMESTAMP DEFAULT NOW(),
+                                          deleted_at TIMESTAMP DEFAULT NULL,
+                                          PRIMARY KEY (id)
+);
+
+-- workspaces_membership
+CREATE TYPE workspaces_role AS ENUM ('viewer', 'editor', 'admin', 'owner');
+CREATE TABLE IF NOT EXISTS workspaces_membership (
+                                                     workspace_id UUID NOT NULL,
+                                                     user_id UUID NOT NULL,
+                                                     role workspaces_role NOT NULL DEFAULT 'viewer',
+                                                     added_at TIMESTAMP NOT NULL DEFAULT NOW(),
+                                                     deleted_at TIMESTAMP DEFAULT NULL,
+                                                     PRIMARY KEY (workspace_id, user_id)
+);
+CREATE INDEX idx_workspaces_membership_user_id ON workspaces_membership (user_id);
+CREATE INDEX idx_workspaces_membership_workspace_id ON workspaces_membership (workspace_id);